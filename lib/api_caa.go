@@ -0,0 +1,403 @@
+package a24apiclient
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+//
+// CAA records (RFC 8659) restrict which CAs may issue certificates for a name. The relevant rrset is found by
+// climbing from the name towards the apex, so policy of a name applies to all names below it without their own
+// CAA records. Property issue covers all certificates, issuewild overrides it for wildcards; value of both is
+// "<issuer>[; <parameter>=<value>]..." with empty issuer (";") denying issuance. Parameters accounturi and
+// validationmethods are defined by RFC 8657.
+
+// T_CaaProperty is parsed value of issue or issuewild property
+type T_CaaProperty struct {
+    Issuer          string
+    Parameters      []T_DkimTag
+}
+
+// T_CaaOptions are properties set by caa set, nil lists keep existing properties of the tag; AccountUri and
+// ValidationMethods override parameters of given issuers when not empty
+type T_CaaOptions struct {
+    Issue               []string
+    IssueWild           []string
+    Iodef               []string
+    AccountUri          string
+    ValidationMethods   string
+    Ttl                 string
+    Reset               bool
+}
+
+// T_CaaPolicy is CAA rrset relevant for name: Source is name the rrset is published at (differs from Name when
+// inherited), Inheriting are names below Name the policy applies to, Overriding names below with their own rrset
+type T_CaaPolicy struct {
+    Domain          string                `json:"domain"`
+    Name            string                `json:"name"`
+    Source          string                `json:"source"`
+    Records         []map[string]string   `json:"records"`
+    Inheriting      []string              `json:"inheriting"`
+    Overriding      []string              `json:"overriding"`
+    Findings        []T_SpfFinding        `json:"findings"`
+}
+
+const (
+    C_Caa_Ttl = "3600"
+    C_Caa_Deny = ";"
+    C_Caa_Critical = "128"
+)
+
+// tags managed by caa set, other tags (issuemail, issuevmc, contact*) are always kept
+var C_Caa_ManagedTags = []string{ "issue", "issuewild", "iodef" }
+
+// validation methods of ACME (RFC 8555, 8737), CA specific methods start with ca-
+var C_Caa_ValidationMethods = map[string]bool {
+    "dns-01": true,
+    "http-01": true,
+    "tls-alpn-01": true,
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Property
+// --------------------------------------------------------------------------------------------------------------------
+
+// ParseCaaValue parses issue or issuewild value
+func ParseCaaValue(value string) T_CaaProperty {
+    lParts := strings.Split(value, ";")
+    p := T_CaaProperty{ Issuer: strings.ToLower(strings.TrimSpace(lParts[0])) }
+    for _, lPart := range lParts[1:] {
+        if lPart = strings.TrimSpace(lPart); lPart == "" {
+            continue
+        }
+        lName, lValue := lPart, ""
+        if index := strings.Index(lPart, "="); index >= 0 {
+            lName, lValue = strings.TrimSpace(lPart[:index]), strings.TrimSpace(lPart[index + 1:])
+        }
+        p.Parameters = append(p.Parameters, T_DkimTag{ Name: strings.ToLower(lName), Value: lValue })
+    }
+    return p
+}
+
+// Parameter returns value of parameter, empty when not present
+func (p T_CaaProperty) Parameter(name string) string {
+    for _, lParameter := range p.Parameters {
+        if lParameter.Name == name {
+            return lParameter.Value
+        }
+    }
+    return ""
+}
+
+// SetParameter sets value of parameter, empty value removes it
+func (p *T_CaaProperty) SetParameter(name, value string) {
+    var lParameters []T_DkimTag
+    lSet := false
+    for _, lParameter := range p.Parameters {
+        if lParameter.Name == name {
+            lSet = true
+            if value == "" {
+                continue
+            }
+            lParameter.Value = value
+        }
+        lParameters = append(lParameters, lParameter)
+    }
+    if !lSet && value != "" {
+        lParameters = append(lParameters, T_DkimTag{ Name: name, Value: value })
+    }
+    p.Parameters = lParameters
+}
+
+// Deny reports whether property denies issuance (empty issuer)
+func (p T_CaaProperty) Deny() bool {
+    return p.Issuer == ""
+}
+
+func (p T_CaaProperty) String() string {
+    if p.Deny() {
+        return C_Caa_Deny
+    }
+    lParts := []string{ p.Issuer }
+    for _, lParameter := range p.Parameters {
+        lParts = append(lParts, lParameter.Name + "=" + lParameter.Value)
+    }
+    return strings.Join(lParts, "; ")
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Validate
+// --------------------------------------------------------------------------------------------------------------------
+
+// ValidateCaa returns findings of CAA rrset published for name (absolute), mainly policies blocking issuance
+func ValidateCaa(name string, records []map[string]string) []T_SpfFinding {
+    lFindings := []T_SpfFinding{}
+    lAdd := func(level, term, message string) {
+        lFindings = append(lFindings, T_SpfFinding{ Level: level, Domain: name, Term: term, Message: message })
+    }
+    if len(records) == 0 {
+        lAdd(C_SpfLint_Warning, "", "no CAA records, any CA may issue certificates")
+        return lFindings
+    }
+    lProperties := make(map[string][]T_CaaProperty)
+    for _, lRecord := range records {
+        lTag := strings.ToLower(lRecord["Tag"])
+        if !C_DnsValidate_CaaTags[lTag] && lRecord["Flags"] == C_Caa_Critical {
+            lAdd(C_SpfLint_Error, lTag, "is unknown critical property, no CA may issue certificates")
+        }
+        if lTag != "issue" && lTag != "issuewild" {
+            continue
+        }
+        p := ParseCaaValue(lRecord["CaaValue"])
+        lProperties[lTag] = append(lProperties[lTag], p)
+        if !p.Deny() && validateDnsHostname(p.Issuer, false) != "" {
+            lAdd(C_SpfLint_Error, lTag, fmt.Sprintf("issuer %s is not a domain name", p.Issuer))
+        }
+        if lUri := p.Parameter("accounturi"); lUri != "" && !strings.HasPrefix(lUri, "https://") {
+            lAdd(C_SpfLint_Warning, lTag, fmt.Sprintf("accounturi %s of %s is not an https url", lUri, p.Issuer))
+        }
+        for _, lMethod := range caaValidationMethods(p) {
+            if !C_Caa_ValidationMethods[lMethod] && !strings.HasPrefix(lMethod, "ca-") {
+                lAdd(C_SpfLint_Warning, lTag, fmt.Sprintf("validation method %s of %s is unknown", lMethod, p.Issuer))
+            }
+        }
+    }
+    lIssue, lWild := lProperties["issue"], lProperties["issuewild"]
+    if len(lIssue) == 0 && len(lWild) > 0 {
+        lAdd(C_SpfLint_Warning, "issue", "is not set, any CA may issue non-wildcard certificates")
+    }
+    if len(lIssue) > 0 && caaDenies(lIssue) {
+        lAdd(C_SpfLint_Warning, "issue", fmt.Sprintf("denies issuance for %s and names below without own CAA records", name))
+    }
+    // wildcards use issuewild when present, issue otherwise
+    lWildTag := "issuewild"
+    if len(lWild) == 0 {
+        lWild, lWildTag = lIssue, "issue"
+    }
+    if len(lWild) > 0 && caaDenies(lWild) {
+        // denied issue is reported above
+        if lWildTag == "issuewild" {
+            lAdd(C_SpfLint_Warning, lWildTag, fmt.Sprintf("denies wildcard certificates for *.%s", name))
+        }
+        return lFindings
+    }
+    for _, p := range lWild {
+        if lMethods := caaValidationMethods(p); len(lMethods) > 0 && !caaHasMethod(lMethods, "dns-01") {
+            lAdd(C_SpfLint_Warning, lWildTag, fmt.Sprintf("%s limits validation methods to %s, wildcard certificates require dns-01", p.Issuer, strings.Join(lMethods, ",")))
+        }
+    }
+    return lFindings
+}
+
+// caaDenies reports whether properties of one tag allow no issuer
+func caaDenies(properties []T_CaaProperty) bool {
+    for _, p := range properties {
+        if !p.Deny() {
+            return false
+        }
+    }
+    return true
+}
+
+func caaValidationMethods(p T_CaaProperty) []string {
+    var lMethods []string
+    for _, lMethod := range strings.Split(p.Parameter("validationmethods"), ",") {
+        if lMethod = strings.TrimSpace(lMethod); lMethod != "" {
+            lMethods = append(lMethods, strings.ToLower(lMethod))
+        }
+    }
+    return lMethods
+}
+
+func caaHasMethod(methods []string, method string) bool {
+    for _, lMethod := range methods {
+        if lMethod == method {
+            return true
+        }
+    }
+    return false
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Policy
+// --------------------------------------------------------------------------------------------------------------------
+
+// NewCaaPolicy returns CAA policy relevant for name from zone records; when desired is not nil it replaces CAA rrset
+// of name, e.g. to check policy before it is published
+func NewCaaPolicy(domain, name string, zone, desired []map[string]string) *T_CaaPolicy {
+    // rrsets are keyed by lower-case name, lDisplay keeps name as written in zone
+    lName := DnsNameRelative(name, domain)
+    lKey := strings.ToLower(lName)
+    lRRsets := make(map[string][]map[string]string)
+    lDisplay := map[string]string{ lKey: lName }
+    for _, lRecord := range zone {
+        lRecordName := DnsNameRelative(lRecord["Name"], domain)
+        lRecordKey := strings.ToLower(lRecordName)
+        if _, isKnown := lDisplay[lRecordKey]; !isKnown {
+            lDisplay[lRecordKey] = lRecordName
+        }
+        if lRecord["Type"] == "CAA" && (desired == nil || lRecordKey != lKey) {
+            lRRsets[lRecordKey] = append(lRRsets[lRecordKey], lRecord)
+        }
+    }
+    if len(desired) > 0 {
+        lRRsets[lKey] = desired
+    }
+    p := &T_CaaPolicy{ Domain: domain, Name: lName, Inheriting: []string{}, Overriding: []string{} }
+    lSource := caaSource(lKey, lRRsets)
+    p.Source = lDisplay[lSource]
+    p.Records = lRRsets[lSource]
+    if p.Records == nil {
+        p.Records = []map[string]string{}
+    }
+    for lRecordKey, lRecordName := range lDisplay {
+        if lRecordKey == lKey || !(lKey == C_DnsName_Apex || strings.HasSuffix(lRecordKey, "." + lKey)) {
+            continue
+        }
+        if len(lRRsets[lRecordKey]) > 0 {
+            p.Overriding = append(p.Overriding, lRecordName)
+        } else if lSource != "" && !strings.HasPrefix(lRecordKey, "_") && !strings.Contains(lRecordKey, "._") && caaSource(lRecordKey, lRRsets) == lSource {
+            // names with underscore labels (_dmarc, s1._domainkey...) never get certificates
+            p.Inheriting = append(p.Inheriting, lRecordName)
+        }
+    }
+    sort.Strings(p.Inheriting)
+    sort.Strings(p.Overriding)
+    p.Findings = ValidateCaa(strings.TrimSuffix(DnsNameAbsolute(p.Source, domain), "."), p.Records)
+    if lIssue := caaProperties(p.Records, "issue"); len(lIssue) > 0 && caaDenies(lIssue) && len(p.Inheriting) > 0 {
+        p.Findings = append(p.Findings, T_SpfFinding{ Level: C_SpfLint_Warning, Domain: strings.TrimSuffix(DnsNameAbsolute(p.Source, domain), "."), Term: "issue", Message: fmt.Sprintf("blocks issuance for %d names below: %s", len(p.Inheriting), strings.Join(p.Inheriting, ", ")) })
+    }
+    return p
+}
+
+// caaSource returns name whose CAA rrset is relevant for name, climbing towards apex, empty when there is none
+func caaSource(name string, rrsets map[string][]map[string]string) string {
+    for {
+        if len(rrsets[name]) > 0 {
+            return name
+        }
+        if name == C_DnsName_Apex {
+            return ""
+        }
+        if index := strings.Index(name, "."); index >= 0 {
+            name = name[index + 1:]
+        } else {
+            name = C_DnsName_Apex
+        }
+    }
+}
+
+func caaProperties(records []map[string]string, tag string) []T_CaaProperty {
+    var lProperties []T_CaaProperty
+    for _, lRecord := range records {
+        if strings.ToLower(lRecord["Tag"]) == tag {
+            lProperties = append(lProperties, ParseCaaValue(lRecord["CaaValue"]))
+        }
+    }
+    return lProperties
+}
+
+// Errors returns number of error findings
+func (p *T_CaaPolicy) Errors() int {
+    return len(mailAuthErrors(p.Findings))
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Set
+// --------------------------------------------------------------------------------------------------------------------
+
+// NewCaaRecords returns CAA rrset of name with properties of options: given tags replace existing ones, tags not
+// given are kept unless Reset, other tags are always kept
+func NewCaaRecords(domain, name string, existing []map[string]string, options T_CaaOptions) ([]map[string]string, error) {
+    if options.Issue == nil && options.IssueWild == nil && options.Iodef == nil && !options.Reset {
+        return nil, NewA24ApiClientError("Error: No CAA property given (--issue, --issuewild, --iodef or --reset).")
+    }
+    if (options.AccountUri != "" || options.ValidationMethods != "") && options.Issue == nil && options.IssueWild == nil {
+        return nil, NewA24ApiClientError("Error: Parameters --account-uri and --validation-methods need --issue or --issuewild.")
+    }
+    lGiven := map[string][]string{ "issue": options.Issue, "issuewild": options.IssueWild, "iodef": options.Iodef }
+    var lRecords []map[string]string
+    for _, lRecord := range existing {
+        lTag := strings.ToLower(lRecord["Tag"])
+        lValues, isManaged := lGiven[lTag]
+        if isManaged && (lValues != nil || options.Reset) {
+            continue
+        }
+        lRecords = append(lRecords, lRecord)
+    }
+    lTtl := options.Ttl
+    if lTtl == "" && len(existing) > 0 {
+        lTtl = existing[0]["Ttl"]
+    }
+    if lTtl == "" {
+        lTtl = C_Caa_Ttl
+    }
+    for _, lTag := range C_Caa_ManagedTags {
+        for _, lValue := range lGiven[lTag] {
+            lValue = strings.TrimSpace(lValue)
+            if lTag == "iodef" {
+                if strings.Contains(lValue, "@") && !strings.Contains(lValue, ":") {
+                    lValue = "mailto:" + lValue
+                }
+            } else if lValue == "none" || lValue == "" || lValue == C_Caa_Deny {
+                lValue = C_Caa_Deny
+            } else {
+                p := ParseCaaValue(lValue)
+                // flags override parameters written in value, parameters without flag are kept
+                if options.AccountUri != "" {
+                    p.SetParameter("accounturi", options.AccountUri)
+                }
+                if options.ValidationMethods != "" {
+                    p.SetParameter("validationmethods", strings.Replace(options.ValidationMethods, " ", "", -1))
+                }
+                lValue = p.String()
+            }
+            lRecord, err := NewDnsRecord(domain, []string{ "CAA", name, lTtl, "0", lTag, lValue })
+            if err != nil {
+                return nil, err
+            }
+            lRecords = append(lRecords, lRecord)
+        }
+    }
+    return lRecords, nil
+}
+
+// DnsPlanCaa returns changes making CAA rrset of name equal to records with policy the records would give
+func (c *T_A24ApiClient) DnsPlanCaa(domain, name string, options T_CaaOptions) ([]T_DnsChange, *T_CaaPolicy, error) {
+    lDomain, lZone, err := c.DnsZoneRecords(domain)
+    if err != nil {
+        return nil, nil, err
+    }
+    lName, err := NormaliseDnsName(name, lDomain)
+    if err != nil {
+        return nil, nil, err
+    }
+    var lExisting []map[string]string
+    for _, lRecord := range lZone {
+        if !DnsNameEqual(DnsNameRelative(lRecord["Name"], lDomain), lName) {
+            continue
+        }
+        if lRecord["Type"] == "CNAME" {
+            return nil, nil, NewA24ApiClientError(fmt.Sprintf("Error: Name %s is CNAME, CAA records can not be added.", DnsNameAbsolute(lName, lDomain)))
+        }
+        if lRecord["Type"] == "CAA" {
+            lExisting = append(lExisting, lRecord)
+        }
+    }
+    lRecords, err := NewCaaRecords(lDomain, lName, lExisting, options)
+    if err != nil {
+        return nil, nil, err
+    }
+    lChanges := DnsPlanRRset(lExisting, lRecords)
+    if lChanges == nil {
+        lChanges = []T_DnsChange{}
+    }
+    if lRecords == nil {
+        lRecords = []map[string]string{}
+    }
+    return lChanges, NewCaaPolicy(lDomain, lName, lZone, lRecords), nil
+}
@@ -0,0 +1,148 @@
+package a24apiclient
+
+import (
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func caaTestRecord(name, flags, tag, value string) map[string]string {
+    return map[string]string{ "Domain": "example.com", "Type": "CAA", "Name": name, "Ttl": "3600", "Flags": flags, "Tag": tag, "CaaValue": value }
+}
+
+func caaTestValues(records []map[string]string) []string {
+    var lValues []string
+    for _, lRecord := range records {
+        lValues = append(lValues, lRecord["Tag"] + " " + lRecord["CaaValue"])
+    }
+    return lValues
+}
+
+func TestParseCaaValue(t *testing.T) {
+    lTests := []struct {
+        value           string
+        issuer          string
+        accountUri      string
+        methods         string
+        text            string
+    }{
+        { "letsencrypt.org", "letsencrypt.org", "", "", "letsencrypt.org" },
+        { ";", "", "", "", ";" },
+        { "", "", "", "", ";" },
+        { "LetsEncrypt.org; accounturi=https://acme/acct/1", "letsencrypt.org", "https://acme/acct/1", "", "letsencrypt.org; accounturi=https://acme/acct/1" },
+        { "ca.example ;validationmethods = dns-01,http-01; ", "ca.example", "", "dns-01,http-01", "ca.example; validationmethods=dns-01,http-01" },
+    }
+    for _, lTest := range lTests {
+        p := ParseCaaValue(lTest.value)
+        if p.Issuer != lTest.issuer || p.Parameter("accounturi") != lTest.accountUri || p.Parameter("validationmethods") != lTest.methods {
+            t.Errorf("ParseCaaValue(%q) = %+v", lTest.value, p)
+        }
+        if p.String() != lTest.text {
+            t.Errorf("ParseCaaValue(%q).String() = %q, want %q", lTest.value, p.String(), lTest.text)
+        }
+    }
+}
+
+func TestNewCaaRecords(t *testing.T) {
+    lExisting := []map[string]string{
+        caaTestRecord("@", "0", "issue", "sectigo.com"),
+        caaTestRecord("@", "0", "issuewild", ";"),
+        caaTestRecord("@", "0", "iodef", "mailto:old@example.com"),
+        caaTestRecord("@", "0", "issuemail", "mail.example"),
+    }
+    lTests := []struct {
+        name            string
+        options         T_CaaOptions
+        values          []string
+        isError         bool
+    }{
+        { "inline parameters are kept",
+            T_CaaOptions{ Issue: []string{ "letsencrypt.org; accounturi=https://acme/acct/123; validationmethods=dns-01" } },
+            []string{ "issuewild ;", "iodef mailto:old@example.com", "issuemail mail.example", "issue letsencrypt.org; accounturi=https://acme/acct/123; validationmethods=dns-01" }, false },
+        { "flags override inline parameters",
+            T_CaaOptions{ Issue: []string{ "letsencrypt.org; accounturi=https://acme/acct/1; validationmethods=dns-01" }, ValidationMethods: "http-01, dns-01" },
+            []string{ "issuewild ;", "iodef mailto:old@example.com", "issuemail mail.example", "issue letsencrypt.org; accounturi=https://acme/acct/1; validationmethods=http-01,dns-01" }, false },
+        { "flags add parameters to each issuer",
+            T_CaaOptions{ IssueWild: []string{ "a.example", "none" }, AccountUri: "https://acme/acct/2" },
+            []string{ "issue sectigo.com", "iodef mailto:old@example.com", "issuemail mail.example", "issuewild a.example; accounturi=https://acme/acct/2", "issuewild ;" }, false },
+        { "iodef address gets mailto",
+            T_CaaOptions{ Iodef: []string{ "security@example.com", "https://report.example/caa" } },
+            []string{ "issue sectigo.com", "issuewild ;", "issuemail mail.example", "iodef mailto:security@example.com", "iodef https://report.example/caa" }, false },
+        { "reset removes managed tags not given",
+            T_CaaOptions{ Issue: []string{ "letsencrypt.org" }, Reset: true },
+            []string{ "issuemail mail.example", "issue letsencrypt.org" }, false },
+        { "nothing given", T_CaaOptions{}, nil, true },
+        { "parameters without issuer", T_CaaOptions{ Iodef: []string{ "a@example.com" }, AccountUri: "https://acme/acct/1" }, nil, true },
+    }
+    for _, lTest := range lTests {
+        lRecords, err := NewCaaRecords("example.com", "@", lExisting, lTest.options)
+        if (err != nil) != lTest.isError {
+            t.Errorf("%s: error %v", lTest.name, err)
+            continue
+        }
+        if lValues := caaTestValues(lRecords); !reflect.DeepEqual(lValues, lTest.values) {
+            t.Errorf("%s: got %q, want %q", lTest.name, lValues, lTest.values)
+        }
+    }
+}
+
+func TestValidateCaa(t *testing.T) {
+    lTests := []struct {
+        name            string
+        records         []map[string]string
+        findings        []string
+    }{
+        { "no records", nil, []string{ "warning : no CAA records" } },
+        { "plain issuer", []map[string]string{ caaTestRecord("@", "0", "issue", "letsencrypt.org") }, nil },
+        { "issue denied", []map[string]string{ caaTestRecord("@", "0", "issue", ";") }, []string{ "warning issue: denies issuance" } },
+        { "issuewild denied", []map[string]string{ caaTestRecord("@", "0", "issue", "letsencrypt.org"), caaTestRecord("@", "0", "issuewild", ";") }, []string{ "warning issuewild: denies wildcard" } },
+        { "issuewild without issue", []map[string]string{ caaTestRecord("@", "0", "issuewild", "letsencrypt.org") }, []string{ "warning issue: is not set" } },
+        { "wildcard without dns-01", []map[string]string{ caaTestRecord("@", "0", "issue", "letsencrypt.org; validationmethods=http-01") }, []string{ "warning issue: letsencrypt.org limits validation methods" } },
+        { "unknown method and http account", []map[string]string{ caaTestRecord("@", "0", "issue", "ca.example; accounturi=http://x; validationmethods=dns-01,foo") }, []string{ "warning issue: accounturi", "warning issue: validation method foo" } },
+        { "invalid issuer", []map[string]string{ caaTestRecord("@", "0", "issue", "not a host") }, []string{ "error issue: issuer" } },
+        { "unknown critical tag", []map[string]string{ caaTestRecord("@", "128", "future", "x"), caaTestRecord("@", "0", "issue", "ca.example") }, []string{ "error future: is unknown critical" } },
+    }
+    for _, lTest := range lTests {
+        lFindings := ValidateCaa("example.com", lTest.records)
+        if len(lFindings) != len(lTest.findings) {
+            t.Errorf("%s: got %d findings %+v, want %d", lTest.name, len(lFindings), lFindings, len(lTest.findings))
+            continue
+        }
+        for index, lFinding := range lFindings {
+            if lText := lFinding.Level + " " + lFinding.Term + ": " + lFinding.Message; !strings.HasPrefix(lText, lTest.findings[index]) {
+                t.Errorf("%s: finding %q, want prefix %q", lTest.name, lText, lTest.findings[index])
+            }
+        }
+    }
+}
+
+func TestNewCaaPolicy(t *testing.T) {
+    lZone := []map[string]string{
+        caaTestRecord("@", "0", "issue", ";"),
+        caaTestRecord("sub", "0", "issue", "letsencrypt.org"),
+        { "Type": "A", "Name": "www", "Ip": "192.0.2.1" },
+        { "Type": "A", "Name": "a.sub", "Ip": "192.0.2.2" },
+        { "Type": "TXT", "Name": "_dmarc", "Text": "v=DMARC1; p=none" },
+        { "Type": "TXT", "Name": "s1._domainkey", "Text": "v=DKIM1; p=" },
+    }
+    p := NewCaaPolicy("example.com", "www", lZone, nil)
+    if p.Source != "@" || len(p.Records) != 1 || len(p.Inheriting) != 0 {
+        t.Errorf("www policy %+v, want inherited from @", p)
+    }
+    p = NewCaaPolicy("example.com", "@", lZone, nil)
+    if !reflect.DeepEqual(p.Inheriting, []string{ "www" }) || !reflect.DeepEqual(p.Overriding, []string{ "sub" }) {
+        t.Errorf("apex inheriting %q overriding %q", p.Inheriting, p.Overriding)
+    }
+    if lLast := p.Findings[len(p.Findings) - 1]; !strings.HasPrefix(lLast.Message, "blocks issuance for 1 names below: www") {
+        t.Errorf("apex findings %+v", p.Findings)
+    }
+    p = NewCaaPolicy("example.com", "sub", lZone, nil)
+    if p.Source != "sub" || !reflect.DeepEqual(p.Inheriting, []string{ "a.sub" }) || len(p.Findings) != 0 {
+        t.Errorf("sub policy %+v", p)
+    }
+    // desired rrset replaces published one, empty removes it
+    p = NewCaaPolicy("example.com", "sub", lZone, []map[string]string{})
+    if p.Source != "@" {
+        t.Errorf("sub without rrset source %q, want @", p.Source)
+    }
+}
@@ -8,6 +8,7 @@ import (
     "time"
     "net/http"
     "strconv"
+    "sync"
 )
 
 // =============================================================================================================================================================
@@ -20,6 +21,7 @@ var (
         "token": "123456qwerty-ok",
         "network": "tcp",                                // [tcp|tcp4|tcp6]
         "timeout": "30",
+        "ratelimit": "0",                                // requests per second, 0 = unlimited
     }
 )
 
@@ -32,11 +34,16 @@ type T_A24ApiClient_Config_File struct {
     Token      string
     Network    string
     Timeout    string
+    Ratelimit  string
 }
 
 type T_A24ApiClient struct {
     Config                          map[string]string
     HttpClient                      *http.Client
+
+    rateInterval                    time.Duration
+    rateNext                        time.Time
+    rateMutex                       sync.Mutex
 }
 
 type T_A24ApiClientError struct{
@@ -56,6 +63,9 @@ func NewA24ApiClient(config map[string]string) *T_A24ApiClient {
     c := &T_A24ApiClient{ Config: config }
     c.mergeConfig()
     c.HttpClient = c.newHttpClient()
+    if lRate, err := strconv.ParseFloat(c.Config["ratelimit"], 64); err == nil && lRate > 0 {
+        c.rateInterval = time.Duration(float64(time.Second) / lRate)
+    }
 
     return c
 }
@@ -65,7 +75,7 @@ func (c *T_A24ApiClient) mergeConfig() {
         c.Config = make(map[string]string)
     }
     for key, defaultValue := range C_A24ApiClient_Config {
-        if _, isPresent := c.Config[key]; !isPresent {
+        if value, isPresent := c.Config[key]; !isPresent || value == "" {
             c.Config[key] = defaultValue
         }
     }
@@ -80,6 +90,22 @@ func (c *T_A24ApiClient) GetCodeText(code int, service string, function string)
     return
 }
 
+// waitRateLimit blocks until next request is allowed by ratelimit, it is safe for concurrent use
+func (c *T_A24ApiClient) waitRateLimit() {
+    if c.rateInterval == 0 {
+        return
+    }
+    c.rateMutex.Lock()
+    lNow := time.Now()
+    if c.rateNext.Before(lNow) {
+        c.rateNext = lNow
+    }
+    lWait := c.rateNext.Sub(lNow)
+    c.rateNext = c.rateNext.Add(c.rateInterval)
+    c.rateMutex.Unlock()
+    time.Sleep(lWait)
+}
+
 func newHttpTransport(timeout, network string) *http.Transport {
     var l_dualstack bool = true
     l_timeout_i, _ := strconv.Atoi(timeout)
@@ -125,6 +151,8 @@ func (c *T_A24ApiClient) doApiRequest(method, endpoint string, body map[string]s
     a24api_request.Header.Set("Accept", "application/json")
     a24api_request.Header.Set("Authorization", "Bearer " + c.Config["token"])
 
+    c.waitRateLimit()
+
     a24api_response, err := c.HttpClient.Do(a24api_request)
     if err != nil {
         return 0, nil, err
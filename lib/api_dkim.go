@@ -0,0 +1,328 @@
+package a24apiclient
+
+import (
+    "crypto"
+    "crypto/ed25519"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/x509"
+    "encoding/base64"
+    "encoding/pem"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+//
+// DKIM public keys are published as TXT records <selector>._domainkey (RFC 6376), RSA keys as base64 of
+// SubjectPublicKeyInfo, Ed25519 keys as base64 of the raw 32-byte key (RFC 8463). Selector kept live for a grace
+// period after rotation is marked by note tag n=retire-after:<time> which verifiers ignore; prune deletes marked
+// selectors once the time has passed.
+
+// T_DkimKey is generated key pair
+type T_DkimKey struct {
+    Algorithm       string
+    Bits            int
+    Private         crypto.Signer
+    Public          string
+}
+
+// T_DkimSelector is published DKIM key of domain
+type T_DkimSelector struct {
+    Domain          string                `json:"domain"`
+    Selector        string                `json:"selector"`
+    Algorithm       string                `json:"algorithm"`
+    Bits            int                   `json:"bits,omitempty"`
+    Revoked         bool                  `json:"revoked"`
+    RetireAfter     string                `json:"retireAfter,omitempty"`
+    Record          map[string]string     `json:"-"`
+}
+
+const (
+    C_Dkim_Rsa = "rsa"
+    C_Dkim_Ed25519 = "ed25519"
+    C_Dkim_RsaBits = 2048
+    C_Dkim_RsaMinBits = 1024
+    C_Dkim_RsaMaxBits = 4096
+    C_Dkim_Suffix = "._domainkey"
+    C_Dkim_Ttl = "3600"
+    C_Dkim_RetireNote = "retire-after:"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Key
+// --------------------------------------------------------------------------------------------------------------------
+
+// NewDkimKey generates key pair, bits apply to rsa only (0 is the default 2048)
+func NewDkimKey(algorithm string, bits int) (*T_DkimKey, error) {
+    k := &T_DkimKey{ Algorithm: strings.ToLower(algorithm) }
+    var lPublic []byte
+    switch k.Algorithm {
+        case C_Dkim_Rsa:
+            if bits == 0 {
+                bits = C_Dkim_RsaBits
+            }
+            if bits < C_Dkim_RsaMinBits || bits > C_Dkim_RsaMaxBits {
+                return nil, NewA24ApiClientError(fmt.Sprintf("Error: Invalid rsa key size %d (%d-%d).", bits, C_Dkim_RsaMinBits, C_Dkim_RsaMaxBits))
+            }
+            lKey, err := rsa.GenerateKey(rand.Reader, bits)
+            if err != nil {
+                return nil, err
+            }
+            if lPublic, err = x509.MarshalPKIXPublicKey(&lKey.PublicKey); err != nil {
+                return nil, err
+            }
+            k.Bits = bits
+            k.Private = lKey
+        case C_Dkim_Ed25519:
+            lPublicKey, lKey, err := ed25519.GenerateKey(rand.Reader)
+            if err != nil {
+                return nil, err
+            }
+            lPublic = lPublicKey
+            k.Private = lKey
+        default:
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Unsupported dkim algorithm %s (rsa, ed25519).", algorithm))
+    }
+    k.Public = base64.StdEncoding.EncodeToString(lPublic)
+    return k, nil
+}
+
+// PrivateKeyPem returns private key in PEM form read by MTAs, rsa as PKCS#1, ed25519 as PKCS#8
+func (k *T_DkimKey) PrivateKeyPem() ([]byte, error) {
+    if lKey, isRsa := k.Private.(*rsa.PrivateKey); isRsa {
+        return pem.EncodeToMemory(&pem.Block{ Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(lKey) }), nil
+    }
+    lDer, err := x509.MarshalPKCS8PrivateKey(k.Private)
+    if err != nil {
+        return nil, err
+    }
+    return pem.EncodeToMemory(&pem.Block{ Type: "PRIVATE KEY", Bytes: lDer }), nil
+}
+
+// Text returns value of DKIM TXT record
+func (k *T_DkimKey) Text() string {
+    return fmt.Sprintf("v=DKIM1; k=%s; p=%s", k.Algorithm, k.Public)
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Tags
+// --------------------------------------------------------------------------------------------------------------------
+
+// T_DkimTag is one tag=value pair of DKIM record, order of tags is kept
+type T_DkimTag struct {
+    Name            string
+    Value           string
+}
+
+// ParseDkimTags splits DKIM record into tags, whitespace around names and values is dropped
+func ParseDkimTags(text string) []T_DkimTag {
+    var lTags []T_DkimTag
+    for _, lPart := range strings.Split(text, ";") {
+        lPart = strings.TrimSpace(lPart)
+        if lPart == "" {
+            continue
+        }
+        lTag := T_DkimTag{ Name: lPart }
+        if index := strings.Index(lPart, "="); index >= 0 {
+            lTag = T_DkimTag{ Name: strings.TrimSpace(lPart[:index]), Value: strings.TrimSpace(lPart[index + 1:]) }
+        }
+        lTags = append(lTags, lTag)
+    }
+    return lTags
+}
+
+// DkimTagsString joins tags into DKIM record
+func DkimTagsString(tags []T_DkimTag) string {
+    var lParts []string
+    for _, lTag := range tags {
+        lParts = append(lParts, lTag.Name + "=" + lTag.Value)
+    }
+    return strings.Join(lParts, "; ")
+}
+
+func dkimTag(tags []T_DkimTag, name string) (string, bool) {
+    for _, lTag := range tags {
+        if lTag.Name == name {
+            return lTag.Value, true
+        }
+    }
+    return "", false
+}
+
+// IsDkim reports whether TXT value is DKIM key record (v=DKIM1 or p= tag)
+func IsDkim(text string) bool {
+    lTags := ParseDkimTags(text)
+    if len(lTags) > 0 && lTags[0].Name == "v" {
+        return lTags[0].Value == "DKIM1"
+    }
+    _, isPresent := dkimTag(lTags, "p")
+    return isPresent
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Selectors
+// --------------------------------------------------------------------------------------------------------------------
+
+// DkimSelectors returns DKIM keys published in domain ordered by selector
+func (c *T_A24ApiClient) DkimSelectors(domain string) ([]T_DkimSelector, error) {
+    lDomain, err := DnsIdnToAscii(domain)
+    if err != nil {
+        return nil, err
+    }
+    rc, lList, err := c.DnsListRecords(map[string]string{ "0": lDomain })
+    if err != nil {
+        return nil, err
+    }
+    if err := c.dnsResponseError(rc, "list"); err != nil {
+        return nil, err
+    }
+    lSelectors := []T_DkimSelector{}
+    for _, element := range lList {
+        lRecord := NewDnsRecordFromList(lDomain, element)
+        if lRecord["Type"] != "TXT" || !strings.HasSuffix(strings.ToLower(lRecord["Name"]), C_Dkim_Suffix) || !IsDkim(lRecord["Text"]) {
+            continue
+        }
+        lSelectors = append(lSelectors, newDkimSelector(lRecord))
+    }
+    sort.SliceStable(lSelectors, func(i, j int) bool {
+        return lSelectors[i].Selector < lSelectors[j].Selector
+    })
+    return lSelectors, nil
+}
+
+func newDkimSelector(record map[string]string) T_DkimSelector {
+    s := T_DkimSelector{ Domain: record["Domain"], Selector: record["Name"][:len(record["Name"]) - len(C_Dkim_Suffix)], Algorithm: C_Dkim_Rsa, Record: record }
+    lTags := ParseDkimTags(record["Text"])
+    if lAlgorithm, isPresent := dkimTag(lTags, "k"); isPresent {
+        s.Algorithm = lAlgorithm
+    }
+    lPublic, _ := dkimTag(lTags, "p")
+    lPublic = strings.Join(strings.Fields(lPublic), "")
+    s.Revoked = lPublic == ""
+    if lDer, err := base64.StdEncoding.DecodeString(lPublic); err == nil && s.Algorithm == C_Dkim_Rsa {
+        if lKey, err := x509.ParsePKIXPublicKey(lDer); err == nil {
+            if lRsa, isRsa := lKey.(*rsa.PublicKey); isRsa {
+                s.Bits = lRsa.N.BitLen()
+            }
+        } else if lRsa, err := x509.ParsePKCS1PublicKey(lDer); err == nil {
+            s.Bits = lRsa.N.BitLen()
+        }
+    }
+    if lNote, isPresent := dkimTag(lTags, "n"); isPresent && strings.HasPrefix(lNote, C_Dkim_RetireNote) {
+        s.RetireAfter = strings.TrimPrefix(lNote, C_Dkim_RetireNote)
+    }
+    return s
+}
+
+// Expired reports whether selector is marked for retirement and its grace period has passed
+func (s T_DkimSelector) Expired(now time.Time) bool {
+    lRetireAfter, err := time.Parse(time.RFC3339, s.RetireAfter)
+    return err == nil && !now.Before(lRetireAfter)
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Rotate
+// --------------------------------------------------------------------------------------------------------------------
+
+// DkimRotate returns changes publishing key under new selector. Old selector (optional) is deleted when grace is 0,
+// otherwise kept live and marked for retirement after grace.
+func (c *T_A24ApiClient) DkimRotate(domain, selector string, newKey *T_DkimKey, old string, grace time.Duration, now time.Time) ([]T_DnsChange, error) {
+    lDomain, err := DnsIdnToAscii(domain)
+    if err != nil {
+        return nil, err
+    }
+    lSelector := strings.TrimSuffix(strings.ToLower(selector), C_Dkim_Suffix)
+    if lMessage := validateDnsName(lSelector, false); lMessage != "" || lSelector == C_DnsName_Apex {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Invalid selector %s.", selector))
+    }
+    lOld := strings.TrimSuffix(strings.ToLower(old), C_Dkim_Suffix)
+    if lOld == lSelector {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: New and old selector are the same (%s).", lSelector))
+    }
+    lSelectors, err := c.DkimSelectors(lDomain)
+    if err != nil {
+        return nil, err
+    }
+    var lOldSelector *T_DkimSelector
+    // published selectors keep case of their records
+    for index := range lSelectors {
+        switch {
+            case DnsNameEqual(lSelectors[index].Selector, lSelector):
+                return nil, NewA24ApiClientError(fmt.Sprintf("Error: Selector %s is already published in %s, choose new selector.", lSelector, lDomain))
+            case lOld != "" && DnsNameEqual(lSelectors[index].Selector, lOld):
+                lOldSelector = &lSelectors[index]
+        }
+    }
+    if lOld != "" && lOldSelector == nil {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Selector %s is not published in %s.", lOld, lDomain))
+    }
+    lTtl := C_Dkim_Ttl
+    if lOldSelector != nil {
+        lTtl = lOldSelector.Record["Ttl"]
+    }
+    lRecord := map[string]string{ "Domain": lDomain, "Type": "TXT", "Name": lSelector + C_Dkim_Suffix, "Ttl": lTtl, "Text": newKey.Text() }
+    lChanges := []T_DnsChange{ { Action: "create", Record: lRecord } }
+    if lOldSelector == nil {
+        return lChanges, nil
+    }
+    if grace <= 0 {
+        return append(lChanges, T_DnsChange{ Action: "delete", Record: lOldSelector.Record }), nil
+    }
+    // old key stays valid for signatures in transit and queued mail
+    lTags := ParseDkimTags(lOldSelector.Record["Text"])
+    lNote := C_Dkim_RetireNote + now.Add(grace).UTC().Format(time.RFC3339)
+    lNoted := false
+    for index := range lTags {
+        if lTags[index].Name == "n" {
+            lTags[index].Value = lNote
+            lNoted = true
+        }
+    }
+    if !lNoted {
+        lTags = append(lTags, T_DkimTag{ Name: "n", Value: lNote })
+    }
+    lRetired := make(map[string]string)
+    for key, value := range lOldSelector.Record {
+        lRetired[key] = value
+    }
+    lRetired["Text"] = DkimTagsString(lTags)
+    return append(lChanges, T_DnsChange{ Action: "update", Record: lRetired, Previous: lOldSelector.Record }), nil
+}
+
+// DkimPrune returns changes deleting selectors whose grace period has passed
+func (c *T_A24ApiClient) DkimPrune(domain string, now time.Time) ([]T_DnsChange, error) {
+    lSelectors, err := c.DkimSelectors(domain)
+    if err != nil {
+        return nil, err
+    }
+    lChanges := []T_DnsChange{}
+    for _, lSelector := range lSelectors {
+        if lSelector.Expired(now) {
+            lChanges = append(lChanges, T_DnsChange{ Action: "delete", Record: lSelector.Record })
+        }
+    }
+    return lChanges, nil
+}
+
+// ParseDkimGrace parses grace period as go duration (72h) or days (7d)
+func ParseDkimGrace(value string) (time.Duration, error) {
+    if value == "" {
+        return 0, nil
+    }
+    if strings.HasSuffix(value, "d") {
+        if lDays, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && lDays >= 0 {
+            return time.Duration(lDays) * 24 * time.Hour, nil
+        }
+    }
+    lGrace, err := time.ParseDuration(value)
+    if err != nil || lGrace < 0 {
+        return 0, NewA24ApiClientError(fmt.Sprintf("Error: Invalid grace period %s (e.g. 72h or 7d).", value))
+    }
+    return lGrace, nil
+}
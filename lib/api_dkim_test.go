@@ -0,0 +1,29 @@
+package a24apiclient
+
+import (
+    "testing"
+    "time"
+)
+
+func TestDkimRotateSelectorCase(t *testing.T) {
+    s, lZones := newDnsTestServer("example.com")
+    defer s.Close()
+    lZones["example.com"] = append(lZones["example.com"], map[string]interface{}{ "hashId": "h0", "type": "TXT", "name": "Mail._domainkey", "ttl": 7200.0, "text": "v=DKIM1; k=rsa; p=AAAA" })
+    c := NewA24ApiClient(map[string]string{ "endpoint": s.URL })
+    lKey, err := NewDkimKey(C_Dkim_Ed25519, 0)
+    if err != nil {
+        t.Fatalf("NewDkimKey failed: %v", err)
+    }
+    lNow := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+    if _, err := c.DkimRotate("example.com", "mail", lKey, "", 0, lNow); err == nil {
+        t.Errorf("DkimRotate accepted published selector Mail as new selector mail")
+    }
+    lChanges, err := c.DkimRotate("example.com", "next", lKey, "MAIL._domainkey", 0, lNow)
+    if err != nil {
+        t.Fatalf("DkimRotate failed: %v", err)
+    }
+    if len(lChanges) != 2 || lChanges[0].Record["Ttl"] != "7200" || lChanges[1].Action != "delete" || lChanges[1].Record["HashId"] != "h0" {
+        t.Errorf("DkimRotate changes %+v", lChanges)
+    }
+}
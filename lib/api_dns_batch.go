@@ -0,0 +1,267 @@
+package a24apiclient
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "strings"
+    "sync"
+    "time"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+
+// T_DnsBatchOperation is one line of batch file. Err is set when the line could not be parsed.
+type T_DnsBatchOperation struct {
+    Line            int
+    Operation       string
+    Record          map[string]string
+    Err             error
+}
+
+type T_DnsBatchResult struct {
+    Line            int                   `json:"line"`
+    Operation       string                `json:"operation"`
+    Action          string                `json:"action,omitempty"`
+    Status          string                `json:"status"`
+    Code            int                   `json:"code"`
+    CodeText        string                `json:"codeText,omitempty"`
+    Record          map[string]string     `json:"record,omitempty"`
+    Error           string                `json:"error,omitempty"`
+    StartedAt       time.Time             `json:"-"`
+    Duration        time.Duration         `json:"-"`
+}
+
+const (
+    C_DnsBatch_Ok = "ok"
+    C_DnsBatch_Error = "error"
+    C_DnsBatch_Skipped = "skipped"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Parse batch file
+// --------------------------------------------------------------------------------------------------------------------
+//
+// csv:   one operation per row, columns follow command-line arguments, lines starting with # are ignored
+//          create,<domain>,<type>,<name>,<ttl>,<value...>
+//          upsert,<domain>,<type>,<name>,<ttl>,<value...>
+//          update,<domain>,<hash_id>,<type>,<name>,<ttl>,<value...>
+//          delete,<domain>,<hash_id>
+// jsonl: one json object per line with operation, domain and record keys as returned by api
+//          {"operation":"create","domain":"example.com","type":"A","name":"www","ttl":3600,"ip":"192.0.2.1"}
+
+// NewDnsBatch parses batch file, format is csv, jsonl or empty for autodetection
+func NewDnsBatch(r io.Reader, format string) ([]T_DnsBatchOperation, error) {
+    lData, err := ioutil.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+    if format == "" {
+        format = "csv"
+        if strings.HasPrefix(strings.TrimSpace(string(lData)), "{") {
+            format = "jsonl"
+        }
+    }
+    switch format {
+        case "csv":
+            return newDnsBatchFromCsv(lData)
+        case "jsonl":
+            return newDnsBatchFromJsonl(lData)
+        default:
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Unsupported batch format %s.", format))
+    }
+}
+
+func newDnsBatchFromCsv(data []byte) ([]T_DnsBatchOperation, error) {
+    var lOperations []T_DnsBatchOperation
+    r := csv.NewReader(bytes.NewReader(data))
+    r.FieldsPerRecord = -1
+    r.Comment = '#'
+    r.TrimLeadingSpace = true
+    for {
+        lRow, err := r.Read()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            // FieldPos is valid only after successful read
+            if lParseError, isParseError := err.(*csv.ParseError); isParseError {
+                lOperations = append(lOperations, T_DnsBatchOperation{ Line: lParseError.StartLine, Err: err })
+                continue
+            }
+            return nil, err
+        }
+        lLine, _ := r.FieldPos(0)
+        lOperations = append(lOperations, newDnsBatchOperation(lLine, lRow))
+    }
+    return lOperations, nil
+}
+
+func newDnsBatchOperation(line int, row []string) T_DnsBatchOperation {
+    o := T_DnsBatchOperation{ Line: line }
+    if len(row) < 2 {
+        o.Err = NewA24ApiClientError("Error: Operation or domain not provided.")
+        return o
+    }
+    o.Operation = strings.ToLower(row[0])
+    switch o.Operation {
+        case "create", "upsert":
+            o.Record, o.Err = NewDnsRecord(row[1], row[2:])
+        case "update":
+            if len(row) < 3 {
+                o.Err = NewA24ApiClientError("Error: Hash_id not provided.")
+                return o
+            }
+            o.Record, o.Err = NewDnsRecord(row[1], row[3:])
+            if o.Err == nil {
+                o.Record["HashId"] = row[2]
+            }
+        case "delete":
+            if len(row) != 3 {
+                o.Err = NewA24ApiClientError("Error: Delete expects domain and hash_id.")
+                return o
+            }
+            o.Record = map[string]string{ "Domain": row[1], "HashId": row[2] }
+        default:
+            o.Err = NewA24ApiClientError(fmt.Sprintf("Error: Unsupported operation %s.", row[0]))
+    }
+    return o
+}
+
+func newDnsBatchFromJsonl(data []byte) ([]T_DnsBatchOperation, error) {
+    var lOperations []T_DnsBatchOperation
+    s := bufio.NewScanner(bytes.NewReader(data))
+    s.Buffer(make([]byte, 64 * 1024), 1024 * 1024)
+    lLine := 0
+    for s.Scan() {
+        lLine++
+        lText := strings.TrimSpace(s.Text())
+        if lText == "" || strings.HasPrefix(lText, "#") {
+            continue
+        }
+        var element map[string]interface{}
+        if err := json.Unmarshal([]byte(lText), &element); err != nil {
+            lOperations = append(lOperations, T_DnsBatchOperation{ Line: lLine, Err: err })
+            continue
+        }
+        lOperations = append(lOperations, newDnsBatchOperationFromJson(lLine, element))
+    }
+    return lOperations, s.Err()
+}
+
+func newDnsBatchOperationFromJson(line int, element map[string]interface{}) T_DnsBatchOperation {
+    o := T_DnsBatchOperation{ Line: line }
+    o.Operation = strings.ToLower(dnsRecordListValue(element["operation"]))
+    lDomain := dnsRecordListValue(element["domain"])
+    if lDomain == "" {
+        o.Err = NewA24ApiClientError("Error: Domain not provided.")
+        return o
+    }
+    lRecord := NewDnsRecordFromList(lDomain, element)
+    switch o.Operation {
+        case "create", "upsert", "update":
+            lArgs := []string{ lRecord["Type"], lRecord["Name"], lRecord["Ttl"] }
+            for _, lField := range C_A24ApiClient_DnsRecordFields[strings.ToUpper(lRecord["Type"])] {
+                lArgs = append(lArgs, dnsRecordListValue(element[lField.ApiKey]))
+            }
+            o.Record, o.Err = NewDnsRecord(lDomain, lArgs)
+            if o.Err == nil && o.Operation == "update" {
+                if lRecord["HashId"] == "" {
+                    o.Err = NewA24ApiClientError("Error: HashId not provided.")
+                }
+                o.Record["HashId"] = lRecord["HashId"]
+            }
+        case "delete":
+            if lRecord["HashId"] == "" {
+                o.Err = NewA24ApiClientError("Error: HashId not provided.")
+            }
+            o.Record = map[string]string{ "Domain": lDomain, "HashId": lRecord["HashId"] }
+        default:
+            o.Err = NewA24ApiClientError(fmt.Sprintf("Error: Unsupported operation %s.", o.Operation))
+    }
+    return o
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Execute batch
+// --------------------------------------------------------------------------------------------------------------------
+
+// DnsBatch executes operations with given concurrency and returns results in input order. With stopOnError no new
+// operation is started after the first failure and remaining ones are reported as skipped.
+func (c *T_A24ApiClient) DnsBatch(operations []T_DnsBatchOperation, concurrency int, stopOnError bool) []T_DnsBatchResult {
+    return c.DnsBatchStream(operations, concurrency, stopOnError, nil)
+}
+
+// DnsBatchStream is DnsBatch which also passes each result to emit as soon as it is known (completion order,
+// skipped operations last). Calls of emit are serialized.
+func (c *T_A24ApiClient) DnsBatchStream(operations []T_DnsBatchOperation, concurrency int, stopOnError bool, emit func(T_DnsBatchResult)) []T_DnsBatchResult {
+    lResults := make([]T_DnsBatchResult, len(operations))
+    var lEmitMutex sync.Mutex
+
+    lStarted := RunWorkerPool(len(operations), concurrency, stopOnError, func(index int) error {
+        lResults[index] = c.dnsBatchExecute(operations[index])
+        if emit != nil {
+            lEmitMutex.Lock()
+            emit(lResults[index])
+            lEmitMutex.Unlock()
+        }
+        if lResults[index].Status == C_DnsBatch_Error {
+            return NewA24ApiClientError(lResults[index].Error)
+        }
+        return nil
+    })
+
+    for index := range operations {
+        if !lStarted[index] {
+            lResults[index] = T_DnsBatchResult{ Line: operations[index].Line, Operation: operations[index].Operation, Status: C_DnsBatch_Skipped, Record: operations[index].Record }
+            if emit != nil {
+                emit(lResults[index])
+            }
+        }
+    }
+
+    return lResults
+}
+
+func (c *T_A24ApiClient) dnsBatchExecute(operation T_DnsBatchOperation) T_DnsBatchResult {
+    r := T_DnsBatchResult{ Line: operation.Line, Operation: operation.Operation, Record: operation.Record, StartedAt: time.Now() }
+    if operation.Err != nil {
+        r.Status = C_DnsBatch_Error
+        r.Error = operation.Err.Error()
+        r.Duration = time.Since(r.StartedAt)
+        return r
+    }
+    var err error
+    lCodeFunction := operation.Operation
+    switch operation.Operation {
+        case "create":
+            r.Code, _, err = c.DnsCreate(operation.Record)
+        case "update":
+            r.Code, _, err = c.DnsUpdate(operation.Record)
+        case "delete":
+            r.Code, _, err = c.DnsDelete(operation.Record)
+        case "upsert":
+            r.Action, r.Code, _, err = c.DnsUpsert(operation.Record)
+            lCodeFunction = r.Action
+    }
+    if err == nil {
+        err = c.dnsResponseError(r.Code, lCodeFunction)
+    }
+    if r.Code != 0 {
+        r.CodeText = c.GetCodeText(r.Code, "dns", lCodeFunction)
+    }
+    if err != nil {
+        r.Status = C_DnsBatch_Error
+        r.Error = err.Error()
+    } else {
+        r.Status = C_DnsBatch_Ok
+    }
+    r.Duration = time.Since(r.StartedAt)
+    return r
+}
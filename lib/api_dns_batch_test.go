@@ -0,0 +1,56 @@
+package a24apiclient
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestNewDnsBatch(t *testing.T) {
+    lTests := []struct {
+        name            string
+        format          string
+        input           string
+        lines           []int
+        operations      []string
+        errors          []bool
+    }{
+        { "csv create", "", "create,example.com,A,www,3600,192.0.2.1\n", []int{ 1 }, []string{ "create" }, []bool{ false } },
+        { "csv comment and delete", "csv", "# comment\ndelete,example.com,abc123\n", []int{ 2 }, []string{ "delete" }, []bool{ false } },
+        { "csv bad quote in first field", "csv", "a\"b,example.com\n", []int{ 1 }, []string{ "" }, []bool{ true } },
+        { "csv bad quote then valid line", "csv", "create,example.com,A,www,3600,192.0.2.1\nx\"y,example.com\ndelete,example.com,abc123\n", []int{ 1, 2, 3 }, []string{ "create", "", "delete" }, []bool{ false, true, false } },
+        { "csv unknown operation", "csv", "rename,example.com\n", []int{ 1 }, []string{ "rename" }, []bool{ true } },
+        { "csv update without hash", "csv", "update,example.com\n", []int{ 1 }, []string{ "update" }, []bool{ true } },
+        { "csv wrong argument count", "csv", "create,example.com,A,www,3600\n", []int{ 1 }, []string{ "create" }, []bool{ true } },
+        { "jsonl autodetected", "", "{\"operation\":\"create\",\"domain\":\"example.com\",\"type\":\"A\",\"name\":\"www\",\"ttl\":3600,\"ip\":\"192.0.2.1\"}\n", []int{ 1 }, []string{ "create" }, []bool{ false } },
+        { "jsonl invalid json", "jsonl", "\n{\"operation\":\n", []int{ 2 }, []string{ "" }, []bool{ true } },
+        { "jsonl delete without hash", "jsonl", "{\"operation\":\"delete\",\"domain\":\"example.com\"}\n", []int{ 1 }, []string{ "delete" }, []bool{ true } },
+    }
+    for _, lTest := range lTests {
+        lOperations, err := NewDnsBatch(strings.NewReader(lTest.input), lTest.format)
+        if err != nil {
+            t.Errorf("%s: unexpected error %v", lTest.name, err)
+            continue
+        }
+        if len(lOperations) != len(lTest.lines) {
+            t.Errorf("%s: got %d operations, want %d", lTest.name, len(lOperations), len(lTest.lines))
+            continue
+        }
+        for index, lOperation := range lOperations {
+            if lOperation.Line != lTest.lines[index] {
+                t.Errorf("%s: operation %d line %d, want %d", lTest.name, index, lOperation.Line, lTest.lines[index])
+            }
+            if lOperation.Operation != lTest.operations[index] {
+                t.Errorf("%s: operation %d is %q, want %q", lTest.name, index, lOperation.Operation, lTest.operations[index])
+            }
+            if (lOperation.Err != nil) != lTest.errors[index] {
+                t.Errorf("%s: operation %d error %v, want error %v", lTest.name, index, lOperation.Err, lTest.errors[index])
+            }
+        }
+    }
+}
+
+func TestNewDnsBatchUnsupportedFormat(t *testing.T) {
+    if _, err := NewDnsBatch(strings.NewReader("x"), "xml"); err == nil {
+        t.Errorf("expected error for unsupported format")
+    }
+}
@@ -27,7 +27,15 @@ func (c *T_A24ApiClient) DnsListDomains() (int, interface {}, error) {
 // --------------------------------------------------------------------------------------------------------------------
 
 func (c *T_A24ApiClient) DnsListRecords(data map[string]string) (int, T_DnsRecordList, error) {
-    rc, rb, err := c.doApiRequest("GET", c.Config["endpoint"] + "/dns/" + data["0"] + "/records/v1", nil);
+    lDomain, lErr := DnsIdnToAscii(data["0"])
+    if lErr != nil {
+        return 0, nil, lErr
+    }
+    lUrl, err := c.dnsUrl(lDomain, "records", "v1")
+    if err != nil {
+        return 0, nil, err
+    }
+    rc, rb, err := c.doApiRequest("GET", lUrl, nil);
     if err != nil {
         return rc, nil, err
     }
@@ -36,7 +44,16 @@ func (c *T_A24ApiClient) DnsListRecords(data map[string]string) (int, T_DnsRecor
     if err != nil {
         return rc, nil, err
     }
-    return rc, t, err
+    // names are reported in canonical form, long TXT values are returned as quoted character-strings
+    for _, element := range t {
+        if lName, isName := element["name"].(string); isName {
+            element["name"] = DnsNameRelative(lName, lDomain)
+        }
+        if lText, isText := element["text"].(string); isText && element["type"] == "TXT" {
+            element["text"] = DnsTxtDecode(lText)
+        }
+    }
+    return rc, t, nil
 }
 
 // --------------------------------------------------------------------------------------------------------------------
@@ -61,9 +78,24 @@ func (c *T_A24ApiClient) DnsDelete(record interface{}) (int, []byte, error) {
             return 0, nil, NewA24ApiClientError(fmt.Sprintf("Error: Unknown type %s.", t))
     }
 
-    rc, rb, err :=  c.doApiRequest("DELETE", c.Config["endpoint"] + "/dns/" + lDomain + "/" + lHashId + "/v1", nil);
+    lUrl, err := c.dnsUrl(lDomain, lHashId, "v1")
+    if err != nil {
+        return 0, nil, err
+    }
+    rc, rb, err :=  c.doApiRequest("DELETE", lUrl, nil);
     if err != nil {
         return rc, nil, err
     }
     return rc, rb, nil
 }
+
+// --------------------------------------------------------------------------------------------------------------------
+// Check response code
+// --------------------------------------------------------------------------------------------------------------------
+
+func (c *T_A24ApiClient) dnsResponseError(rc int, function string) error {
+    if rc == 200 || rc == 204 {
+        return nil
+    }
+    return NewA24ApiClientError(fmt.Sprintf("Error: %s failed with %d %s.", function, rc, c.GetCodeText(rc, "dns", function)))
+}
@@ -0,0 +1,123 @@
+package a24apiclient
+
+import (
+    "fmt"
+    "regexp"
+    "sort"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+
+// T_DnsCopyOptions selects records to copy (nil filter matches all) and sets handling of rrsets which already
+// exist in destination with different content: skip, overwrite or fail
+type T_DnsCopyOptions struct {
+    TypeFilter      *regexp.Regexp
+    NameFilter      *regexp.Regexp
+    Rewrite         bool
+    OnConflict      string
+}
+
+const (
+    C_DnsCopy_Skip = "skip"
+    C_DnsCopy_Overwrite = "overwrite"
+    C_DnsCopy_Fail = "fail"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Plan copy
+// --------------------------------------------------------------------------------------------------------------------
+
+// DnsPlanCopy returns changes copying selected rrsets of source domain into destination domain. Rrsets are
+// compared as a whole after normalisation: missing rrsets are created, equal ones left alone and conflicting ones
+// handled by OnConflict. Created and updated records carry values of source as they are (normalisation is used for
+// comparison only). Names are copied relative to domain, so they need no rewriting; with Rewrite, values
+// referencing source domain (targets, SPF includes, ...) are rewritten to destination.
+// Second value lists skipped conflicting rrsets as "<type> <name>".
+func DnsPlanCopy(src []map[string]string, srcDomain string, dst []map[string]string, dstDomain string, options T_DnsCopyOptions) ([]T_DnsChange, []string, error) {
+    if options.OnConflict == "" {
+        options.OnConflict = C_DnsCopy_Fail
+    }
+    if options.OnConflict != C_DnsCopy_Skip && options.OnConflict != C_DnsCopy_Overwrite && options.OnConflict != C_DnsCopy_Fail {
+        return nil, nil, NewA24ApiClientError(fmt.Sprintf("Error: Unsupported conflict mode %s.", options.OnConflict))
+    }
+
+    lRewrite := regexp.MustCompile(`(?i)(^|[^a-z0-9-])` + regexp.QuoteMeta(dnsCanonicalHost(srcDomain)) + `([^a-z0-9-]|$)`)
+
+    // records are sent with values of source, normalised forms only decide equality
+    lWanted := make(map[string][]map[string]string)
+    lWantedNormalised := make(map[string][]map[string]string)
+    for _, lRecord := range src {
+        lNormalised := NormaliseDnsRecord(lRecord, srcDomain)
+        if options.TypeFilter != nil && !options.TypeFilter.MatchString(lNormalised["Type"]) {
+            continue
+        }
+        if options.NameFilter != nil && !options.NameFilter.MatchString(lNormalised["Name"]) {
+            continue
+        }
+        r := dnsRecordRelative(lRecord, srcDomain)
+        r["Type"] = lNormalised["Type"]
+        if options.Rewrite {
+            for _, lField := range C_A24ApiClient_DnsRecordFields[r["Type"]] {
+                if !lField.Numeric {
+                    r[lField.Key] = lRewrite.ReplaceAllString(r[lField.Key], "${1}" + dnsCanonicalHost(dstDomain) + "${2}")
+                }
+            }
+        }
+        r["Domain"] = dstDomain
+        delete(r, "HashId")
+        lKey := r["Type"] + " " + strings.ToLower(r["Name"])
+        lWanted[lKey] = append(lWanted[lKey], r)
+        lWantedNormalised[lKey] = append(lWantedNormalised[lKey], NormaliseDnsRecord(r, dstDomain))
+    }
+
+    lExisting := make(map[string][]map[string]string)
+    lExistingNormalised := make(map[string][]map[string]string)
+    for _, lRecord := range dst {
+        r := NormaliseDnsRecord(lRecord, dstDomain)
+        lKey := r["Type"] + " " + strings.ToLower(r["Name"])
+        lExisting[lKey] = append(lExisting[lKey], lRecord)
+        lExistingNormalised[lKey] = append(lExistingNormalised[lKey], r)
+    }
+
+    var lKeys []string
+    for lKey := range lWanted {
+        lKeys = append(lKeys, lKey)
+    }
+    sort.Strings(lKeys)
+
+    var lChanges []T_DnsChange
+    var lSkipped []string
+    for _, lKey := range lKeys {
+        lPlan := dnsPlanRRsetCompared(lExisting[lKey], lWanted[lKey], lExistingNormalised[lKey], lWantedNormalised[lKey])
+        if len(lPlan) == 0 {
+            continue
+        }
+        if len(lExisting[lKey]) > 0 {
+            switch options.OnConflict {
+                case C_DnsCopy_Skip:
+                    lSkipped = append(lSkipped, lKey)
+                    continue
+                case C_DnsCopy_Fail:
+                    return nil, nil, NewA24ApiClientError(fmt.Sprintf("Error: Rrset %s already exists in %s with different records.", lKey, dstDomain))
+            }
+        }
+        lChanges = append(lChanges, lPlan...)
+    }
+    return lChanges, lSkipped, nil
+}
+
+// DnsPlanCopyLive loads records of both domains and plans copy
+func (c *T_A24ApiClient) DnsPlanCopyLive(srcDomain, dstDomain string, options T_DnsCopyOptions) ([]T_DnsChange, []string, error) {
+    _, lSrc, err := c.DnsSnapshot(srcDomain)
+    if err != nil {
+        return nil, nil, err
+    }
+    _, lDst, err := c.DnsSnapshot(dstDomain)
+    if err != nil {
+        return nil, nil, err
+    }
+    return DnsPlanCopy(lSrc.RecordMaps(), srcDomain, lDst.RecordMaps(), dstDomain, options)
+}
@@ -0,0 +1,38 @@
+package a24apiclient
+
+import (
+    "testing"
+)
+
+func TestDnsPlanCopyKeepsSourceValues(t *testing.T) {
+    lSrc := []map[string]string{
+        { "Domain": "src.example", "HashId": "s1", "Type": "CNAME", "Name": "www", "Ttl": "300", "Alias": "Web.Example.NET" },
+        { "Domain": "src.example", "HashId": "s2", "Type": "MX", "Name": "@", "Ttl": "3600", "Priority": "10", "Mailserver": "Mail.Example.NET" },
+        { "Domain": "src.example", "HashId": "s3", "Type": "MX", "Name": "@", "Ttl": "3600", "Priority": "20", "Mailserver": "Backup.Example.NET" },
+    }
+    lDst := []map[string]string{
+        { "Domain": "dst.example", "HashId": "d1", "Type": "CNAME", "Name": "www", "Ttl": "300", "Alias": "web.example.net." },
+        { "Domain": "dst.example", "HashId": "d2", "Type": "MX", "Name": "@", "Ttl": "3600", "Priority": "10", "Mailserver": "mail.example.net." },
+    }
+    lChanges, _, err := DnsPlanCopy(lSrc, "src.example", lDst, "dst.example", T_DnsCopyOptions{ OnConflict: C_DnsCopy_Overwrite })
+    if err != nil {
+        t.Fatalf("DnsPlanCopy failed: %v", err)
+    }
+    // normalised equal records are left alone, created one keeps case and form of source
+    if len(lChanges) != 1 || lChanges[0].Action != "create" {
+        t.Fatalf("DnsPlanCopy changes %+v", lChanges)
+    }
+    lRecord := lChanges[0].Record
+    if lRecord["Mailserver"] != "Backup.Example.NET" || lRecord["Domain"] != "dst.example" || lRecord["HashId"] != "" {
+        t.Errorf("created record %v", lRecord)
+    }
+
+    lDst[1]["Priority"] = "5"
+    lChanges, _, err = DnsPlanCopy(lSrc, "src.example", lDst, "dst.example", T_DnsCopyOptions{ OnConflict: C_DnsCopy_Overwrite })
+    if err != nil {
+        t.Fatalf("DnsPlanCopy failed: %v", err)
+    }
+    if len(lChanges) != 2 || lChanges[0].Action != "update" || lChanges[0].Record["Mailserver"] != "Mail.Example.NET" || lChanges[0].Record["HashId"] != "d2" || lChanges[0].Previous["Mailserver"] != "mail.example.net." {
+        t.Errorf("DnsPlanCopy changes %+v", lChanges)
+    }
+}
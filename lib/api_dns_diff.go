@@ -0,0 +1,227 @@
+package a24apiclient
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+
+// T_DnsSource is one side of diff: live domain, snapshot, desired-state file or BIND zone file
+type T_DnsSource struct {
+    Kind            string                `json:"kind"`
+    Source          string                `json:"source"`
+    Domain          string                `json:"domain"`
+    Records         []map[string]string   `json:"-"`
+    Warnings        []string              `json:"warnings,omitempty"`
+}
+
+type T_DnsDiffEntry struct {
+    Status          string                `json:"status"`
+    A               map[string]string     `json:"a,omitempty"`
+    B               map[string]string     `json:"b,omitempty"`
+}
+
+const (
+    C_DnsDiff_Added = "added"
+    C_DnsDiff_Removed = "removed"
+    C_DnsDiff_Changed = "changed"
+)
+
+// record fields holding hex strings, compared case-insensitively
+var C_A24ApiClient_DnsHexFields = map[string]string {
+    "SSHFP": "Text",
+    "TLSA": "Hash",
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Load source
+// --------------------------------------------------------------------------------------------------------------------
+//
+// Existing file is a snapshot or desired-state file when it contains json object {"domain": ..., "records": [...]}
+// with records in api format (snapshot also has time), otherwise it is read as BIND zone file. Anything else is
+// a live domain name. Origin is used for files which do not state their domain.
+
+func (c *T_A24ApiClient) LoadDnsSource(source, origin string) (*T_DnsSource, error) {
+    if _, err := os.Stat(source); err != nil {
+        _, lSnapshot, err := c.DnsSnapshot(source)
+        if err != nil {
+            return nil, err
+        }
+        return &T_DnsSource{ Kind: "live", Source: source, Domain: source, Records: lSnapshot.RecordMaps() }, nil
+    }
+
+    lData, err := ioutil.ReadFile(source)
+    if err != nil {
+        return nil, err
+    }
+
+    if strings.HasPrefix(strings.TrimSpace(string(lData)), "{") {
+        var lSnapshot T_DnsSnapshot
+        if err := json.Unmarshal(lData, &lSnapshot); err != nil {
+            return nil, err
+        }
+        s := &T_DnsSource{ Kind: "desired", Source: source, Domain: lSnapshot.Domain }
+        if !lSnapshot.Time.IsZero() {
+            s.Kind = "snapshot"
+        }
+        if s.Domain == "" {
+            s.Domain = origin
+        }
+        lSnapshot.Domain = s.Domain
+        s.Records = lSnapshot.RecordMaps()
+        return s, nil
+    }
+
+    if origin == "" {
+        origin = dnsZoneFileOrigin(source)
+    }
+    z, err := ParseDnsZoneFile(strings.NewReader(string(lData)), origin)
+    if err != nil {
+        return nil, err
+    }
+    return &T_DnsSource{ Kind: "zonefile", Source: source, Domain: z.Origin, Records: z.Records, Warnings: z.Warnings }, nil
+}
+
+// dnsZoneFileOrigin guesses origin from zone file name, e.g. db.example.com or example.com.zone
+func dnsZoneFileOrigin(path string) string {
+    lName := filepath.Base(path)
+    lName = strings.TrimPrefix(lName, "db.")
+    for _, lSuffix := range []string{ ".zone", ".db", ".txt" } {
+        lName = strings.TrimSuffix(lName, lSuffix)
+    }
+    return lName
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Normalise
+// --------------------------------------------------------------------------------------------------------------------
+
+// NormaliseDnsRecord returns copy of record with name relative to origin (@ for apex), lower-case absolute targets,
+// ttl and numeric fields in canonical number format and lower-case hex strings
+func NormaliseDnsRecord(record map[string]string, origin string) map[string]string {
+    r := make(map[string]string)
+    for key, value := range record {
+        r[key] = value
+    }
+    r["Type"] = strings.ToUpper(r["Type"])
+    r["Name"] = DnsNameRelative(r["Name"], origin)
+    if lTtl, err := ParseDnsTtl(r["Ttl"]); err == nil {
+        r["Ttl"] = lTtl
+    } else if lTtl, err := strconv.ParseFloat(r["Ttl"], 64); err == nil {
+        r["Ttl"] = fmt.Sprintf("%g", lTtl)
+    }
+    for _, lField := range C_A24ApiClient_DnsRecordFields[r["Type"]] {
+        if lField.Numeric {
+            if lValue, err := strconv.ParseFloat(r[lField.Key], 64); err == nil {
+                r[lField.Key] = fmt.Sprintf("%g", lValue)
+            }
+        }
+    }
+    if lField, isTarget := C_A24ApiClient_DnsTargetFields[r["Type"]]; isTarget {
+        r[lField] = dnsCanonicalHost(r[lField]) + "."
+    }
+    if lField, isHex := C_A24ApiClient_DnsHexFields[r["Type"]]; isHex {
+        r[lField] = strings.ToLower(r[lField])
+    }
+    return r
+}
+
+// dnsRecordRelative returns copy of record with name relative to origin, values are kept as they are
+func dnsRecordRelative(record map[string]string, origin string) map[string]string {
+    r := make(map[string]string)
+    for key, value := range record {
+        r[key] = value
+    }
+    r["Name"] = DnsNameRelative(r["Name"], origin)
+    return r
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Diff
+// --------------------------------------------------------------------------------------------------------------------
+
+// DnsDiff compares records of a and b normalised against their origins (so staging and production domains can be
+// compared by relative names). Equal records are paired first, remaining records with the same
+// identity are reported as changed, the rest as removed (only in a) or added (only in b).
+func DnsDiff(a, b []map[string]string, originA, originB string, ignoreTtl bool) []T_DnsDiffEntry {
+    var lA, lB []map[string]string
+    for _, lRecord := range a {
+        lA = append(lA, NormaliseDnsRecord(lRecord, originA))
+    }
+    for _, lRecord := range b {
+        lB = append(lB, NormaliseDnsRecord(lRecord, originB))
+    }
+    lUsedA := make(map[int]bool)
+    lUsedB := make(map[int]bool)
+    lEntries := []T_DnsDiffEntry{}
+
+    lEqual := func(x, y map[string]string) bool {
+        if ignoreTtl {
+            x = dnsRecordWithTtl(x, "0")
+            y = dnsRecordWithTtl(y, "0")
+        }
+        return DnsRecordEqual(x, y)
+    }
+
+    for indexA, lRecordA := range lA {
+        for indexB, lRecordB := range lB {
+            if !lUsedB[indexB] && lEqual(lRecordA, lRecordB) {
+                lUsedA[indexA] = true
+                lUsedB[indexB] = true
+                break
+            }
+        }
+    }
+    for indexA, lRecordA := range lA {
+        if lUsedA[indexA] {
+            continue
+        }
+        for indexB, lRecordB := range lB {
+            if !lUsedB[indexB] && DnsRecordSameIdentity(lRecordA, lRecordB) {
+                lUsedA[indexA] = true
+                lUsedB[indexB] = true
+                lEntries = append(lEntries, T_DnsDiffEntry{ Status: C_DnsDiff_Changed, A: lRecordA, B: lRecordB })
+                break
+            }
+        }
+        if !lUsedA[indexA] {
+            lEntries = append(lEntries, T_DnsDiffEntry{ Status: C_DnsDiff_Removed, A: lRecordA })
+        }
+    }
+    for indexB, lRecordB := range lB {
+        if !lUsedB[indexB] {
+            lEntries = append(lEntries, T_DnsDiffEntry{ Status: C_DnsDiff_Added, B: lRecordB })
+        }
+    }
+
+    sort.SliceStable(lEntries, func(i, j int) bool {
+        return dnsDiffSortKey(lEntries[i]) < dnsDiffSortKey(lEntries[j])
+    })
+    return lEntries
+}
+
+func dnsRecordWithTtl(record map[string]string, ttl string) map[string]string {
+    r := make(map[string]string)
+    for key, value := range record {
+        r[key] = value
+    }
+    r["Ttl"] = ttl
+    return r
+}
+
+func dnsDiffSortKey(entry T_DnsDiffEntry) string {
+    lRecord := entry.A
+    if lRecord == nil {
+        lRecord = entry.B
+    }
+    return strings.ToLower(lRecord["Name"]) + "\x00" + lRecord["Type"] + "\x00" + DnsRecordValue(lRecord)
+}
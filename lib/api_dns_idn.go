@@ -0,0 +1,385 @@
+package a24apiclient
+
+import (
+    "fmt"
+    "net/url"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Internationalised domain names
+// --------------------------------------------------------------------------------------------------------------------
+//
+// Domains and record names may be given in unicode (příklad.cz), api uses ascii form (xn--pklad-zsa96e.cz). Labels
+// with non-ascii characters are lowercased and encoded by punycode (RFC 3492) with xn-- prefix, ascii labels are kept
+// as they are. This is punycode conversion with basic label checks (letters, digits and combining marks only, no
+// leading combining mark, hyphen rules and length of RFC 5891), not full IDNA 2008: input is not normalised to NFC
+// and code points are not checked against IDNA 2008 tables (RFC 5892), so labels are expected in composed form,
+// which is what keyboards produce, and the registry is left to reject what it does not allow.
+
+const (
+    C_DnsIdn_Prefix = "xn--"
+    C_DnsIdn_MaxLabel = 63
+
+    C_Punycode_Base = 36
+    C_Punycode_Tmin = 1
+    C_Punycode_Tmax = 26
+    C_Punycode_Skew = 38
+    C_Punycode_Damp = 700
+    C_Punycode_InitialBias = 72
+    C_Punycode_InitialN = 128
+    C_Punycode_MaxInt = 1 << 31 - 1
+)
+
+// record fields holding domain names which are converted between unicode and ascii form
+var C_DnsIdn_Fields = []string{ "Domain", "Name", "Alias", "NameServer", "Mailserver", "Target" }
+
+// label separators mapped to dot (IDNA 2003 compatible)
+var C_DnsIdn_Dots = strings.NewReplacer("。", ".", "．", ".", "｡", ".")
+
+// --------------------------------------------------------------------------------------------------------------------
+// Convert names
+// --------------------------------------------------------------------------------------------------------------------
+
+// DnsIdnToAscii converts domain or record name into ascii form, @, * and trailing dot are kept
+func DnsIdnToAscii(name string) (string, error) {
+    if dnsIsAscii(name) {
+        return name, nil
+    }
+    lLabels := strings.Split(C_DnsIdn_Dots.Replace(name), ".")
+    for index, lLabel := range lLabels {
+        if dnsIsAscii(lLabel) {
+            continue
+        }
+        lLabel = strings.ToLower(lLabel)
+        for lPosition, ch := range lLabel {
+            if lPosition == 0 && unicode.IsMark(ch) {
+                return "", NewA24ApiClientError(fmt.Sprintf("Error: Invalid domain name %s: label %s starts with combining mark.", name, lLabel))
+            }
+            if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) && !unicode.IsMark(ch) && ch != '-' && ch != '_' {
+                return "", NewA24ApiClientError(fmt.Sprintf("Error: Invalid domain name %s: character %q is not allowed.", name, ch))
+            }
+        }
+        // hyphen rules of RFC 5891 4.2.3.1
+        if strings.HasPrefix(lLabel, "-") || strings.HasSuffix(lLabel, "-") {
+            return "", NewA24ApiClientError(fmt.Sprintf("Error: Invalid domain name %s: label %s starts or ends with hyphen.", name, lLabel))
+        }
+        if lRunes := []rune(lLabel); len(lRunes) >= 4 && lRunes[2] == '-' && lRunes[3] == '-' {
+            return "", NewA24ApiClientError(fmt.Sprintf("Error: Invalid domain name %s: label %s has hyphens in 3rd and 4th position.", name, lLabel))
+        }
+        lEncoded, err := punycodeEncode(lLabel)
+        if err != nil {
+            return "", NewA24ApiClientError(fmt.Sprintf("Error: Invalid domain name %s: %s.", name, err))
+        }
+        lLabels[index] = C_DnsIdn_Prefix + lEncoded
+        if len(lLabels[index]) > C_DnsIdn_MaxLabel {
+            return "", NewA24ApiClientError(fmt.Sprintf("Error: Invalid domain name %s: label %s is longer than %d characters in ascii form.", name, lLabel, C_DnsIdn_MaxLabel))
+        }
+    }
+    return strings.Join(lLabels, "."), nil
+}
+
+// DnsIdnToUnicode converts xn-- labels of domain or record name into unicode, labels which are not valid punycode
+// are kept
+func DnsIdnToUnicode(name string) string {
+    if !strings.Contains(strings.ToLower(name), C_DnsIdn_Prefix) {
+        return name
+    }
+    lLabels := strings.Split(name, ".")
+    for index, lLabel := range lLabels {
+        if !strings.HasPrefix(strings.ToLower(lLabel), C_DnsIdn_Prefix) {
+            continue
+        }
+        if lDecoded, err := punycodeDecode(strings.ToLower(lLabel[len(C_DnsIdn_Prefix):])); err == nil {
+            lLabels[index] = lDecoded
+        }
+    }
+    return strings.Join(lLabels, ".")
+}
+
+// DnsRecordToAscii returns copy of record with domain, name and host name values in ascii form
+func DnsRecordToAscii(record map[string]string) (map[string]string, error) {
+    r := make(map[string]string)
+    for key, value := range record {
+        r[key] = value
+    }
+    for _, lKey := range C_DnsIdn_Fields {
+        if _, isPresent := r[lKey]; !isPresent {
+            continue
+        }
+        lAscii, err := DnsIdnToAscii(r[lKey])
+        if err != nil {
+            return nil, err
+        }
+        r[lKey] = lAscii
+    }
+    return r, nil
+}
+
+// DnsRecordToUnicode returns copy of record with domain, name and host name values in unicode form
+func DnsRecordToUnicode(record map[string]string) map[string]string {
+    if record == nil {
+        return nil
+    }
+    r := make(map[string]string)
+    for key, value := range record {
+        r[key] = value
+    }
+    for _, lKey := range C_DnsIdn_Fields {
+        if _, isPresent := r[lKey]; isPresent {
+            r[lKey] = DnsIdnToUnicode(r[lKey])
+        }
+    }
+    return r
+}
+
+// DnsDataToUnicode converts names in results of dns functions (domain list, record list, records of several domains,
+// record maps, changes, batch results and diff entries) into unicode form, other data is returned unchanged
+func DnsDataToUnicode(data interface{}) interface{} {
+    switch t := data.(type) {
+        case T_DnsDomainList:
+            r := T_DnsDomainList{}
+            for _, element := range t {
+                r = append(r, DnsIdnToUnicode(element))
+            }
+            return r
+        case T_DnsRecordList:
+            r := T_DnsRecordList{}
+            for _, element := range t {
+                lElement := make(map[string]interface{})
+                for key, value := range element {
+                    lElement[key] = value
+                }
+                for _, lField := range C_DnsIdn_Fields {
+                    lKey := strings.ToLower(lField[:1]) + lField[1:]
+                    if lValue, isString := lElement[lKey].(string); isString {
+                        lElement[lKey] = DnsIdnToUnicode(lValue)
+                    }
+                }
+                r = append(r, lElement)
+            }
+            return r
+        case T_DnsDomainRecords:
+            t.Domain = DnsIdnToUnicode(t.Domain)
+            t.Records = DnsDataToUnicode(t.Records).(T_DnsRecordList)
+            return t
+        case []T_DnsDomainRecords:
+            var r []T_DnsDomainRecords
+            for _, element := range t {
+                r = append(r, DnsDataToUnicode(element).(T_DnsDomainRecords))
+            }
+            return r
+        case []map[string]string:
+            r := []map[string]string{}
+            for _, element := range t {
+                r = append(r, DnsRecordToUnicode(element))
+            }
+            return r
+        case []T_DnsChange:
+            r := []T_DnsChange{}
+            for _, element := range t {
+                element.Record = DnsRecordToUnicode(element.Record)
+                element.Previous = DnsRecordToUnicode(element.Previous)
+                r = append(r, element)
+            }
+            return r
+        case T_DnsBatchResult:
+            t.Record = DnsRecordToUnicode(t.Record)
+            return t
+        case []T_DnsBatchResult:
+            r := []T_DnsBatchResult{}
+            for _, element := range t {
+                r = append(r, DnsDataToUnicode(element).(T_DnsBatchResult))
+            }
+            return r
+        case []T_DnsDiffEntry:
+            r := []T_DnsDiffEntry{}
+            for _, element := range t {
+                element.A = DnsRecordToUnicode(element.A)
+                element.B = DnsRecordToUnicode(element.B)
+                r = append(r, element)
+            }
+            return r
+    }
+    return data
+}
+
+// dnsUrl builds api url of domain, domain is converted into ascii form and path segments are escaped; invalid
+// internationalised domain is an error
+func (c *T_A24ApiClient) dnsUrl(domain string, segments ...string) (string, error) {
+    lAscii, err := DnsIdnToAscii(domain)
+    if err != nil {
+        return "", err
+    }
+    lPath := "/dns/" + url.PathEscape(lAscii)
+    for _, lSegment := range segments {
+        lPath += "/" + url.PathEscape(lSegment)
+    }
+    return c.Config["endpoint"] + lPath, nil
+}
+
+func dnsIsAscii(text string) bool {
+    for index := 0; index < len(text); index++ {
+        if text[index] >= utf8.RuneSelf {
+            return false
+        }
+    }
+    return true
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Punycode (RFC 3492)
+// --------------------------------------------------------------------------------------------------------------------
+
+func punycodeEncode(label string) (string, error) {
+    lInput := []rune(label)
+    var lOutput strings.Builder
+    for _, ch := range lInput {
+        if ch < C_Punycode_InitialN {
+            lOutput.WriteRune(ch)
+        }
+    }
+    lBasic := lOutput.Len()
+    lHandled := lBasic
+    if lBasic > 0 {
+        lOutput.WriteByte('-')
+    }
+
+    n, delta, bias := C_Punycode_InitialN, 0, C_Punycode_InitialBias
+    for lHandled < len(lInput) {
+        m := C_Punycode_MaxInt
+        for _, ch := range lInput {
+            if int(ch) >= n && int(ch) < m {
+                m = int(ch)
+            }
+        }
+        if m - n > (C_Punycode_MaxInt - delta) / (lHandled + 1) {
+            return "", NewA24ApiClientError("punycode overflow")
+        }
+        delta += (m - n) * (lHandled + 1)
+        n = m
+        for _, ch := range lInput {
+            if int(ch) < n {
+                delta++
+                if delta == C_Punycode_MaxInt {
+                    return "", NewA24ApiClientError("punycode overflow")
+                }
+            }
+            if int(ch) != n {
+                continue
+            }
+            q := delta
+            for k := C_Punycode_Base; ; k += C_Punycode_Base {
+                t := punycodeThreshold(k, bias)
+                if q < t {
+                    break
+                }
+                lOutput.WriteByte(punycodeDigit(t + (q - t) % (C_Punycode_Base - t)))
+                q = (q - t) / (C_Punycode_Base - t)
+            }
+            lOutput.WriteByte(punycodeDigit(q))
+            bias = punycodeAdapt(delta, lHandled + 1, lHandled == lBasic)
+            delta = 0
+            lHandled++
+        }
+        delta++
+        n++
+    }
+    return lOutput.String(), nil
+}
+
+func punycodeDecode(encoded string) (string, error) {
+    var lOutput []rune
+    lPosition := 0
+    if lDelimiter := strings.LastIndex(encoded, "-"); lDelimiter >= 0 {
+        for index := 0; index < lDelimiter; index++ {
+            if encoded[index] >= C_Punycode_InitialN {
+                return "", NewA24ApiClientError("punycode basic code point is not ascii")
+            }
+            lOutput = append(lOutput, rune(encoded[index]))
+        }
+        lPosition = lDelimiter + 1
+    }
+
+    n, i, bias := C_Punycode_InitialN, 0, C_Punycode_InitialBias
+    for lPosition < len(encoded) {
+        lOld, w := i, 1
+        for k := C_Punycode_Base; ; k += C_Punycode_Base {
+            if lPosition >= len(encoded) {
+                return "", NewA24ApiClientError("punycode is truncated")
+            }
+            lDigit := punycodeDigitValue(encoded[lPosition])
+            lPosition++
+            if lDigit < 0 {
+                return "", NewA24ApiClientError("punycode has invalid digit")
+            }
+            if lDigit > (C_Punycode_MaxInt - i) / w {
+                return "", NewA24ApiClientError("punycode overflow")
+            }
+            i += lDigit * w
+            t := punycodeThreshold(k, bias)
+            if lDigit < t {
+                break
+            }
+            w *= C_Punycode_Base - t
+        }
+        lLength := len(lOutput) + 1
+        bias = punycodeAdapt(i - lOld, lLength, lOld == 0)
+        n += i / lLength
+        i %= lLength
+        if n > unicode.MaxRune {
+            return "", NewA24ApiClientError("punycode code point out of range")
+        }
+        lOutput = append(lOutput, 0)
+        copy(lOutput[i + 1:], lOutput[i:])
+        lOutput[i] = rune(n)
+        i++
+    }
+    return string(lOutput), nil
+}
+
+func punycodeThreshold(k, bias int) int {
+    switch {
+        case k <= bias:
+            return C_Punycode_Tmin
+        case k >= bias + C_Punycode_Tmax:
+            return C_Punycode_Tmax
+    }
+    return k - bias
+}
+
+func punycodeAdapt(delta, length int, first bool) int {
+    if first {
+        delta /= C_Punycode_Damp
+    } else {
+        delta /= 2
+    }
+    delta += delta / length
+    k := 0
+    for delta > ((C_Punycode_Base - C_Punycode_Tmin) * C_Punycode_Tmax) / 2 {
+        delta /= C_Punycode_Base - C_Punycode_Tmin
+        k += C_Punycode_Base
+    }
+    return k + (C_Punycode_Base - C_Punycode_Tmin + 1) * delta / (delta + C_Punycode_Skew)
+}
+
+func punycodeDigit(digit int) byte {
+    if digit < 26 {
+        return byte('a' + digit)
+    }
+    return byte('0' + digit - 26)
+}
+
+func punycodeDigitValue(ch byte) int {
+    switch {
+        case ch >= '0' && ch <= '9':
+            return int(ch - '0') + 26
+        case ch >= 'a' && ch <= 'z':
+            return int(ch - 'a')
+        case ch >= 'A' && ch <= 'Z':
+            return int(ch - 'A')
+    }
+    return -1
+}
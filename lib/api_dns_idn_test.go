@@ -0,0 +1,81 @@
+package a24apiclient
+
+import (
+    "testing"
+)
+
+func TestDnsIdnToAscii(t *testing.T) {
+    lTests := []struct {
+        name            string
+        ascii           string
+        isError         bool
+    }{
+        { "example.com", "example.com", false },
+        { "WWW.Example.com.", "WWW.Example.com.", false },
+        { "příklad.cz", "xn--pklad-zsa96e.cz", false },
+        { "PŘÍKLAD.cz", "xn--pklad-zsa96e.cz", false },
+        { "www.příklad.cz.", "www.xn--pklad-zsa96e.cz.", false },
+        { "příklad。cz", "xn--pklad-zsa96e.cz", false },
+        { "bücher.example", "xn--bcher-kva.example", false },
+        { "münchen-ost.example", "xn--mnchen-ost-9db.example", false },
+        { "日本語.jp", "xn--wgv71a119e.jp", false },
+        { "@", "@", false },
+        { "*.příklad.cz", "*.xn--pklad-zsa96e.cz", false },
+        { "-příklad.cz", "", true },
+        { "příklad-.cz", "", true },
+        { "př--íklad.cz", "", true },
+        { "́příklad.cz", "", true },
+        { "pří klad.cz", "", true },
+        { "pří☃.cz", "", true },
+        { "řaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.cz", "", true },
+    }
+    for _, lTest := range lTests {
+        lAscii, err := DnsIdnToAscii(lTest.name)
+        if (err != nil) != lTest.isError {
+            t.Errorf("DnsIdnToAscii(%q) error %v", lTest.name, err)
+            continue
+        }
+        if lAscii != lTest.ascii {
+            t.Errorf("DnsIdnToAscii(%q) = %q, want %q", lTest.name, lAscii, lTest.ascii)
+        }
+    }
+}
+
+func TestDnsIdnToUnicode(t *testing.T) {
+    lTests := []struct {
+        name            string
+        unicode         string
+    }{
+        { "example.com", "example.com" },
+        { "xn--pklad-zsa96e.cz", "příklad.cz" },
+        { "WWW.XN--PKLAD-ZSA96E.cz.", "WWW.příklad.cz." },
+        { "xn--wgv71a119e.jp", "日本語.jp" },
+        { "xn--!!.example", "xn--!!.example" },
+    }
+    for _, lTest := range lTests {
+        if lUnicode := DnsIdnToUnicode(lTest.name); lUnicode != lTest.unicode {
+            t.Errorf("DnsIdnToUnicode(%q) = %q, want %q", lTest.name, lUnicode, lTest.unicode)
+        }
+        if lAscii, err := DnsIdnToAscii(DnsIdnToUnicode(lTest.name)); err != nil {
+            t.Errorf("DnsIdnToAscii(DnsIdnToUnicode(%q)) error %v", lTest.name, err)
+        } else if DnsIdnToUnicode(lAscii) != lTest.unicode {
+            t.Errorf("round trip of %q gives %q", lTest.name, lAscii)
+        }
+    }
+}
+
+func TestDnsUrlInvalidIdn(t *testing.T) {
+    c := NewA24ApiClient(map[string]string{ "endpoint": "http://127.0.0.1:1" })
+    if lUrl, err := c.dnsUrl("příklad.cz", "records", "v1"); err != nil || lUrl != "http://127.0.0.1:1/dns/xn--pklad-zsa96e.cz/records/v1" {
+        t.Errorf("dnsUrl = %q, %v", lUrl, err)
+    }
+    if _, err := c.dnsUrl("-příklad.cz", "records", "v1"); err == nil {
+        t.Errorf("dnsUrl of invalid domain did not fail")
+    }
+    if _, _, err := c.DnsListRecords(map[string]string{ "0": "-příklad.cz" }); err == nil {
+        t.Errorf("DnsListRecords of invalid domain did not fail")
+    }
+    if _, _, err := c.DnsDelete(map[string]string{ "Domain": "-příklad.cz", "HashId": "h1" }); err == nil {
+        t.Errorf("DnsDelete of invalid domain did not fail")
+    }
+}
@@ -0,0 +1,89 @@
+package a24apiclient
+
+import (
+    "path"
+    "sort"
+    "sync"
+    "time"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+
+type T_DnsDomainRecords struct {
+    Domain          string                `json:"domain"`
+    Code            int                   `json:"code"`
+    Records         T_DnsRecordList       `json:"records"`
+    Error           string                `json:"error,omitempty"`
+    Err             error                 `json:"-"`
+    StartedAt       time.Time             `json:"-"`
+    Duration        time.Duration         `json:"-"`
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Select domains
+// --------------------------------------------------------------------------------------------------------------------
+
+// DnsSelectDomains returns sorted domains of account matching glob pattern (path.Match syntax), empty pattern
+// matches all domains
+func (c *T_A24ApiClient) DnsSelectDomains(pattern string) (int, []string, error) {
+    rc, lData, err := c.DnsListDomains()
+    if err != nil {
+        return rc, nil, err
+    }
+    if err := c.dnsResponseError(rc, "list"); err != nil {
+        return rc, nil, err
+    }
+    var lDomains []string
+    for _, lDomain := range lData.(T_DnsDomainList) {
+        if pattern != "" {
+            // pattern may be given in ascii or unicode form
+            lMatch, err := path.Match(pattern, lDomain)
+            if err != nil {
+                return rc, nil, err
+            }
+            if lMatchUnicode, _ := path.Match(pattern, DnsIdnToUnicode(lDomain)); !lMatch && !lMatchUnicode {
+                continue
+            }
+        }
+        lDomains = append(lDomains, lDomain)
+    }
+    sort.Strings(lDomains)
+    return rc, lDomains, nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// List records of several domains
+// --------------------------------------------------------------------------------------------------------------------
+
+// DnsListRecordsMulti lists records of domains concurrently, results keep order of domains
+func (c *T_A24ApiClient) DnsListRecordsMulti(domains []string, concurrency int) []T_DnsDomainRecords {
+    return c.DnsListRecordsMultiStream(domains, concurrency, nil)
+}
+
+// DnsListRecordsMultiStream is DnsListRecordsMulti which also passes records of each domain to emit as soon as
+// they are listed (completion order). Calls of emit are serialized.
+func (c *T_A24ApiClient) DnsListRecordsMultiStream(domains []string, concurrency int, emit func(T_DnsDomainRecords)) []T_DnsDomainRecords {
+    lResults := make([]T_DnsDomainRecords, len(domains))
+    var lEmitMutex sync.Mutex
+    RunWorkerPool(len(domains), concurrency, false, func(index int) error {
+        r := T_DnsDomainRecords{ Domain: domains[index], StartedAt: time.Now() }
+        r.Code, r.Records, r.Err = c.DnsListRecords(map[string]string{ "0": domains[index] })
+        r.Duration = time.Since(r.StartedAt)
+        if r.Err == nil {
+            r.Err = c.dnsResponseError(r.Code, "list")
+        }
+        if r.Err != nil {
+            r.Error = r.Err.Error()
+        }
+        lResults[index] = r
+        if emit != nil {
+            lEmitMutex.Lock()
+            emit(r)
+            lEmitMutex.Unlock()
+        }
+        return r.Err
+    })
+    return lResults
+}
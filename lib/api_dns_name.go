@@ -0,0 +1,89 @@
+package a24apiclient
+
+import (
+    "fmt"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Record names
+// --------------------------------------------------------------------------------------------------------------------
+//
+// Record names are accepted relative to domain (www), as FQDN with or without trailing dot (www.example.com,
+// www.example.com.) or as apex (@, empty or the domain itself). Canonical form used in requests and output is
+// relative with @ for apex; case given by user is kept and names are compared case-insensitively (DnsNameEqual).
+
+const (
+    C_DnsName_Apex = "@"
+)
+
+// NormaliseDnsName converts name into canonical form relative to origin, absolute names (trailing dot) outside
+// origin are rejected
+func NormaliseDnsName(name, origin string) (string, error) {
+    lOrigin := dnsCanonicalHost(origin)
+    if lAbsolute := dnsCanonicalHost(name); strings.HasSuffix(name, ".") && lOrigin != "" {
+        if lAbsolute != lOrigin && !strings.HasSuffix(lAbsolute, "." + lOrigin) {
+            return "", NewA24ApiClientError(fmt.Sprintf("Error: Name %s is outside of domain %s.", name, lOrigin))
+        }
+    }
+    return DnsNameRelative(name, origin), nil
+}
+
+// DnsNameRelative returns name relative to origin with @ for apex, names outside origin lose trailing dot only;
+// origin is matched case-insensitively, case of the rest is kept
+func DnsNameRelative(name, origin string) string {
+    lName := strings.TrimSpace(name)
+    lOrigin := dnsCanonicalHost(origin)
+    if lName == "" || lName == C_DnsName_Apex {
+        return C_DnsName_Apex
+    }
+    lName = strings.TrimSuffix(lName, ".")
+    if lOrigin != "" {
+        lLower := strings.ToLower(lName)
+        if lLower == lOrigin {
+            return C_DnsName_Apex
+        }
+        if strings.HasSuffix(lLower, "." + lOrigin) {
+            return lName[:len(lName) - len(lOrigin) - 1]
+        }
+    }
+    return lName
+}
+
+// DnsNameEqual reports whether names are the same, dns names are case-insensitive
+func DnsNameEqual(a, b string) bool {
+    return strings.EqualFold(a, b)
+}
+
+// DnsNameAbsolute returns FQDN of name with trailing dot, names with trailing dot are kept
+func DnsNameAbsolute(name, origin string) string {
+    lOrigin := dnsCanonicalHost(origin)
+    if strings.HasSuffix(name, ".") && name != "." {
+        return name
+    }
+    lName := DnsNameRelative(name, lOrigin)
+    if lName == C_DnsName_Apex {
+        return lOrigin + "."
+    }
+    if lOrigin == "" {
+        return lName + "."
+    }
+    return lName + "." + lOrigin + "."
+}
+
+// NormaliseDnsRecordName returns copy of record with name in canonical form relative to its domain
+func NormaliseDnsRecordName(record map[string]string) (map[string]string, error) {
+    r := make(map[string]string)
+    for key, value := range record {
+        r[key] = value
+    }
+    if _, isPresent := r["Name"]; !isPresent {
+        return r, nil
+    }
+    lName, err := NormaliseDnsName(r["Name"], r["Domain"])
+    if err != nil {
+        return nil, err
+    }
+    r["Name"] = lName
+    return r, nil
+}
@@ -0,0 +1,56 @@
+package a24apiclient
+
+import (
+    "testing"
+)
+
+func TestNormaliseDnsName(t *testing.T) {
+    lTests := []struct {
+        name            string
+        origin          string
+        relative        string
+        absolute        string
+        isError         bool
+    }{
+        { "www", "example.com", "www", "www.example.com.", false },
+        { "", "example.com", "@", "example.com.", false },
+        { "@", "example.com", "@", "example.com.", false },
+        { "example.com", "example.com", "@", "example.com.", false },
+        { "www.example.com.", "example.com", "www", "www.example.com.", false },
+        { "a24test-A", "example.com", "a24test-A", "a24test-A.example.com.", false },
+        { "Mail.Example.COM.", "example.com", "Mail", "Mail.Example.COM.", false },
+        { "EXAMPLE.com", "Example.Com.", "@", "example.com.", false },
+        { "www.example.org.", "example.com", "", "", true },
+    }
+    for _, lTest := range lTests {
+        lRelative, err := NormaliseDnsName(lTest.name, lTest.origin)
+        if (err != nil) != lTest.isError {
+            t.Errorf("NormaliseDnsName(%q, %q) error %v", lTest.name, lTest.origin, err)
+            continue
+        }
+        if err != nil {
+            continue
+        }
+        if lRelative != lTest.relative {
+            t.Errorf("NormaliseDnsName(%q, %q) = %q, want %q", lTest.name, lTest.origin, lRelative, lTest.relative)
+        }
+        if lAbsolute := DnsNameAbsolute(lTest.name, lTest.origin); lAbsolute != lTest.absolute {
+            t.Errorf("DnsNameAbsolute(%q, %q) = %q, want %q", lTest.name, lTest.origin, lAbsolute, lTest.absolute)
+        }
+    }
+}
+
+func TestDnsPlanRRsetIgnoresNameCase(t *testing.T) {
+    lExisting := []map[string]string{
+        { "Domain": "example.com", "HashId": "1", "Type": "A", "Name": "a24test-A", "Ttl": "3600", "Ip": "192.0.2.1" },
+    }
+    lDesired := []map[string]string{
+        { "Domain": "example.com", "Type": "A", "Name": "A24TEST-a", "Ttl": "3600", "Ip": "192.0.2.1" },
+    }
+    if lChanges := DnsPlanRRset(lExisting, lDesired); len(lChanges) != 0 {
+        t.Errorf("DnsPlanRRset with names differing in case got %+v", lChanges)
+    }
+    if !DnsNameEqual("Www", "wWW") || DnsNameEqual("www", "www2") {
+        t.Errorf("DnsNameEqual compares case-sensitively")
+    }
+}
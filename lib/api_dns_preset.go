@@ -0,0 +1,342 @@
+package a24apiclient
+
+import (
+    "fmt"
+    "io/ioutil"
+    "regexp"
+    "sort"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+//
+// Preset is a named set of records (api keys type, name, ttl, priority, mailserver, ...) with ${variable}
+// placeholders. Variables have default values, empty default means the variable is required. Variables domain
+// (ascii form) and domain_dashed (dots replaced by hyphens) are always set. User-defined presets are yaml files:
+//
+//   name: example
+//   description: Example mail
+//   variables:
+//     ttl: 3600
+//     mx: ""
+//   records:
+//     - type: MX
+//       name: "@"
+//       ttl: ${ttl}
+//       priority: 10
+//       mailserver: ${mx}
+
+type T_DnsPreset struct {
+    Name            string                `json:"name"`
+    Description     string                `json:"description"`
+    Variables       map[string]string     `json:"variables"`
+    Records         []map[string]string   `json:"records"`
+}
+
+var C_DnsPreset_Variable = regexp.MustCompile(`\$\{([A-Za-z0-9_]+)\}`)
+
+var C_DnsPreset_Builtin = map[string]T_DnsPreset {
+    "microsoft365": {
+        Name: "microsoft365",
+        Description: "Microsoft 365 mail (Exchange Online) with autodiscover and Teams/Skype records",
+        Variables: map[string]string{ "ttl": "3600", "tenant": "${domain_dashed}" },
+        Records: []map[string]string {
+            { "type": "MX", "name": "@", "ttl": "${ttl}", "priority": "0", "mailserver": "${tenant}.mail.protection.outlook.com" },
+            { "type": "TXT", "name": "@", "ttl": "${ttl}", "text": "v=spf1 include:spf.protection.outlook.com -all" },
+            { "type": "CNAME", "name": "autodiscover", "ttl": "${ttl}", "alias": "autodiscover.outlook.com" },
+            { "type": "CNAME", "name": "sip", "ttl": "${ttl}", "alias": "sipdir.online.lync.com" },
+            { "type": "CNAME", "name": "lyncdiscover", "ttl": "${ttl}", "alias": "webdir.online.lync.com" },
+            { "type": "SRV", "name": "_sip._tls", "ttl": "${ttl}", "priority": "100", "weight": "1", "port": "443", "target": "sipdir.online.lync.com" },
+            { "type": "SRV", "name": "_sipfederationtls._tcp", "ttl": "${ttl}", "priority": "100", "weight": "1", "port": "5061", "target": "sipfed.online.lync.com" },
+        },
+    },
+    "google-workspace": {
+        Name: "google-workspace",
+        Description: "Google Workspace mail",
+        Variables: map[string]string{ "ttl": "3600" },
+        Records: []map[string]string {
+            { "type": "MX", "name": "@", "ttl": "${ttl}", "priority": "1", "mailserver": "smtp.google.com" },
+            { "type": "TXT", "name": "@", "ttl": "${ttl}", "text": "v=spf1 include:_spf.google.com ~all" },
+        },
+    },
+    "active24": {
+        Name: "active24",
+        Description: "Active24 mail hosting with autodiscover (RFC 6186) records",
+        Variables: map[string]string{ "ttl": "3600", "mx1": "mx1.active24.cz", "mx2": "mx2.active24.cz", "imap": "imap.active24.cz", "smtp": "smtp.active24.cz", "spf": "_spf.active24.cz" },
+        Records: []map[string]string {
+            { "type": "MX", "name": "@", "ttl": "${ttl}", "priority": "10", "mailserver": "${mx1}" },
+            { "type": "MX", "name": "@", "ttl": "${ttl}", "priority": "20", "mailserver": "${mx2}" },
+            { "type": "TXT", "name": "@", "ttl": "${ttl}", "text": "v=spf1 include:${spf} ~all" },
+            { "type": "SRV", "name": "_imaps._tcp", "ttl": "${ttl}", "priority": "0", "weight": "1", "port": "993", "target": "${imap}" },
+            { "type": "SRV", "name": "_submission._tcp", "ttl": "${ttl}", "priority": "0", "weight": "1", "port": "587", "target": "${smtp}" },
+        },
+    },
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Load
+// --------------------------------------------------------------------------------------------------------------------
+
+// DnsPresets returns built-in presets ordered by name
+func DnsPresets() []T_DnsPreset {
+    var lPresets []T_DnsPreset
+    for _, lPreset := range C_DnsPreset_Builtin {
+        lPresets = append(lPresets, lPreset)
+    }
+    sort.Slice(lPresets, func(i, j int) bool {
+        return lPresets[i].Name < lPresets[j].Name
+    })
+    return lPresets
+}
+
+// LoadDnsPreset returns built-in preset of name or preset read from yaml file
+func LoadDnsPreset(name string) (*T_DnsPreset, error) {
+    if lPreset, isPresent := C_DnsPreset_Builtin[strings.ToLower(name)]; isPresent {
+        return &lPreset, nil
+    }
+    lData, err := ioutil.ReadFile(name)
+    if err != nil {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Unknown preset %s (not built-in and not readable file).", name))
+    }
+    p, err := NewDnsPreset(lData)
+    if err != nil {
+        return nil, NewA24ApiClientError(fmt.Sprintf("%s (%s)", err, name))
+    }
+    if p.Name == "" {
+        p.Name = name
+    }
+    return p, nil
+}
+
+// NewDnsPreset parses yaml preset
+func NewDnsPreset(data []byte) (*T_DnsPreset, error) {
+    lDocument, err := ParseYaml(data)
+    if err != nil {
+        return nil, err
+    }
+    lMap, isMap := lDocument.(map[string]interface{})
+    if !isMap {
+        return nil, NewA24ApiClientError("Error: Preset must be a mapping with name, variables and records.")
+    }
+    p := &T_DnsPreset{ Variables: make(map[string]string) }
+    for key, value := range lMap {
+        switch key {
+            case "name", "description":
+                lValue, isString := value.(string)
+                if !isString {
+                    return nil, NewA24ApiClientError(fmt.Sprintf("Error: Preset %s must be a string.", key))
+                }
+                if key == "name" {
+                    p.Name = lValue
+                } else {
+                    p.Description = lValue
+                }
+            case "variables":
+                lVariables, isMap := value.(map[string]interface{})
+                if !isMap {
+                    return nil, NewA24ApiClientError("Error: Preset variables must be a mapping.")
+                }
+                for lName, lValue := range lVariables {
+                    lString, isString := lValue.(string)
+                    if !isString {
+                        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Preset variable %s must be a string.", lName))
+                    }
+                    p.Variables[lName] = lString
+                }
+            case "records":
+                lRecords, isList := value.([]interface{})
+                if !isList {
+                    return nil, NewA24ApiClientError("Error: Preset records must be a list.")
+                }
+                for index, lElement := range lRecords {
+                    lFields, isMap := lElement.(map[string]interface{})
+                    if !isMap {
+                        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Preset record %d must be a mapping.", index + 1))
+                    }
+                    lRecord := make(map[string]string)
+                    for lKey, lValue := range lFields {
+                        lString, isString := lValue.(string)
+                        if !isString {
+                            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Preset record %d field %s must be a string.", index + 1, lKey))
+                        }
+                        lRecord[lKey] = lString
+                    }
+                    p.Records = append(p.Records, lRecord)
+                }
+            default:
+                return nil, NewA24ApiClientError(fmt.Sprintf("Error: Unknown preset key %s.", key))
+        }
+    }
+    if len(p.Records) == 0 {
+        return nil, NewA24ApiClientError("Error: Preset has no records.")
+    }
+    return p, nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Expand
+// --------------------------------------------------------------------------------------------------------------------
+
+// Expand returns records of preset for domain with variables set by values over defaults
+func (p *T_DnsPreset) Expand(domain string, values map[string]string) ([]map[string]string, error) {
+    lDomain, err := DnsIdnToAscii(domain)
+    if err != nil {
+        return nil, err
+    }
+    lDomain = dnsCanonicalHost(lDomain)
+    lVariables := map[string]string{ "domain": lDomain, "domain_dashed": strings.Replace(lDomain, ".", "-", -1) }
+    for key, value := range p.Variables {
+        lVariables[key] = value
+    }
+    for key, value := range values {
+        if _, isPresent := p.Variables[key]; !isPresent {
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Preset %s has no variable %s.", p.Name, key))
+        }
+        lVariables[key] = value
+    }
+    var lMissing []string
+    for key := range p.Variables {
+        if lVariables[key] == "" {
+            lMissing = append(lMissing, key)
+        }
+    }
+    if len(lMissing) > 0 {
+        sort.Strings(lMissing)
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Preset %s needs variables %s (--var name=value).", p.Name, strings.Join(lMissing, ", ")))
+    }
+
+    var lRecords []map[string]string
+    for index, lTemplate := range p.Records {
+        lFields := make(map[string]string)
+        for key, value := range lTemplate {
+            lValue, err := dnsPresetSubstitute(value, lVariables)
+            if err != nil {
+                return nil, NewA24ApiClientError(fmt.Sprintf("%s (record %d)", err, index + 1))
+            }
+            lFields[key] = lValue
+        }
+        lType := strings.ToUpper(lFields["type"])
+        lArgs := []string{ lType, lFields["name"], lFields["ttl"] }
+        for _, lField := range C_A24ApiClient_DnsRecordFields[lType] {
+            lArgs = append(lArgs, lFields[lField.ApiKey])
+        }
+        lRecord, err := NewDnsRecord(lDomain, lArgs)
+        if err != nil {
+            return nil, NewA24ApiClientError(fmt.Sprintf("%s (record %d)", err, index + 1))
+        }
+        lRecords = append(lRecords, lRecord)
+    }
+    return lRecords, nil
+}
+
+// dnsPresetSubstitute replaces ${name} by variable value, values may refer to other variables
+func dnsPresetSubstitute(value string, variables map[string]string) (string, error) {
+    for depth := 0; depth < 8 && C_DnsPreset_Variable.MatchString(value); depth++ {
+        var lMissing string
+        value = C_DnsPreset_Variable.ReplaceAllStringFunc(value, func(match string) string {
+            lName := C_DnsPreset_Variable.FindStringSubmatch(match)[1]
+            lValue, isPresent := variables[lName]
+            if !isPresent {
+                lMissing = lName
+            }
+            return lValue
+        })
+        if lMissing != "" {
+            return "", NewA24ApiClientError(fmt.Sprintf("Error: Unknown preset variable %s.", lMissing))
+        }
+    }
+    if C_DnsPreset_Variable.MatchString(value) {
+        return "", NewA24ApiClientError("Error: Preset variables refer to each other in a loop.")
+    }
+    return value, nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Plan
+// --------------------------------------------------------------------------------------------------------------------
+
+// DnsPlanUpsert returns changes upserting desired records into existing ones (see DnsUpsert). An existing SPF
+// record is updated in place as only one SPF record per name is valid. Second value lists existing records of the
+// same name and type which are kept, e.g. MX records of previous mail provider.
+func DnsPlanUpsert(existing, desired []map[string]string, domain string) ([]T_DnsChange, []map[string]string) {
+    lChanges := []T_DnsChange{}
+    lUsed := make(map[int]bool)
+    lRRsets := make(map[string]bool)
+    // normalised records decide equality only, changes carry existing and desired records as they are
+    for _, lRecord := range desired {
+        lDesired := NormaliseDnsRecord(lRecord, domain)
+        lRRsets[lDesired["Type"] + " " + strings.ToLower(lDesired["Name"])] = true
+        lMatched := false
+        for index, lExistingRecord := range existing {
+            lExisting := NormaliseDnsRecord(lExistingRecord, domain)
+            lSpf := lDesired["Type"] == "TXT" && lExisting["Type"] == "TXT" && DnsNameEqual(lDesired["Name"], lExisting["Name"]) && IsSpf(lDesired["Text"]) && IsSpf(lExisting["Text"])
+            if lUsed[index] || (!lSpf && !DnsRecordSameIdentity(lExisting, lDesired)) {
+                continue
+            }
+            lUsed[index] = true
+            lMatched = true
+            if !DnsRecordEqual(lExisting, lDesired) {
+                lChanges = append(lChanges, dnsUpdateChange(lExistingRecord, dnsRecordRelative(lRecord, domain)))
+            }
+            break
+        }
+        if !lMatched {
+            lChanges = append(lChanges, T_DnsChange{ Action: "create", Record: dnsRecordRelative(lRecord, domain) })
+        }
+    }
+    var lKept []map[string]string
+    for index, lRecord := range existing {
+        if !lUsed[index] && lRRsets[lRecord["Type"] + " " + strings.ToLower(DnsNameRelative(lRecord["Name"], domain))] {
+            lKept = append(lKept, lRecord)
+        }
+    }
+    return lChanges, lKept
+}
+
+// DnsPlanPreset expands preset for domain and plans its upsert against live records
+func (c *T_A24ApiClient) DnsPlanPreset(domain string, preset *T_DnsPreset, values map[string]string) ([]T_DnsChange, []map[string]string, error) {
+    lDesired, err := preset.Expand(domain, values)
+    if err != nil {
+        return nil, nil, err
+    }
+    lDomain := lDesired[0]["Domain"]
+    rc, lList, err := c.DnsListRecords(map[string]string{ "0": lDomain })
+    if err != nil {
+        return nil, nil, err
+    }
+    if err := c.dnsResponseError(rc, "list"); err != nil {
+        return nil, nil, err
+    }
+    var lExisting []map[string]string
+    for _, element := range lList {
+        lExisting = append(lExisting, NewDnsRecordFromList(lDomain, element))
+    }
+    lChanges, lKept := DnsPlanUpsert(lExisting, lDesired, lDomain)
+    return lChanges, lKept, nil
+}
+
+// DnsPlanPresetMulti plans preset for each of domains, records are listed concurrently. Changes and kept records
+// of all domains are returned in order of domains, domain which fails to list stops planning.
+func (c *T_A24ApiClient) DnsPlanPresetMulti(domains []string, preset *T_DnsPreset, values map[string]string, concurrency int) ([]T_DnsChange, []map[string]string, error) {
+    lChanges := []T_DnsChange{}
+    var lKept []map[string]string
+    for _, lDomainRecords := range c.DnsListRecordsMulti(domains, concurrency) {
+        if lDomainRecords.Err != nil {
+            return nil, nil, NewA24ApiClientError(fmt.Sprintf("%s: %s", lDomainRecords.Domain, lDomainRecords.Err))
+        }
+        lDesired, err := preset.Expand(lDomainRecords.Domain, values)
+        if err != nil {
+            return nil, nil, err
+        }
+        var lExisting []map[string]string
+        for _, element := range lDomainRecords.Records {
+            lExisting = append(lExisting, NewDnsRecordFromList(lDomainRecords.Domain, element))
+        }
+        lDomainChanges, lDomainKept := DnsPlanUpsert(lExisting, lDesired, lDomainRecords.Domain)
+        lChanges = append(lChanges, lDomainChanges...)
+        lKept = append(lKept, lDomainKept...)
+    }
+    return lChanges, lKept, nil
+}
@@ -0,0 +1,56 @@
+package a24apiclient
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestDnsPlanUpsertKeepsDesiredValues(t *testing.T) {
+    lExisting := []map[string]string{
+        { "Domain": "example.com", "HashId": "h1", "Type": "MX", "Name": "@", "Ttl": "300", "Priority": "10", "Mailserver": "mail.example.net." },
+        { "Domain": "example.com", "HashId": "h2", "Type": "CNAME", "Name": "autodiscover", "Ttl": "3600", "Alias": "autodiscover.example.net." },
+        { "Domain": "example.com", "HashId": "h3", "Type": "MX", "Name": "@", "Ttl": "300", "Priority": "20", "Mailserver": "old.example.org." },
+    }
+    lDesired := []map[string]string{
+        { "Domain": "example.com", "Type": "MX", "Name": "example.com.", "Ttl": "3600", "Priority": "10", "Mailserver": "Mail.Example.NET" },
+        { "Domain": "example.com", "Type": "CNAME", "Name": "autodiscover", "Ttl": "3600", "Alias": "AutoDiscover.Example.NET" },
+        { "Domain": "example.com", "Type": "TXT", "Name": "@", "Ttl": "3600", "Text": "v=spf1 include:Spf.Example.NET -all" },
+    }
+    lChanges, lKept := DnsPlanUpsert(lExisting, lDesired, "example.com")
+    if len(lChanges) != 2 || len(lKept) != 1 || lKept[0]["HashId"] != "h3" {
+        t.Fatalf("DnsPlanUpsert changes %+v, kept %v", lChanges, lKept)
+    }
+    // normalised equal CNAME is left alone, changes carry desired values as given
+    if lChanges[0].Action != "update" || lChanges[0].Record["Mailserver"] != "Mail.Example.NET" || lChanges[0].Record["Name"] != "@" || lChanges[0].Record["HashId"] != "h1" || lChanges[0].Previous["Mailserver"] != "mail.example.net." {
+        t.Errorf("update change %+v", lChanges[0])
+    }
+    if lChanges[1].Action != "create" || lChanges[1].Record["Text"] != "v=spf1 include:Spf.Example.NET -all" {
+        t.Errorf("create change %+v", lChanges[1])
+    }
+}
+
+func TestDnsPlanPresetMulti(t *testing.T) {
+    s, lZones := newDnsTestServer("example.com", "example.org")
+    defer s.Close()
+    lZones["example.org"] = append(lZones["example.org"], map[string]interface{}{ "hashId": "h0", "type": "MX", "name": "@", "ttl": 3600.0, "priority": 1.0, "mailserver": "smtp.google.com" })
+    c := NewA24ApiClient(map[string]string{ "endpoint": s.URL })
+    lPreset, err := LoadDnsPreset("google-workspace")
+    if err != nil {
+        t.Fatalf("LoadDnsPreset failed: %v", err)
+    }
+    lChanges, _, err := c.DnsPlanPresetMulti([]string{ "example.com", "example.org" }, lPreset, nil, 2)
+    if err != nil {
+        t.Fatalf("DnsPlanPresetMulti failed: %v", err)
+    }
+    var lPlanned []string
+    for _, lChange := range lChanges {
+        lPlanned = append(lPlanned, lChange.Action + " " + lChange.Record["Domain"] + " " + lChange.Record["Type"])
+    }
+    lWant := []string{ "create example.com MX", "create example.com TXT", "create example.org TXT" }
+    if strings.Join(lPlanned, ",") != strings.Join(lWant, ",") {
+        t.Errorf("planned %q, want %q", lPlanned, lWant)
+    }
+    if _, _, err := c.DnsPlanPresetMulti([]string{ "example.com", "example.net" }, lPreset, nil, 2); err == nil {
+        t.Errorf("DnsPlanPresetMulti of unknown domain did not fail")
+    }
+}
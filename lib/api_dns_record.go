@@ -0,0 +1,246 @@
+package a24apiclient
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+
+// T_DnsRecordField describes one value field of a record type. Key is the record map key (Go style, e.g. "Ip"),
+// ApiKey is the json key used by the api (e.g. "ip"). Identity fields identify a record within its rrset.
+type T_DnsRecordField struct {
+    Key             string
+    ApiKey          string
+    Numeric         bool
+    Identity        bool
+}
+
+// value fields per record type in command-line order (after name and ttl)
+var C_A24ApiClient_DnsRecordFields = map[string][]T_DnsRecordField {
+    "A": []T_DnsRecordField {
+        { Key: "Ip", ApiKey: "ip", Identity: true },
+    },
+    "AAAA": []T_DnsRecordField {
+        { Key: "Ip", ApiKey: "ip", Identity: true },
+    },
+    "CNAME": []T_DnsRecordField {
+        { Key: "Alias", ApiKey: "alias", Identity: true },
+    },
+    "TXT": []T_DnsRecordField {
+        { Key: "Text", ApiKey: "text", Identity: true },
+    },
+    "NS": []T_DnsRecordField {
+        { Key: "NameServer", ApiKey: "nameServer", Identity: true },
+    },
+    "SSHFP": []T_DnsRecordField {
+        { Key: "Algorithm", ApiKey: "algorithm", Numeric: true, Identity: true },
+        { Key: "FingerprintType", ApiKey: "fingerprintType", Numeric: true, Identity: true },
+        { Key: "Text", ApiKey: "text" },
+    },
+    "SRV": []T_DnsRecordField {
+        { Key: "Priority", ApiKey: "priority", Numeric: true },
+        { Key: "Weight", ApiKey: "weight", Numeric: true },
+        { Key: "Port", ApiKey: "port", Numeric: true, Identity: true },
+        { Key: "Target", ApiKey: "target", Identity: true },
+    },
+    "TLSA": []T_DnsRecordField {
+        { Key: "CertificateUsage", ApiKey: "certificateUsage", Numeric: true, Identity: true },
+        { Key: "Selector", ApiKey: "selector", Numeric: true, Identity: true },
+        { Key: "MatchingType", ApiKey: "matchingType", Numeric: true, Identity: true },
+        { Key: "Hash", ApiKey: "hash", Identity: true },
+    },
+    "CAA": []T_DnsRecordField {
+        { Key: "Flags", ApiKey: "flags", Numeric: true },
+        { Key: "Tag", ApiKey: "tag", Identity: true },
+        { Key: "CaaValue", ApiKey: "caaValue", Identity: true },
+    },
+    "MX": []T_DnsRecordField {
+        { Key: "Priority", ApiKey: "priority", Numeric: true },
+        { Key: "Mailserver", ApiKey: "mailserver", Identity: true },
+    },
+}
+
+// record types which allow only one record per name
+var C_A24ApiClient_DnsSingleValueTypes = map[string]bool {
+    "CNAME": true,
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Constructors
+// --------------------------------------------------------------------------------------------------------------------
+
+// NewDnsRecord builds record map from command-line arguments: <type> <name> <ttl> <value...>
+func NewDnsRecord(domain string, args []string) (map[string]string, error) {
+    if len(args) < 1 {
+        return nil, NewA24ApiClientError("Error: Record type not provided.")
+    }
+    lType := strings.ToUpper(args[0])
+    lFields, isPresent := C_A24ApiClient_DnsRecordFields[lType]
+    if !isPresent {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Unsupported dns type %s.", args[0]))
+    }
+    if len(args) != 3 + len(lFields) {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Record type %s expects %d arguments, %d given.", lType, 2 + len(lFields), len(args) - 1))
+    }
+    r := make(map[string]string)
+    r["Domain"] = domain
+    r["Type"] = lType
+    r["Name"] = args[1]
+    r["Ttl"] = args[2]
+    if _, err := strconv.ParseFloat(r["Ttl"], 64); err != nil {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Invalid ttl %s.", r["Ttl"]))
+    }
+    for index, lField := range lFields {
+        r[lField.Key] = args[3 + index]
+    }
+    r, err := DnsRecordToAscii(r)
+    if err != nil {
+        return nil, err
+    }
+    return NormaliseDnsRecordName(r)
+}
+
+// NewDnsRecordFromList converts one element of T_DnsRecordList into record map
+func NewDnsRecordFromList(domain string, element map[string]interface{}) map[string]string {
+    r := make(map[string]string)
+    r["Domain"] = domain
+    r["HashId"] = dnsRecordListValue(element["hashId"])
+    r["Type"] = dnsRecordListValue(element["type"])
+    r["Name"] = dnsRecordListValue(element["name"])
+    r["Ttl"] = dnsRecordListValue(element["ttl"])
+    for _, lField := range C_A24ApiClient_DnsRecordFields[r["Type"]] {
+        r[lField.Key] = dnsRecordListValue(element[lField.ApiKey])
+    }
+    return r
+}
+
+func dnsRecordListValue(value interface{}) string {
+    switch t := value.(type) {
+        case nil:
+            return ""
+        case string:
+            return t
+        case float64:
+            return strconv.FormatFloat(t, 'f', -1, 64)
+        default:
+            return fmt.Sprintf("%v", t)
+    }
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------------------------------------------------------
+
+// DnsRecordValue returns value fields of record joined by space
+func DnsRecordValue(record map[string]string) string {
+    var lValues []string
+    for _, lField := range C_A24ApiClient_DnsRecordFields[record["Type"]] {
+        lValues = append(lValues, record[lField.Key])
+    }
+    return strings.Join(lValues, " ")
+}
+
+// DnsRecordSameIdentity reports whether both records are the same member of one rrset
+func DnsRecordSameIdentity(a, b map[string]string) bool {
+    if a["Type"] != b["Type"] || !DnsNameEqual(a["Name"], b["Name"]) {
+        return false
+    }
+    if C_A24ApiClient_DnsSingleValueTypes[a["Type"]] {
+        return true
+    }
+    for _, lField := range C_A24ApiClient_DnsRecordFields[a["Type"]] {
+        if lField.Identity && !dnsRecordFieldEqual(lField.Numeric, a[lField.Key], b[lField.Key]) {
+            return false
+        }
+    }
+    return true
+}
+
+// DnsRecordEqual reports whether both records carry the same name, ttl and values
+func DnsRecordEqual(a, b map[string]string) bool {
+    if a["Type"] != b["Type"] || !DnsNameEqual(a["Name"], b["Name"]) || !dnsRecordFieldEqual(true, a["Ttl"], b["Ttl"]) {
+        return false
+    }
+    for _, lField := range C_A24ApiClient_DnsRecordFields[a["Type"]] {
+        if !dnsRecordFieldEqual(lField.Numeric, a[lField.Key], b[lField.Key]) {
+            return false
+        }
+    }
+    return true
+}
+
+func dnsRecordFieldEqual(numeric bool, a, b string) bool {
+    if numeric {
+        lA, errA := strconv.ParseFloat(a, 64)
+        lB, errB := strconv.ParseFloat(b, 64)
+        if errA == nil && errB == nil {
+            return lA == lB
+        }
+    }
+    return a == b
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Create/update dns record of any supported type
+// --------------------------------------------------------------------------------------------------------------------
+
+func (c *T_A24ApiClient) DnsCreateUpdate(record map[string]string, action string) (int, []byte, error) {
+
+    lFields, isPresent := C_A24ApiClient_DnsRecordFields[record["Type"]]
+    if !isPresent {
+        return 0, nil, NewA24ApiClientError(fmt.Sprintf("Error: Unsupported dns type %s.", record["Type"]))
+    }
+    // unicode names are sent in ascii form, name relative to domain
+    lRecord, err := DnsRecordToAscii(record)
+    if err != nil {
+        return 0, nil, err
+    }
+    if lRecord, err = NormaliseDnsRecordName(lRecord); err != nil {
+        return 0, nil, err
+    }
+    if err := ValidateDnsRecord(lRecord, action); err != nil {
+        return 0, nil, err
+    }
+
+    var lApiData map[string]string
+    lApiData = make(map[string]string)
+    var lMethod string
+
+    lApiData["name"] = lRecord["Name"]
+    lApiData["ttl"] = lRecord["Ttl"]
+    for _, lField := range lFields {
+        lApiData[lField.ApiKey] = lRecord[lField.Key]
+    }
+    if lRecord["Type"] == "TXT" {
+        lApiData["text"] = DnsTxtEncode(lRecord["Text"])
+    }
+
+    if action == "update" {
+        lMethod = "PUT"
+        lApiData["hashId"] = lRecord["HashId"]
+    } else {
+        lMethod = "POST"
+    }
+
+    lUrl, err := c.dnsUrl(lRecord["Domain"], strings.ToLower(lRecord["Type"]), "v1")
+    if err != nil {
+        return 0, nil, err
+    }
+    rc, rb, err :=  c.doApiRequest(lMethod, lUrl, lApiData);
+    if err != nil {
+        return rc, nil, err
+    }
+    return rc, rb, nil
+}
+
+func (c *T_A24ApiClient) DnsCreate(record map[string]string) (int, []byte, error) {
+    return c.DnsCreateUpdate(record, "create")
+}
+
+func (c *T_A24ApiClient) DnsUpdate(record map[string]string) (int, []byte, error) {
+    return c.DnsCreateUpdate(record, "update")
+}
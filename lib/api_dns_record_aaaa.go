@@ -10,7 +10,7 @@ import (
 // --------------------------------------------------------------------------------------------------------------------
 
 type T_DnsRecordAAAA struct {
-    Domain          string
+    Domain          string    `json:"domain"`
     HashId          string    `json:"hashId"`
     Type            string    `json:"type"`
     Ip              string    `json:"ip"`
@@ -69,7 +69,11 @@ func (c *T_A24ApiClient) DnsCreateUpdateAAAA(record interface{}, action string)
         lMethod = "POST"
     }
 
-    rc, rb, err :=  c.doApiRequest(lMethod, c.Config["endpoint"] + "/dns/" + lDomain + "/a/v1", lApiData);
+    lUrl, err := c.dnsUrl(lDomain, "a", "v1")
+    if err != nil {
+        return 0, nil, err
+    }
+    rc, rb, err :=  c.doApiRequest(lMethod, lUrl, lApiData);
     if err != nil {
         return rc, nil, err
     }
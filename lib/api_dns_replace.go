@@ -0,0 +1,83 @@
+package a24apiclient
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "net"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Plan replace
+// --------------------------------------------------------------------------------------------------------------------
+
+// NewDnsReplaceQuery builds search query for replace source, ip address or cidr selects A/AAAA records in range,
+// anything else selects CNAME/MX/NS/SRV records with exactly this target
+func NewDnsReplaceQuery(from string) (*T_DnsSearchQuery, error) {
+    if _, _, err := net.ParseCIDR(from); err == nil || net.ParseIP(from) != nil {
+        return NewDnsSearchQuery(from, "cidr", "")
+    }
+    return NewDnsSearchQuery(from, "exact", "")
+}
+
+// DnsPlanReplace returns update changes setting ip or target of records to value. Records whose value already
+// equals are skipped, A/AAAA records are rejected when value is not an address of the same family.
+func DnsPlanReplace(records []map[string]string, value string) ([]T_DnsChange, error) {
+    var lChanges []T_DnsChange
+    for _, lRecord := range records {
+        var lField string
+        switch lRecord["Type"] {
+            case "A", "AAAA":
+                lIp := net.ParseIP(value)
+                if lIp == nil || (lRecord["Type"] == "A") != (lIp.To4() != nil) {
+                    return nil, NewA24ApiClientError(fmt.Sprintf("Error: %s is not a valid %s address.", value, lRecord["Type"]))
+                }
+                lField = "Ip"
+            default:
+                lTargetField, isPresent := C_A24ApiClient_DnsTargetFields[lRecord["Type"]]
+                if !isPresent {
+                    return nil, NewA24ApiClientError(fmt.Sprintf("Error: Record type %s can not be replaced.", lRecord["Type"]))
+                }
+                lField = lTargetField
+        }
+        if lRecord[lField] == value {
+            continue
+        }
+        lDesired := make(map[string]string)
+        for key, element := range lRecord {
+            lDesired[key] = element
+        }
+        lDesired[lField] = value
+        lChanges = append(lChanges, T_DnsChange{ Action: "update", Record: lDesired, Previous: lRecord })
+    }
+    return lChanges, nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Rollback file
+// --------------------------------------------------------------------------------------------------------------------
+
+// WriteDnsChanges writes changes as indented json array of {action, record, previous, code}, e.g. rollback file
+func WriteDnsChanges(w io.Writer, changes []T_DnsChange) error {
+    lData, err := json.MarshalIndent(changes, "", "    ")
+    if err != nil {
+        return err
+    }
+    _, err = w.Write(append(lData, '\n'))
+    return err
+}
+
+// ReadDnsChanges reads json array of changes written by WriteDnsChanges
+func ReadDnsChanges(r io.Reader) ([]T_DnsChange, error) {
+    var lChanges []T_DnsChange
+    if err := json.NewDecoder(r).Decode(&lChanges); err != nil {
+        return nil, err
+    }
+    return lChanges, nil
+}
+
+// DnsRevertChanges reverts changes (e.g. loaded from rollback file) in reverse order and returns executed reverting
+// changes, the last one failed when error is returned
+func (c *T_A24ApiClient) DnsRevertChanges(changes []T_DnsChange) ([]T_DnsChange, error) {
+    return c.dnsRollbackChanges(changes)
+}
@@ -0,0 +1,261 @@
+package a24apiclient
+
+import (
+    "context"
+    "fmt"
+    "net"
+    "sort"
+    "strings"
+    "time"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+//
+// T_DnsResolver looks up records by name in zone data of the account: the longest account domain containing name is
+// the zone, its records are listed once and the answer is authoritative (no records means no data). Names outside
+// account domains are looked up by optional local resolver. CNAME records found in zone data are followed, targets
+// (alias, mailserver) are absolute names.
+
+type T_DnsResolver struct {
+    Client          *T_A24ApiClient
+    Resolver        *net.Resolver
+
+    domains         []string
+    zones           map[string][]map[string]string
+}
+
+// T_DnsResolverError is returned when name can not be looked up (outside account domains and no resolver)
+type T_DnsResolverError struct {
+    Name            string
+}
+
+func (e *T_DnsResolverError) Error() string {
+    return fmt.Sprintf("Error: %s is not in account domains and no resolver is set.", e.Name)
+}
+
+const (
+    C_DnsResolver_System = "system"
+    C_DnsResolver_MaxCname = 8
+    C_DnsResolver_Timeout = 10 * time.Second
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Constructor
+// --------------------------------------------------------------------------------------------------------------------
+
+// NewDnsResolver returns resolver over zone data of client, resolver may be nil
+func (c *T_A24ApiClient) NewDnsResolver(resolver *net.Resolver) *T_DnsResolver {
+    return &T_DnsResolver{ Client: c, Resolver: resolver, zones: make(map[string][]map[string]string) }
+}
+
+// NewNetResolver returns local resolver: empty address means none, system the system resolver, anything else
+// a name server address (host or host:port)
+func NewNetResolver(address string) *net.Resolver {
+    switch address {
+        case "":
+            return nil
+        case C_DnsResolver_System:
+            return net.DefaultResolver
+    }
+    if _, _, err := net.SplitHostPort(address); err != nil {
+        address = net.JoinHostPort(strings.Trim(address, "[]"), "53")
+    }
+    return &net.Resolver{
+        PreferGo: true,
+        Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+            return (&net.Dialer{ Timeout: C_DnsResolver_Timeout }).DialContext(ctx, network, address)
+        },
+    }
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Lookup
+// --------------------------------------------------------------------------------------------------------------------
+
+// Zone returns account domain containing name (longest match) or empty string
+func (r *T_DnsResolver) Zone(name string) (string, error) {
+    if r.domains == nil {
+        rc, lDomains, err := r.Client.DnsSelectDomains("")
+        if err != nil {
+            return "", err
+        }
+        if err := r.Client.dnsResponseError(rc, "list"); err != nil {
+            return "", err
+        }
+        r.domains = lDomains
+        if r.domains == nil {
+            r.domains = []string{}
+        }
+    }
+    lName := dnsCanonicalHost(name)
+    lZone := ""
+    for _, lDomain := range r.domains {
+        lDomain = dnsCanonicalHost(lDomain)
+        if (lName == lDomain || strings.HasSuffix(lName, "." + lDomain)) && len(lDomain) > len(lZone) {
+            lZone = lDomain
+        }
+    }
+    return lZone, nil
+}
+
+// ZoneRecords returns records of account domain, records are listed once
+func (r *T_DnsResolver) ZoneRecords(zone string) ([]map[string]string, error) {
+    if lRecords, isPresent := r.zones[zone]; isPresent {
+        return lRecords, nil
+    }
+    rc, lList, err := r.Client.DnsListRecords(map[string]string{ "0": zone })
+    if err != nil {
+        return nil, err
+    }
+    if err := r.Client.dnsResponseError(rc, "list"); err != nil {
+        return nil, err
+    }
+    var lRecords []map[string]string
+    for _, element := range lList {
+        lRecords = append(lRecords, NewDnsRecordFromList(zone, element))
+    }
+    r.zones[zone] = lRecords
+    return lRecords, nil
+}
+
+// Lookup returns records of type at name (absolute, trailing dot optional), CNAME is followed within zone data
+func (r *T_DnsResolver) Lookup(name, rtype string) ([]map[string]string, error) {
+    lName := dnsCanonicalHost(name)
+    for index := 0; index <= C_DnsResolver_MaxCname; index++ {
+        lZone, err := r.Zone(lName)
+        if err != nil {
+            return nil, err
+        }
+        if lZone == "" {
+            return r.lookupNet(lName, rtype)
+        }
+        lRecords, err := r.ZoneRecords(lZone)
+        if err != nil {
+            return nil, err
+        }
+        lRelative := DnsNameRelative(lName, lZone)
+        var lFound []map[string]string
+        lAlias := ""
+        for _, lRecord := range lRecords {
+            if !DnsNameEqual(lRecord["Name"], lRelative) {
+                continue
+            }
+            if lRecord["Type"] == rtype {
+                lFound = append(lFound, lRecord)
+            } else if lRecord["Type"] == "CNAME" {
+                lAlias = lRecord["Alias"]
+            }
+        }
+        if lFound != nil || lAlias == "" || rtype == "CNAME" {
+            return lFound, nil
+        }
+        lName = dnsCanonicalHost(lAlias)
+    }
+    return nil, NewA24ApiClientError(fmt.Sprintf("Error: Too many CNAME records following %s.", name))
+}
+
+// LookupTxt returns TXT values at name
+func (r *T_DnsResolver) LookupTxt(name string) ([]string, error) {
+    lRecords, err := r.Lookup(name, "TXT")
+    if err != nil {
+        return nil, err
+    }
+    var lTexts []string
+    for _, lRecord := range lRecords {
+        lTexts = append(lTexts, lRecord["Text"])
+    }
+    return lTexts, nil
+}
+
+// LookupAddresses returns A and AAAA addresses of name
+func (r *T_DnsResolver) LookupAddresses(name string) ([]string, error) {
+    var lAddresses []string
+    for _, lType := range []string{ "A", "AAAA" } {
+        lRecords, err := r.Lookup(name, lType)
+        if err != nil {
+            return nil, err
+        }
+        for _, lRecord := range lRecords {
+            lAddresses = append(lAddresses, lRecord["Ip"])
+        }
+    }
+    return lAddresses, nil
+}
+
+// LookupMx returns mail servers of name ordered by priority, as absolute names without trailing dot
+func (r *T_DnsResolver) LookupMx(name string) ([]string, error) {
+    lRecords, err := r.Lookup(name, "MX")
+    if err != nil {
+        return nil, err
+    }
+    sort.SliceStable(lRecords, func(i, j int) bool {
+        return dnsRecordListNumber(lRecords[i]["Priority"]) < dnsRecordListNumber(lRecords[j]["Priority"])
+    })
+    var lHosts []string
+    for _, lRecord := range lRecords {
+        // null MX (RFC 7505)
+        if lRecord["Mailserver"] == "." {
+            continue
+        }
+        lHosts = append(lHosts, dnsCanonicalHost(lRecord["Mailserver"]))
+    }
+    return lHosts, nil
+}
+
+// lookupNet looks up name by local resolver, not found is returned as no records
+func (r *T_DnsResolver) lookupNet(name, rtype string) ([]map[string]string, error) {
+    if r.Resolver == nil {
+        return nil, &T_DnsResolverError{ Name: name }
+    }
+    lContext, lCancel := context.WithTimeout(context.Background(), C_DnsResolver_Timeout)
+    defer lCancel()
+    var lRecords []map[string]string
+    var err error
+    switch rtype {
+        case "TXT":
+            var lTexts []string
+            if lTexts, err = r.Resolver.LookupTXT(lContext, name); err == nil {
+                for _, lText := range lTexts {
+                    lRecords = append(lRecords, map[string]string{ "Type": rtype, "Name": name, "Text": lText })
+                }
+            }
+        case "A", "AAAA":
+            lNetwork := "ip4"
+            if rtype == "AAAA" {
+                lNetwork = "ip6"
+            }
+            var lIps []net.IP
+            if lIps, err = r.Resolver.LookupIP(lContext, lNetwork, name); err == nil {
+                for _, lIp := range lIps {
+                    lRecords = append(lRecords, map[string]string{ "Type": rtype, "Name": name, "Ip": lIp.String() })
+                }
+            }
+        case "MX":
+            var lMxs []*net.MX
+            if lMxs, err = r.Resolver.LookupMX(lContext, name); err == nil {
+                for _, lMx := range lMxs {
+                    lRecords = append(lRecords, map[string]string{ "Type": rtype, "Name": name, "Priority": fmt.Sprintf("%d", lMx.Pref), "Mailserver": lMx.Host })
+                }
+            }
+        case "CNAME":
+            // canonical name equal to name means there is no alias
+            var lCanonical string
+            if lCanonical, err = r.Resolver.LookupCNAME(lContext, name); err == nil && dnsCanonicalHost(lCanonical) != dnsCanonicalHost(name) {
+                lRecords = append(lRecords, map[string]string{ "Type": rtype, "Name": name, "Alias": lCanonical })
+            }
+        default:
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Lookup of %s records is not supported by resolver.", rtype))
+    }
+    if lDnsError, isDnsError := err.(*net.DNSError); isDnsError && lDnsError.IsNotFound {
+        return nil, nil
+    }
+    return lRecords, err
+}
+
+func dnsRecordListNumber(value string) float64 {
+    var lNumber float64
+    fmt.Sscan(value, &lNumber)
+    return lNumber
+}
@@ -0,0 +1,175 @@
+package a24apiclient
+
+import (
+    "encoding/json"
+    "strconv"
+    "time"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+//
+// T_DnsResult is a stable machine-readable result of one operation. Every command reports a list of results:
+// mutating commands one per record operation, listing and other commands one per domain or command with payload
+// in data. Record uses api keys and types (ttl and numeric fields are numbers).
+
+type T_DnsResult struct {
+    Operation       string                  `json:"operation"`
+    Action          string                  `json:"action,omitempty"`
+    Line            int                     `json:"line,omitempty"`
+    Domain          string                  `json:"domain,omitempty"`
+    Record          map[string]interface{}  `json:"record,omitempty"`
+    HashId          string                  `json:"hashId,omitempty"`
+    Status          string                  `json:"status"`
+    Code            int                     `json:"code"`
+    CodeText        string                  `json:"codeText,omitempty"`
+    Data            interface{}             `json:"data,omitempty"`
+    StartedAt       time.Time               `json:"startedAt"`
+    DurationMs      float64                 `json:"durationMs"`
+    Error           *T_DnsResultError       `json:"error,omitempty"`
+}
+
+// T_DnsResultError holds error message and api response body when it is json or invalid fields of record
+type T_DnsResultError struct {
+    Message         string                  `json:"message"`
+    Details         json.RawMessage         `json:"details,omitempty"`
+}
+
+// T_DnsResultDocument wraps results of one command
+type T_DnsResultDocument struct {
+    Schema          string                  `json:"schema"`
+    Results         []T_DnsResult           `json:"results"`
+}
+
+const (
+    C_DnsResult_Schema = "a24api.dns.result/v1"
+    C_DnsResult_Ok = "ok"
+    C_DnsResult_Error = "error"
+    C_DnsResult_Skipped = "skipped"
+    C_DnsResult_RolledBack = "rolled_back"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Build results
+// --------------------------------------------------------------------------------------------------------------------
+
+func NewDnsResult(operation, domain string, record map[string]string, started time.Time) T_DnsResult {
+    r := T_DnsResult{ Operation: operation, Domain: domain, StartedAt: started.UTC() }
+    if record != nil {
+        r.Record = DnsRecordApi(record)
+        r.HashId = record["HashId"]
+        if r.Domain == "" {
+            r.Domain = record["Domain"]
+        }
+    }
+    return r
+}
+
+// Finish sets code, status, duration and error. Status is ok for 200/204 without error, skipped when no request
+// was made and no error is given. Body of failed request is attached as error details when it is json.
+func (r *T_DnsResult) Finish(code int, codeText string, body []byte, duration time.Duration, err error) {
+    r.Code = code
+    if code != 0 {
+        r.CodeText = codeText
+    }
+    r.DurationMs = float64(duration.Microseconds()) / 1000
+    switch {
+        case err == nil && (code == 200 || code == 204):
+            r.Status = C_DnsResult_Ok
+            return
+        case err == nil && code == 0:
+            r.Status = C_DnsResult_Skipped
+            return
+    }
+    r.Status = C_DnsResult_Error
+    r.Error = &T_DnsResultError{}
+    if err != nil {
+        r.Error.Message = err.Error()
+    } else {
+        r.Error.Message = strconv.Itoa(code) + " " + codeText
+    }
+    if len(body) > 0 && json.Valid(body) {
+        r.Error.Details = json.RawMessage(body)
+    }
+    // invalid fields of pre-flight validation
+    if lValidation, isValidation := err.(*T_DnsValidationError); isValidation {
+        r.Error.Details, _ = json.Marshal(lValidation)
+    }
+}
+
+// NewDnsResultFromBatch converts batch result, line of input file is kept
+func NewDnsResultFromBatch(result T_DnsBatchResult) T_DnsResult {
+    r := NewDnsResult(result.Operation, "", result.Record, result.StartedAt)
+    r.Line = result.Line
+    r.Action = result.Action
+    var err error
+    if result.Error != "" {
+        err = NewA24ApiClientError(result.Error)
+    }
+    r.Finish(result.Code, result.CodeText, nil, result.Duration, err)
+    if result.Status == C_DnsBatch_Skipped {
+        r.Status = C_DnsResult_Skipped
+        r.Error = nil
+    }
+    return r
+}
+
+// NewDnsResultFromDomainRecords converts records of one domain listed by DnsListRecordsMulti, records are in data
+func NewDnsResultFromDomainRecords(operation string, result T_DnsDomainRecords, codeText string) T_DnsResult {
+    r := NewDnsResult(operation, result.Domain, nil, result.StartedAt)
+    if result.Err == nil {
+        r.Data = result.Records
+    }
+    r.Finish(result.Code, codeText, nil, result.Duration, result.Err)
+    return r
+}
+
+// NewDnsResultFromChange converts applied change, err is error of the failed change (Code is set for every
+// attempted change, the rest is skipped); change reverted after a later failure is rolled_back
+func NewDnsResultFromChange(operation string, change T_DnsChange, codeText string, err error) T_DnsResult {
+    r := NewDnsResult(operation, "", change.Record, change.StartedAt)
+    r.Action = change.Action
+    r.Finish(change.Code, codeText, nil, change.Duration, err)
+    if change.RolledBack {
+        r.Status = C_DnsResult_RolledBack
+    }
+    return r
+}
+
+// DnsRecordApi converts record map into api format with numeric ttl and value fields
+func DnsRecordApi(record map[string]string) map[string]interface{} {
+    lFields := append([]T_DnsRecordField{
+        { Key: "HashId", ApiKey: "hashId" },
+        { Key: "Type", ApiKey: "type" },
+        { Key: "Name", ApiKey: "name" },
+        { Key: "Ttl", ApiKey: "ttl", Numeric: true },
+    }, C_A24ApiClient_DnsRecordFields[record["Type"]]...)
+    r := make(map[string]interface{})
+    for _, lField := range lFields {
+        lValue, isPresent := record[lField.Key]
+        if !isPresent || lValue == "" {
+            continue
+        }
+        if lField.Numeric {
+            if lNumber, err := strconv.ParseFloat(lValue, 64); err == nil {
+                r[lField.ApiKey] = lNumber
+                continue
+            }
+        }
+        r[lField.ApiKey] = lValue
+    }
+    return r
+}
+
+// DnsResolveHashId returns hashId of existing record with equal content, api does not return it on create
+func (c *T_A24ApiClient) DnsResolveHashId(record map[string]string) (string, error) {
+    lRecord, err := DnsRecordToAscii(record)
+    if err != nil {
+        return "", err
+    }
+    if lRecord, err = NormaliseDnsRecordName(lRecord); err != nil {
+        return "", err
+    }
+    return c.dnsFindHashId(lRecord)
+}
@@ -0,0 +1,29 @@
+package a24apiclient
+
+import (
+    "testing"
+)
+
+func TestNewDnsResultFromChange(t *testing.T) {
+    lRecord := map[string]string{ "Domain": "example.com", "Type": "A", "Name": "www", "Ttl": "300", "Ip": "192.0.2.1" }
+    lTests := []struct {
+        name            string
+        change          T_DnsChange
+        err             error
+        status          string
+    }{
+        { "applied", T_DnsChange{ Action: "create", Record: lRecord, Code: 204 }, nil, C_DnsResult_Ok },
+        { "rolled back", T_DnsChange{ Action: "create", Record: lRecord, Code: 204, RolledBack: true }, nil, C_DnsResult_RolledBack },
+        { "failed", T_DnsChange{ Action: "create", Record: lRecord, Code: 400 }, NewA24ApiClientError("Error: create failed."), C_DnsResult_Error },
+        { "not attempted", T_DnsChange{ Action: "delete", Record: lRecord }, nil, C_DnsResult_Skipped },
+    }
+    for _, lTest := range lTests {
+        r := NewDnsResultFromChange("rrset", lTest.change, "", lTest.err)
+        if r.Status != lTest.status {
+            t.Errorf("%s: status %q, want %q", lTest.name, r.Status, lTest.status)
+        }
+        if (r.Error != nil) != (lTest.status == C_DnsResult_Error) {
+            t.Errorf("%s: error %+v", lTest.name, r.Error)
+        }
+    }
+}
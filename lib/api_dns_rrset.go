@@ -0,0 +1,318 @@
+package a24apiclient
+
+import (
+    "fmt"
+    "strings"
+    "time"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+
+// T_DnsChange is one api call of a plan. Record is the record to create, the new state for update or the record
+// to delete; Previous is the state before update (used for rollback). RolledBack is set when the change was applied
+// and then reverted because a later change failed.
+type T_DnsChange struct {
+    Action          string                `json:"action"`
+    Record          map[string]string     `json:"record"`
+    Previous        map[string]string     `json:"previous,omitempty"`
+    Code            int                   `json:"code"`
+    RolledBack      bool                  `json:"rolledBack,omitempty"`
+    StartedAt       time.Time             `json:"-"`
+    Duration        time.Duration         `json:"-"`
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Get rrset
+// --------------------------------------------------------------------------------------------------------------------
+
+func (c *T_A24ApiClient) DnsGetRRset(domain, rtype, name string) (int, []map[string]string, error) {
+    // records are listed in ascii form with canonical names
+    var err error
+    if domain, err = DnsIdnToAscii(domain); err != nil {
+        return 0, nil, err
+    }
+    if name, err = DnsIdnToAscii(name); err != nil {
+        return 0, nil, err
+    }
+    if name, err = NormaliseDnsName(name, domain); err != nil {
+        return 0, nil, err
+    }
+    rc, lRecords, err := c.DnsListRecords(map[string]string{ "0": domain })
+    if err != nil {
+        return rc, nil, err
+    }
+    if err := c.dnsResponseError(rc, "list"); err != nil {
+        return rc, nil, err
+    }
+    var lRRset []map[string]string
+    for _, element := range lRecords {
+        lRecord := NewDnsRecordFromList(domain, element)
+        if lRecord["Type"] == rtype && DnsNameEqual(lRecord["Name"], name) {
+            lRRset = append(lRRset, lRecord)
+        }
+    }
+    return rc, lRRset, nil
+}
+
+// DnsZoneRecords returns ascii form of domain and all its records
+func (c *T_A24ApiClient) DnsZoneRecords(domain string) (string, []map[string]string, error) {
+    lDomain, err := DnsIdnToAscii(domain)
+    if err != nil {
+        return "", nil, err
+    }
+    rc, lList, err := c.DnsListRecords(map[string]string{ "0": lDomain })
+    if err != nil {
+        return "", nil, err
+    }
+    if err := c.dnsResponseError(rc, "list"); err != nil {
+        return "", nil, err
+    }
+    var lRecords []map[string]string
+    for _, element := range lList {
+        lRecords = append(lRecords, NewDnsRecordFromList(lDomain, element))
+    }
+    return lDomain, lRecords, nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Plan rrset changes
+// --------------------------------------------------------------------------------------------------------------------
+
+// DnsPlanRRset computes minimal list of changes turning existing rrset into desired one. Records with the same
+// identity are kept or updated, remaining desired records reuse remaining existing ones via update, the rest
+// is created or deleted.
+func DnsPlanRRset(existing, desired []map[string]string) []T_DnsChange {
+    return dnsPlanRRsetCompared(existing, desired, existing, desired)
+}
+
+// dnsPlanRRsetCompared plans rrset as DnsPlanRRset comparing compareExisting and compareDesired (e.g. normalised
+// records), changes carry records of existing and desired at the same positions
+func dnsPlanRRsetCompared(existing, desired, compareExisting, compareDesired []map[string]string) []T_DnsChange {
+    var lChanges []T_DnsChange
+    lUsed := make(map[int]bool)
+    var lUnmatched []int
+
+    for lDesired := range desired {
+        lMatched := false
+        for index := range existing {
+            if lUsed[index] || !DnsRecordSameIdentity(compareExisting[index], compareDesired[lDesired]) {
+                continue
+            }
+            lUsed[index] = true
+            lMatched = true
+            if !DnsRecordEqual(compareExisting[index], compareDesired[lDesired]) {
+                lChanges = append(lChanges, dnsUpdateChange(existing[index], desired[lDesired]))
+            }
+            break
+        }
+        if !lMatched {
+            lUnmatched = append(lUnmatched, lDesired)
+        }
+    }
+
+    for _, lDesired := range lUnmatched {
+        lMatched := false
+        for index := range existing {
+            if lUsed[index] || compareExisting[index]["Type"] != compareDesired[lDesired]["Type"] || !DnsNameEqual(compareExisting[index]["Name"], compareDesired[lDesired]["Name"]) {
+                continue
+            }
+            lUsed[index] = true
+            lMatched = true
+            lChanges = append(lChanges, dnsUpdateChange(existing[index], desired[lDesired]))
+            break
+        }
+        if !lMatched {
+            lChanges = append(lChanges, T_DnsChange{ Action: "create", Record: desired[lDesired] })
+        }
+    }
+
+    for index := range existing {
+        if !lUsed[index] {
+            lChanges = append(lChanges, T_DnsChange{ Action: "delete", Record: existing[index] })
+        }
+    }
+
+    return lChanges
+}
+
+func dnsUpdateChange(existing, desired map[string]string) T_DnsChange {
+    lRecord := make(map[string]string)
+    for key, value := range desired {
+        lRecord[key] = value
+    }
+    lRecord["HashId"] = existing["HashId"]
+    return T_DnsChange{ Action: "update", Record: lRecord, Previous: existing }
+}
+
+// Status returns result status of change: ok when applied, error when attempted and failed, rolled_back when
+// reverted after a later failure and skipped when not attempted
+func (c T_DnsChange) Status() string {
+    switch {
+        case c.RolledBack:
+            return C_DnsResult_RolledBack
+        case c.StartedAt.IsZero():
+            return C_DnsResult_Skipped
+        case c.Code == 200 || c.Code == 204:
+            return C_DnsResult_Ok
+    }
+    return C_DnsResult_Error
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Apply changes
+// --------------------------------------------------------------------------------------------------------------------
+
+// DnsApplyChanges applies changes in order. Created and updated records are validated before the first call.
+// When a change fails, already applied changes are reverted in reverse order and the original error is returned
+// together with the changes (Code is set for every attempted change, RolledBack for every reverted one).
+func (c *T_A24ApiClient) DnsApplyChanges(changes []T_DnsChange) ([]T_DnsChange, error) {
+    for _, lChange := range changes {
+        if lChange.Action == "create" || lChange.Action == "update" {
+            if err := ValidateDnsRecord(lChange.Record, lChange.Action); err != nil {
+                return changes, err
+            }
+        }
+    }
+    for index := range changes {
+        changes[index].StartedAt = time.Now()
+        rc, err := c.dnsApplyChange(changes[index])
+        changes[index].Duration = time.Since(changes[index].StartedAt)
+        changes[index].Code = rc
+        if err != nil {
+            if _, lErr := c.dnsRollbackChanges(changes[:index]); lErr != nil {
+                return changes, NewA24ApiClientError(fmt.Sprintf("%s Rollback failed: %s", err, lErr))
+            }
+            return changes, err
+        }
+    }
+    return changes, nil
+}
+
+func (c *T_A24ApiClient) dnsApplyChange(change T_DnsChange) (int, error) {
+    var rc int
+    var err error
+    switch change.Action {
+        case "create":
+            rc, _, err = c.DnsCreate(change.Record)
+        case "update":
+            rc, _, err = c.DnsUpdate(change.Record)
+        case "delete":
+            rc, _, err = c.DnsDelete(change.Record)
+        default:
+            return 0, NewA24ApiClientError(fmt.Sprintf("Error: Unknown action %s.", change.Action))
+    }
+    if err != nil {
+        return rc, err
+    }
+    return rc, c.dnsResponseError(rc, change.Action)
+}
+
+// dnsRollbackChanges reverts changes in reverse order, marks them RolledBack and returns executed reverting changes
+func (c *T_A24ApiClient) dnsRollbackChanges(changes []T_DnsChange) ([]T_DnsChange, error) {
+    var lReverts []T_DnsChange
+    for index := len(changes) - 1; index >= 0; index-- {
+        var lRevert T_DnsChange
+        switch changes[index].Action {
+            case "create":
+                // api does not return hashId of created record, look it up
+                lHashId, err := c.dnsFindHashId(changes[index].Record)
+                if err != nil {
+                    return lReverts, err
+                }
+                lRecord := make(map[string]string)
+                for key, value := range changes[index].Record {
+                    lRecord[key] = value
+                }
+                lRecord["HashId"] = lHashId
+                lRevert = T_DnsChange{ Action: "delete", Record: lRecord }
+            case "update":
+                lRevert = T_DnsChange{ Action: "update", Record: changes[index].Previous, Previous: changes[index].Record }
+            case "delete":
+                lRevert = T_DnsChange{ Action: "create", Record: changes[index].Record }
+        }
+        lRevert.StartedAt = time.Now()
+        rc, err := c.dnsApplyChange(lRevert)
+        lRevert.Duration = time.Since(lRevert.StartedAt)
+        lRevert.Code = rc
+        lReverts = append(lReverts, lRevert)
+        if err != nil {
+            return lReverts, err
+        }
+        changes[index].RolledBack = true
+    }
+    return lReverts, nil
+}
+
+func (c *T_A24ApiClient) dnsFindHashId(record map[string]string) (string, error) {
+    _, lRRset, err := c.DnsGetRRset(record["Domain"], record["Type"], record["Name"])
+    if err != nil {
+        return "", err
+    }
+    for _, lRecord := range lRRset {
+        if DnsRecordEqual(lRecord, record) {
+            return lRecord["HashId"], nil
+        }
+    }
+    return "", NewA24ApiClientError(fmt.Sprintf("Error: Record %s %s %s not found.", record["Type"], record["Name"], DnsRecordValue(record)))
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Replace/delete rrset
+// --------------------------------------------------------------------------------------------------------------------
+
+func (c *T_A24ApiClient) DnsReplaceRRset(domain, rtype, name string, desired []map[string]string) ([]T_DnsChange, error) {
+    var err error
+    if domain, err = DnsIdnToAscii(domain); err != nil {
+        return nil, err
+    }
+    if name, err = DnsIdnToAscii(name); err != nil {
+        return nil, err
+    }
+    if name, err = NormaliseDnsName(name, domain); err != nil {
+        return nil, err
+    }
+    for _, lDesired := range desired {
+        if lDesired["Domain"] != domain || lDesired["Type"] != rtype || !DnsNameEqual(lDesired["Name"], name) {
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Record %s %s does not belong to rrset %s %s.", lDesired["Type"], lDesired["Name"], rtype, name))
+        }
+    }
+    if C_A24ApiClient_DnsSingleValueTypes[rtype] && len(desired) > 1 {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Record type %s allows only one record per name.", rtype))
+    }
+    _, lExisting, err := c.DnsGetRRset(domain, rtype, name)
+    if err != nil {
+        return nil, err
+    }
+    return c.DnsApplyChanges(DnsPlanRRset(lExisting, desired))
+}
+
+func (c *T_A24ApiClient) DnsDeleteRRset(domain, rtype, name string) ([]T_DnsChange, error) {
+    return c.DnsReplaceRRset(domain, rtype, name, nil)
+}
+
+// NewDnsRRset builds rrset from command-line arguments: <type> <name> <ttl> <value...> [<value...>]
+func NewDnsRRset(domain string, args []string) ([]map[string]string, error) {
+    if len(args) < 3 {
+        return nil, NewA24ApiClientError("Error: Record type, name or ttl not provided.")
+    }
+    lType := strings.ToUpper(args[0])
+    lFields, isPresent := C_A24ApiClient_DnsRecordFields[lType]
+    if !isPresent {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Unsupported dns type %s.", args[0]))
+    }
+    lValues := args[3:]
+    if len(lValues) % len(lFields) != 0 {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Record type %s expects values in groups of %d.", lType, len(lFields)))
+    }
+    var lRRset []map[string]string
+    for index := 0; index < len(lValues); index += len(lFields) {
+        lRecord, err := NewDnsRecord(domain, append([]string{ lType, args[1], args[2] }, lValues[index:index + len(lFields)]...))
+        if err != nil {
+            return nil, err
+        }
+        lRRset = append(lRRset, lRecord)
+    }
+    return lRRset, nil
+}
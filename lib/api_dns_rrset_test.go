@@ -0,0 +1,134 @@
+package a24apiclient
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strconv"
+    "strings"
+    "sync"
+    "testing"
+)
+
+// newDnsTestServer returns fake dns api keeping records of domains in memory, records with ip 192.0.2.99 are
+// refused with 400
+func newDnsTestServer(domains ...string) (*httptest.Server, map[string][]map[string]interface{}) {
+    var lMutex sync.Mutex
+    lZones := make(map[string][]map[string]interface{})
+    for _, lDomain := range domains {
+        lZones[lDomain] = []map[string]interface{}{}
+    }
+    lNext := 0
+    s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        lMutex.Lock()
+        defer lMutex.Unlock()
+        // /dns/<domain>/<records|type|hashId>/v1
+        lPath := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+        if len(lPath) != 4 || lPath[0] != "dns" {
+            w.WriteHeader(404)
+            return
+        }
+        lRecords, isDomain := lZones[lPath[1]]
+        if !isDomain {
+            w.WriteHeader(400)
+            return
+        }
+        var lBody map[string]interface{}
+        json.NewDecoder(r.Body).Decode(&lBody)
+        if lTtl, isString := lBody["ttl"].(string); isString {
+            lBody["ttl"], _ = strconv.ParseFloat(lTtl, 64)
+        }
+        switch r.Method {
+            case "GET":
+                json.NewEncoder(w).Encode(lRecords)
+                return
+            case "POST":
+                if lBody["ip"] == "192.0.2.99" {
+                    w.WriteHeader(400)
+                    return
+                }
+                lNext++
+                lBody["hashId"] = fmt.Sprintf("h%d", lNext)
+                lBody["type"] = strings.ToUpper(lPath[2])
+                lZones[lPath[1]] = append(lRecords, lBody)
+                w.WriteHeader(204)
+                return
+            case "PUT":
+                for _, lRecord := range lRecords {
+                    if lRecord["hashId"] == lBody["hashId"] {
+                        for key, value := range lBody {
+                            lRecord[key] = value
+                        }
+                        w.WriteHeader(204)
+                        return
+                    }
+                }
+            case "DELETE":
+                for index, lRecord := range lRecords {
+                    if lRecord["hashId"] == lPath[2] {
+                        lZones[lPath[1]] = append(lRecords[:index:index], lRecords[index + 1:]...)
+                        w.WriteHeader(204)
+                        return
+                    }
+                }
+        }
+        w.WriteHeader(400)
+    }))
+    return s, lZones
+}
+
+func dnsTestChange(action, ip string) T_DnsChange {
+    return T_DnsChange{ Action: action, Record: map[string]string{ "Domain": "example.com", "Type": "A", "Name": "www", "Ttl": "300", "Ip": ip } }
+}
+
+func dnsTestStatuses(changes []T_DnsChange) []string {
+    var lStatuses []string
+    for _, lChange := range changes {
+        lStatuses = append(lStatuses, lChange.Status())
+    }
+    return lStatuses
+}
+
+func TestDnsApplyChangesRollback(t *testing.T) {
+    s, lZones := newDnsTestServer("example.com")
+    defer s.Close()
+    c := NewA24ApiClient(map[string]string{ "endpoint": s.URL })
+
+    lChanges, err := c.DnsApplyChanges([]T_DnsChange{ dnsTestChange("create", "192.0.2.1"), dnsTestChange("create", "192.0.2.2"), dnsTestChange("create", "192.0.2.99"), dnsTestChange("create", "192.0.2.3") })
+    if err == nil {
+        t.Fatalf("DnsApplyChanges did not fail")
+    }
+    lWant := []string{ C_DnsResult_RolledBack, C_DnsResult_RolledBack, C_DnsResult_Error, C_DnsResult_Skipped }
+    if lStatuses := dnsTestStatuses(lChanges); !reflect.DeepEqual(lStatuses, lWant) {
+        t.Errorf("statuses %q, want %q", lStatuses, lWant)
+    }
+    if len(lZones["example.com"]) != 0 {
+        t.Errorf("records left after rollback: %+v", lZones["example.com"])
+    }
+}
+
+func TestDnsRevertChanges(t *testing.T) {
+    s, lZones := newDnsTestServer("example.com")
+    defer s.Close()
+    c := NewA24ApiClient(map[string]string{ "endpoint": s.URL })
+
+    lChanges, err := c.DnsApplyChanges([]T_DnsChange{ dnsTestChange("create", "192.0.2.1"), dnsTestChange("create", "192.0.2.2") })
+    if err != nil {
+        t.Fatalf("DnsApplyChanges failed: %v", err)
+    }
+    lReverts, err := c.DnsRevertChanges(lChanges)
+    if err != nil {
+        t.Fatalf("DnsRevertChanges failed: %v", err)
+    }
+    if len(lReverts) != 2 || lReverts[0].Action != "delete" || lReverts[0].Record["Ip"] != "192.0.2.2" || lReverts[0].Record["HashId"] != "h2" {
+        t.Errorf("reverting changes %+v", lReverts)
+    }
+    if lStatuses := dnsTestStatuses(lReverts); !reflect.DeepEqual(lStatuses, []string{ C_DnsResult_Ok, C_DnsResult_Ok }) {
+        t.Errorf("reverting statuses %q", lStatuses)
+    }
+    if len(lZones["example.com"]) != 0 {
+        t.Errorf("records left after revert: %+v", lZones["example.com"])
+    }
+}
@@ -0,0 +1,152 @@
+package a24apiclient
+
+import (
+    "fmt"
+    "net"
+    "regexp"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+
+// T_DnsSearchQuery matches records by regex (value, name or type), by cidr (A/AAAA ip), by suffix
+// (CNAME/MX/NS/SRV target) or exactly (A/AAAA ip or CNAME/MX/NS/SRV target)
+type T_DnsSearchQuery struct {
+    Mode            string
+    Field           string
+    Regexp          *regexp.Regexp
+    Network         *net.IPNet
+    Suffix          string
+    Exact           string
+}
+
+// record fields holding hostname targets
+var C_A24ApiClient_DnsTargetFields = map[string]string {
+    "CNAME": "Alias",
+    "MX": "Mailserver",
+    "NS": "NameServer",
+    "SRV": "Target",
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Constructor
+// --------------------------------------------------------------------------------------------------------------------
+
+// NewDnsSearchQuery builds query, mode is regex, cidr, suffix, exact or empty for autodetection (ip address or cidr
+// means cidr, anything else regex), field is value, name or type (regex mode only, default value)
+func NewDnsSearchQuery(pattern, mode, field string) (*T_DnsSearchQuery, error) {
+    q := &T_DnsSearchQuery{ Mode: mode, Field: field }
+    if q.Field == "" {
+        q.Field = "value"
+    }
+    if q.Mode == "" {
+        q.Mode = "regex"
+        if _, _, err := net.ParseCIDR(pattern); err == nil {
+            q.Mode = "cidr"
+        } else if net.ParseIP(pattern) != nil {
+            q.Mode = "cidr"
+        }
+    }
+    switch q.Mode {
+        case "regex":
+            if q.Field != "value" && q.Field != "name" && q.Field != "type" {
+                return nil, NewA24ApiClientError(fmt.Sprintf("Error: Unsupported search field %s.", q.Field))
+            }
+            lRegexp, err := regexp.Compile(pattern)
+            if err != nil {
+                return nil, err
+            }
+            q.Regexp = lRegexp
+        case "cidr":
+            if lIp := net.ParseIP(pattern); lIp != nil {
+                if lIp.To4() != nil {
+                    pattern = pattern + "/32"
+                } else {
+                    pattern = pattern + "/128"
+                }
+            }
+            _, lNetwork, err := net.ParseCIDR(pattern)
+            if err != nil {
+                return nil, err
+            }
+            q.Network = lNetwork
+        case "suffix":
+            q.Suffix = dnsCanonicalHost(pattern)
+        case "exact":
+            q.Exact = dnsCanonicalHost(pattern)
+        default:
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Unsupported search mode %s.", q.Mode))
+    }
+    return q, nil
+}
+
+func dnsCanonicalHost(host string) string {
+    return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Match
+// --------------------------------------------------------------------------------------------------------------------
+
+func (q *T_DnsSearchQuery) Match(record map[string]string) bool {
+    switch q.Mode {
+        case "regex":
+            switch q.Field {
+                case "name":
+                    return q.Regexp.MatchString(record["Name"])
+                case "type":
+                    return q.Regexp.MatchString(record["Type"])
+                default:
+                    return q.Regexp.MatchString(DnsRecordValue(record))
+            }
+        case "cidr":
+            if record["Type"] != "A" && record["Type"] != "AAAA" {
+                return false
+            }
+            lIp := net.ParseIP(record["Ip"])
+            return lIp != nil && q.Network.Contains(lIp)
+        case "suffix":
+            lField, isPresent := C_A24ApiClient_DnsTargetFields[record["Type"]]
+            if !isPresent {
+                return false
+            }
+            lTarget := dnsCanonicalHost(record[lField])
+            if strings.HasPrefix(q.Suffix, ".") {
+                return strings.HasSuffix(lTarget, q.Suffix)
+            }
+            return lTarget == q.Suffix || strings.HasSuffix(lTarget, "." + q.Suffix)
+        case "exact":
+            if record["Type"] == "A" || record["Type"] == "AAAA" {
+                lIp := net.ParseIP(record["Ip"])
+                return lIp != nil && lIp.Equal(net.ParseIP(q.Exact))
+            }
+            lField, isPresent := C_A24ApiClient_DnsTargetFields[record["Type"]]
+            return isPresent && dnsCanonicalHost(record[lField]) == q.Exact
+    }
+    return false
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Search records of several domains
+// --------------------------------------------------------------------------------------------------------------------
+
+// DnsSearch returns matching records of domains in domain order, second value lists domains which failed to load
+func (c *T_A24ApiClient) DnsSearch(domains []string, query *T_DnsSearchQuery, concurrency int) ([]map[string]string, []T_DnsDomainRecords) {
+    var lMatches []map[string]string
+    var lFailed []T_DnsDomainRecords
+    for _, lDomainRecords := range c.DnsListRecordsMulti(domains, concurrency) {
+        if lDomainRecords.Err != nil {
+            lFailed = append(lFailed, lDomainRecords)
+            continue
+        }
+        for _, element := range lDomainRecords.Records {
+            lRecord := NewDnsRecordFromList(lDomainRecords.Domain, element)
+            if query.Match(lRecord) {
+                lMatches = append(lMatches, lRecord)
+            }
+        }
+    }
+    return lMatches, lFailed
+}
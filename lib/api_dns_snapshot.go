@@ -0,0 +1,180 @@
+package a24apiclient
+
+import (
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "time"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+
+type T_DnsSnapshot struct {
+    Domain          string                `json:"domain"`
+    Time            time.Time             `json:"time"`
+    Records         T_DnsRecordList       `json:"records"`
+}
+
+const (
+    C_DnsSnapshot_TimeFormat = "20060102-150405"
+)
+
+func NewDnsSnapshot(domain string, records T_DnsRecordList) *T_DnsSnapshot {
+    return &T_DnsSnapshot{ Domain: domain, Time: time.Now().UTC(), Records: records }
+}
+
+// RecordMaps returns snapshot records converted by NewDnsRecordFromList
+func (s *T_DnsSnapshot) RecordMaps() []map[string]string {
+    var lRecords []map[string]string
+    for _, element := range s.Records {
+        lRecords = append(lRecords, NewDnsRecordFromList(s.Domain, element))
+    }
+    return lRecords
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Snapshot files
+// --------------------------------------------------------------------------------------------------------------------
+//
+// Snapshots are stored as <dir>/<domain>-<YYYYmmdd-HHMMSS>.json (UTC).
+
+// WriteDnsSnapshot stores snapshot into dir and returns path of created file
+func WriteDnsSnapshot(dir string, snapshot *T_DnsSnapshot) (string, error) {
+    if err := os.MkdirAll(dir, 0700); err != nil {
+        return "", err
+    }
+    lData, err := json.MarshalIndent(snapshot, "", "    ")
+    if err != nil {
+        return "", err
+    }
+    lPath := filepath.Join(dir, snapshot.Domain + "-" + snapshot.Time.Format(C_DnsSnapshot_TimeFormat) + ".json")
+    if err := ioutil.WriteFile(lPath, append(lData, '\n'), 0600); err != nil {
+        return "", err
+    }
+    return lPath, nil
+}
+
+func ReadDnsSnapshot(path string) (*T_DnsSnapshot, error) {
+    lData, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var s T_DnsSnapshot
+    if err := json.Unmarshal(lData, &s); err != nil {
+        return nil, err
+    }
+    if s.Domain == "" {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: %s is not a dns snapshot.", path))
+    }
+    return &s, nil
+}
+
+// LatestDnsSnapshot returns path of the most recent snapshot of domain in dir
+func LatestDnsSnapshot(dir, domain string) (string, error) {
+    lPaths, err := filepath.Glob(filepath.Join(dir, domain + "-*.json"))
+    if err != nil {
+        return "", err
+    }
+    var lSnapshots []string
+    for _, lPath := range lPaths {
+        lStamp := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(lPath), domain + "-"), ".json")
+        if _, err := time.Parse(C_DnsSnapshot_TimeFormat, lStamp); err == nil {
+            lSnapshots = append(lSnapshots, lPath)
+        }
+    }
+    if len(lSnapshots) == 0 {
+        return "", NewA24ApiClientError(fmt.Sprintf("Error: No snapshot of %s found in %s.", domain, dir))
+    }
+    sort.Strings(lSnapshots)
+    return lSnapshots[len(lSnapshots) - 1], nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Take snapshot
+// --------------------------------------------------------------------------------------------------------------------
+
+func (c *T_A24ApiClient) DnsSnapshot(domain string) (int, *T_DnsSnapshot, error) {
+    rc, lRecords, err := c.DnsListRecords(map[string]string{ "0": domain })
+    if err != nil {
+        return rc, nil, err
+    }
+    if err := c.dnsResponseError(rc, "list"); err != nil {
+        return rc, nil, err
+    }
+    return rc, NewDnsSnapshot(domain, lRecords), nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Plan restore
+// --------------------------------------------------------------------------------------------------------------------
+
+// DnsPlanRestore returns changes bringing live records back to snapshot. Snapshot records are paired with live
+// ones by hashId, then by equal content (record recreated by earlier restore). Changed records are updated,
+// missing ones created. Live records not present in snapshot are deleted only with prune.
+func DnsPlanRestore(snapshot, live []map[string]string, prune bool) []T_DnsChange {
+    var lChanges []T_DnsChange
+    lUsed := make(map[int]bool)
+    var lUnmatched []map[string]string
+
+    for _, lSnapshot := range snapshot {
+        lMatched := false
+        for index, lLive := range live {
+            if lUsed[index] || lLive["HashId"] != lSnapshot["HashId"] || lLive["Type"] != lSnapshot["Type"] {
+                continue
+            }
+            lUsed[index] = true
+            lMatched = true
+            if !DnsRecordEqual(lLive, lSnapshot) {
+                lChanges = append(lChanges, dnsUpdateChange(lLive, lSnapshot))
+            }
+            break
+        }
+        if !lMatched {
+            lUnmatched = append(lUnmatched, lSnapshot)
+        }
+    }
+
+    for _, lSnapshot := range lUnmatched {
+        lMatched := false
+        for index, lLive := range live {
+            if !lUsed[index] && DnsRecordEqual(lLive, lSnapshot) {
+                lUsed[index] = true
+                lMatched = true
+                break
+            }
+        }
+        if !lMatched {
+            lRecord := make(map[string]string)
+            for key, value := range lSnapshot {
+                lRecord[key] = value
+            }
+            delete(lRecord, "HashId")
+            lChanges = append(lChanges, T_DnsChange{ Action: "create", Record: lRecord })
+        }
+    }
+
+    if prune {
+        for index, lLive := range live {
+            if !lUsed[index] {
+                lChanges = append(lChanges, T_DnsChange{ Action: "delete", Record: lLive })
+            }
+        }
+    }
+
+    return lChanges
+}
+
+// DnsPlanRestoreLive loads live records of snapshot domain and plans restore
+func (c *T_A24ApiClient) DnsPlanRestoreLive(snapshot *T_DnsSnapshot, prune bool) ([]T_DnsChange, error) {
+    _, lLive, err := c.DnsSnapshot(snapshot.Domain)
+    if err != nil {
+        return nil, err
+    }
+    return DnsPlanRestore(snapshot.RecordMaps(), lLive.RecordMaps(), prune), nil
+}
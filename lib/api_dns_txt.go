@@ -0,0 +1,120 @@
+package a24apiclient
+
+import (
+    "strings"
+    "unicode/utf8"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// TXT character-strings
+// --------------------------------------------------------------------------------------------------------------------
+//
+// TXT record data is a sequence of character-strings of at most 255 bytes each. Records keep the logical value
+// (strings concatenated, e.g. the whole DKIM key). Values sent to api which are longer than 255 bytes or look like
+// quoted strings themselves are encoded in presentation format "part1" "part2" with " and \ escaped, values read
+// from api in that format are reassembled.
+
+const (
+    C_DnsTxt_ChunkLength = 255
+)
+
+// DnsTxtEncode converts logical TXT value into the form sent to api
+func DnsTxtEncode(text string) string {
+    if len(text) <= C_DnsTxt_ChunkLength && !DnsTxtIsQuoted(text) {
+        return text
+    }
+    var lChunks []string
+    for _, lChunk := range DnsTxtChunks(text) {
+        lChunks = append(lChunks, "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(lChunk) + "\"")
+    }
+    return strings.Join(lChunks, " ")
+}
+
+// DnsTxtChunks splits text into chunks of at most 255 bytes without breaking utf-8 characters
+func DnsTxtChunks(text string) []string {
+    lChunks := []string{}
+    for len(text) > C_DnsTxt_ChunkLength {
+        lEnd := C_DnsTxt_ChunkLength
+        for lEnd > 0 && !utf8.RuneStart(text[lEnd]) {
+            lEnd--
+        }
+        lChunks = append(lChunks, text[:lEnd])
+        text = text[lEnd:]
+    }
+    return append(lChunks, text)
+}
+
+// DnsTxtDecode reassembles TXT value in presentation format, other values are returned unchanged
+func DnsTxtDecode(text string) string {
+    lChunks, isQuoted := dnsTxtParse(text)
+    if !isQuoted {
+        return text
+    }
+    return strings.Join(lChunks, "")
+}
+
+// DnsTxtIsQuoted reports whether text consists only of quoted character-strings separated by whitespace
+func DnsTxtIsQuoted(text string) bool {
+    _, isQuoted := dnsTxtParse(text)
+    return isQuoted
+}
+
+// dnsTxtParse splits "a" "b" into unescaped strings, \" \\ and \DDD escapes are recognised
+func dnsTxtParse(text string) ([]string, bool) {
+    lText := strings.TrimSpace(text)
+    if !strings.HasPrefix(lText, "\"") {
+        return nil, false
+    }
+    var lChunks []string
+    index := 0
+    for index < len(lText) {
+        if lText[index] == ' ' || lText[index] == '\t' {
+            index++
+            continue
+        }
+        if lText[index] != '"' {
+            return nil, false
+        }
+        index++
+        var lChunk strings.Builder
+        lClosed := false
+        for index < len(lText) {
+            ch := lText[index]
+            if ch == '\\' && index + 1 < len(lText) {
+                if index + 3 < len(lText) && dnsTxtIsDigits(lText[index + 1:index + 4]) {
+                    lValue := int(lText[index + 1] - '0') * 100 + int(lText[index + 2] - '0') * 10 + int(lText[index + 3] - '0')
+                    if lValue > 255 {
+                        return nil, false
+                    }
+                    lChunk.WriteByte(byte(lValue))
+                    index += 4
+                    continue
+                }
+                lChunk.WriteByte(lText[index + 1])
+                index += 2
+                continue
+            }
+            if ch == '"' {
+                lClosed = true
+                index++
+                break
+            }
+            lChunk.WriteByte(ch)
+            index++
+        }
+        if !lClosed {
+            return nil, false
+        }
+        lChunks = append(lChunks, lChunk.String())
+    }
+    return lChunks, true
+}
+
+func dnsTxtIsDigits(text string) bool {
+    for index := 0; index < len(text); index++ {
+        if text[index] < '0' || text[index] > '9' {
+            return false
+        }
+    }
+    return true
+}
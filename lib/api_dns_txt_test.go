@@ -0,0 +1,73 @@
+package a24apiclient
+
+import (
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestDnsTxtEncode(t *testing.T) {
+    lLong := strings.Repeat("a", 300)
+    lTests := []struct {
+        name            string
+        text            string
+        encoded         string
+    }{
+        { "short value", "v=spf1 -all", "v=spf1 -all" },
+        { "exactly 255 bytes", strings.Repeat("a", 255), strings.Repeat("a", 255) },
+        { "long value is split", lLong, "\"" + strings.Repeat("a", 255) + "\" \"" + strings.Repeat("a", 45) + "\"" },
+        { "quoted value is escaped", "\"a\" \"b\"", "\"\\\"a\\\" \\\"b\\\"\"" },
+        { "escapes in long value", strings.Repeat("a", 254) + "\\\"", "\"" + strings.Repeat("a", 254) + "\\\\\" \"\\\"\"" },
+    }
+    for _, lTest := range lTests {
+        lEncoded := DnsTxtEncode(lTest.text)
+        if lEncoded != lTest.encoded {
+            t.Errorf("%s: DnsTxtEncode = %q, want %q", lTest.name, lEncoded, lTest.encoded)
+        }
+        if lDecoded := DnsTxtDecode(lEncoded); lDecoded != lTest.text {
+            t.Errorf("%s: DnsTxtDecode(DnsTxtEncode) = %q, want %q", lTest.name, lDecoded, lTest.text)
+        }
+    }
+}
+
+func TestDnsTxtChunks(t *testing.T) {
+    // 2-byte characters must not be split at 255 byte boundary
+    lText := "a" + strings.Repeat("ř", 200)
+    lChunks := DnsTxtChunks(lText)
+    if len(lChunks) != 2 || len(lChunks[0]) != 255 || strings.Join(lChunks, "") != lText {
+        t.Errorf("DnsTxtChunks split %d chunks of lengths %d", len(lChunks), len(lChunks[0]))
+    }
+    lText = strings.Repeat("ř", 200)
+    lChunks = DnsTxtChunks(lText)
+    if len(lChunks) != 2 || len(lChunks[0]) != 254 || strings.Join(lChunks, "") != lText {
+        t.Errorf("DnsTxtChunks broke utf-8 character, first chunk %d bytes", len(lChunks[0]))
+    }
+    if lChunks = DnsTxtChunks(""); !reflect.DeepEqual(lChunks, []string{ "" }) {
+        t.Errorf("DnsTxtChunks of empty text = %q", lChunks)
+    }
+}
+
+func TestDnsTxtDecode(t *testing.T) {
+    lTests := []struct {
+        text            string
+        decoded         string
+        isQuoted        bool
+    }{
+        { "plain text", "plain text", false },
+        { "\"a\" \"b\"", "ab", true },
+        { "  \"a\"\t\"b c\"  ", "ab c", true },
+        { "\"say \\\"hi\\\"\" \"\\\\\"", "say \"hi\"\\", true },
+        { "\"\\065\\066\"", "AB", true },
+        { "\"\\256\"", "\"\\256\"", false },
+        { "\"unterminated", "\"unterminated", false },
+        { "\"a\" b", "\"a\" b", false },
+    }
+    for _, lTest := range lTests {
+        if lDecoded := DnsTxtDecode(lTest.text); lDecoded != lTest.decoded {
+            t.Errorf("DnsTxtDecode(%q) = %q, want %q", lTest.text, lDecoded, lTest.decoded)
+        }
+        if lQuoted := DnsTxtIsQuoted(lTest.text); lQuoted != lTest.isQuoted {
+            t.Errorf("DnsTxtIsQuoted(%q) = %v, want %v", lTest.text, lQuoted, lTest.isQuoted)
+        }
+    }
+}
@@ -0,0 +1,85 @@
+package a24apiclient
+
+import (
+    "encoding/json"
+    "fmt"
+    "strconv"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Typed records
+// --------------------------------------------------------------------------------------------------------------------
+
+// NewDnsRecordTyped converts record map into typed record struct of its type (T_DnsRecordA, T_DnsRecordMX, ...)
+func NewDnsRecordTyped(record map[string]string) (interface{}, error) {
+    var lTyped interface{}
+    switch record["Type"] {
+        case "A":
+            lTyped = &T_DnsRecordA{}
+        case "AAAA":
+            lTyped = &T_DnsRecordAAAA{}
+        case "CNAME":
+            lTyped = &T_DnsRecordCNAME{}
+        case "TXT":
+            lTyped = &T_DnsRecordTXT{}
+        case "NS":
+            lTyped = &T_DnsRecordNS{}
+        case "SSHFP":
+            lTyped = &T_DnsRecordSSHFP{}
+        case "SRV":
+            lTyped = &T_DnsRecordSRV{}
+        case "TLSA":
+            lTyped = &T_DnsRecordTLSA{}
+        case "CAA":
+            lTyped = &T_DnsRecordCAA{}
+        case "MX":
+            lTyped = &T_DnsRecordMX{}
+        default:
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Unsupported record type %s.", record["Type"]))
+    }
+
+    lFields := append([]T_DnsRecordField{
+        { Key: "Domain", ApiKey: "domain" },
+        { Key: "HashId", ApiKey: "hashId" },
+        { Key: "Type", ApiKey: "type" },
+        { Key: "Name", ApiKey: "name" },
+        { Key: "Ttl", ApiKey: "ttl", Numeric: true },
+    }, C_A24ApiClient_DnsRecordFields[record["Type"]]...)
+    lData := make(map[string]interface{})
+    for _, lField := range lFields {
+        if !lField.Numeric {
+            lData[lField.ApiKey] = record[lField.Key]
+            continue
+        }
+        if record[lField.Key] == "" {
+            continue
+        }
+        lValue, err := strconv.ParseFloat(record[lField.Key], 64)
+        if err != nil {
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Invalid %s %s of %s record.", lField.ApiKey, record[lField.Key], record["Type"]))
+        }
+        lData[lField.ApiKey] = lValue
+    }
+
+    lJson, err := json.Marshal(lData)
+    if err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(lJson, lTyped); err != nil {
+        return nil, err
+    }
+    return lTyped, nil
+}
+
+// NewDnsRecordsTyped converts record maps by NewDnsRecordTyped
+func NewDnsRecordsTyped(records []map[string]string) ([]interface{}, error) {
+    lTyped := []interface{}{}
+    for _, lRecord := range records {
+        r, err := NewDnsRecordTyped(lRecord)
+        if err != nil {
+            return nil, err
+        }
+        lTyped = append(lTyped, r)
+    }
+    return lTyped, nil
+}
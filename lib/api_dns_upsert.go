@@ -0,0 +1,68 @@
+package a24apiclient
+
+import (
+    "fmt"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Upsert dns record
+// --------------------------------------------------------------------------------------------------------------------
+//
+// Existing records of the same name and type are looked up first:
+//   - CNAME allows one record per name, so an existing CNAME is updated to the new alias.
+//   - Other types are rrsets; the record with the same identity fields (see C_A24ApiClient_DnsRecordFields)
+//     is updated when ttl or remaining fields differ, otherwise a new member is created.
+//     Other members of the rrset are left untouched.
+// Returned action is one of create, update or none.
+
+const (
+    C_DnsUpsert_Create = "create"
+    C_DnsUpsert_Update = "update"
+    C_DnsUpsert_None = "none"
+)
+
+func (c *T_A24ApiClient) DnsUpsert(record map[string]string) (string, int, []byte, error) {
+
+    // existing records are compared in ascii form with canonical name
+    record, err := DnsRecordToAscii(record)
+    if err != nil {
+        return "", 0, nil, err
+    }
+    if record, err = NormaliseDnsRecordName(record); err != nil {
+        return "", 0, nil, err
+    }
+    if _, isPresent := C_A24ApiClient_DnsRecordFields[record["Type"]]; !isPresent {
+        return "", 0, nil, NewA24ApiClientError(fmt.Sprintf("Error: Unsupported dns type %s.", record["Type"]))
+    }
+    if err := ValidateDnsRecord(record, C_DnsUpsert_Create); err != nil {
+        return "", 0, nil, err
+    }
+
+    rc, lRecords, err := c.DnsListRecords(map[string]string{ "0": record["Domain"] })
+    if err != nil {
+        return "", rc, nil, err
+    }
+    if err := c.dnsResponseError(rc, "list"); err != nil {
+        return "", rc, nil, err
+    }
+
+    for _, element := range lRecords {
+        lExisting := NewDnsRecordFromList(record["Domain"], element)
+        if !DnsRecordSameIdentity(lExisting, record) {
+            continue
+        }
+        if DnsRecordEqual(lExisting, record) {
+            return C_DnsUpsert_None, rc, nil, nil
+        }
+        lRecord := make(map[string]string)
+        for key, value := range record {
+            lRecord[key] = value
+        }
+        lRecord["HashId"] = lExisting["HashId"]
+        rc, rb, err := c.DnsUpdate(lRecord)
+        return C_DnsUpsert_Update, rc, rb, err
+    }
+
+    rc, rb, err := c.DnsCreate(record)
+    return C_DnsUpsert_Create, rc, rb, err
+}
@@ -0,0 +1,271 @@
+package a24apiclient
+
+import (
+    "encoding/hex"
+    "fmt"
+    "net"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+
+// T_DnsFieldError describes one invalid field of record, Field is api key (ip, ttl, mailserver, ...)
+type T_DnsFieldError struct {
+    Field           string                `json:"field"`
+    Value           string                `json:"value"`
+    Message         string                `json:"message"`
+}
+
+// T_DnsValidationError lists all invalid fields of record
+type T_DnsValidationError struct {
+    Type            string                `json:"type"`
+    Name            string                `json:"name"`
+    Fields          []T_DnsFieldError     `json:"fields"`
+}
+
+func (e *T_DnsValidationError) Error() string {
+    var lFields []string
+    for _, lField := range e.Fields {
+        lFields = append(lFields, fmt.Sprintf("%s %q %s", lField.Field, lField.Value, lField.Message))
+    }
+    return fmt.Sprintf("Error: Invalid %s record %s: %s.", e.Type, e.Name, strings.Join(lFields, "; "))
+}
+
+// TXT values longer than 255 bytes are split into character-strings by DnsTxtEncode, limit is set by 65535 bytes
+// of record data
+const (
+    C_DnsValidate_TtlMin = 0
+    C_DnsValidate_TtlMax = 2147483647
+    C_DnsValidate_TxtMaxLength = 65280
+    C_DnsValidate_NameMaxLength = 253
+    C_DnsValidate_LabelMaxLength = 63
+)
+
+// SSHFP algorithms (RFC 4255, 6594, 7479, 8709) and fingerprint types with hex length of fingerprint
+var C_DnsValidate_SshfpAlgorithms = map[int]string { 1: "RSA", 2: "DSA", 3: "ECDSA", 4: "Ed25519", 6: "Ed448" }
+var C_DnsValidate_SshfpFingerprintTypes = map[int]int { 1: 40, 2: 64 }
+
+// TLSA matching types with hex length of hash, 0 is full certificate or key of any length
+var C_DnsValidate_TlsaMatchingTypes = map[int]int { 0: 0, 1: 64, 2: 128 }
+
+// CAA property tags (RFC 8659, 8657, 9495)
+var C_DnsValidate_CaaTags = map[string]bool {
+    "issue": true,
+    "issuewild": true,
+    "iodef": true,
+    "issuemail": true,
+    "issuevmc": true,
+    "contactemail": true,
+    "contactphone": true,
+}
+
+var C_DnsValidate_Label = regexp.MustCompile(`^[A-Za-z0-9_]([A-Za-z0-9_-]*[A-Za-z0-9_])?$`)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Validate
+// --------------------------------------------------------------------------------------------------------------------
+
+// ValidateDnsRecord checks record before it is sent to api, returned error is *T_DnsValidationError with every
+// invalid field. HashId is required for update.
+func ValidateDnsRecord(record map[string]string, action string) error {
+    lFields, isPresent := C_A24ApiClient_DnsRecordFields[record["Type"]]
+    if !isPresent {
+        return NewA24ApiClientError(fmt.Sprintf("Error: Unsupported dns type %s.", record["Type"]))
+    }
+    e := &T_DnsValidationError{ Type: record["Type"], Name: record["Name"] }
+    lFail := func(field, value, message string) {
+        e.Fields = append(e.Fields, T_DnsFieldError{ Field: field, Value: value, Message: message })
+    }
+
+    if record["Domain"] == "" {
+        lFail("domain", "", "is empty")
+    }
+    if action == "update" && record["HashId"] == "" {
+        lFail("hashId", "", "is required for update")
+    }
+    if lMessage := validateDnsName(record["Name"], true); lMessage != "" {
+        lFail("name", record["Name"], lMessage)
+    }
+    if lMessage := validateDnsInt(record["Ttl"], C_DnsValidate_TtlMin, C_DnsValidate_TtlMax); lMessage != "" {
+        lFail("ttl", record["Ttl"], lMessage)
+    }
+    for _, lField := range lFields {
+        if record[lField.Key] == "" {
+            lFail(lField.ApiKey, "", "is empty")
+        }
+    }
+    if len(e.Fields) > 0 {
+        return e
+    }
+
+    switch record["Type"] {
+        case "A":
+            if lIp := net.ParseIP(record["Ip"]); lIp == nil || lIp.To4() == nil || strings.Contains(record["Ip"], ":") {
+                lFail("ip", record["Ip"], "is not an IPv4 address")
+            }
+        case "AAAA":
+            if lIp := net.ParseIP(record["Ip"]); lIp == nil || !strings.Contains(record["Ip"], ":") {
+                lFail("ip", record["Ip"], "is not an IPv6 address")
+            }
+        case "CNAME":
+            if lMessage := validateDnsHostname(record["Alias"], false); lMessage != "" {
+                lFail("alias", record["Alias"], lMessage)
+            }
+        case "NS":
+            if lMessage := validateDnsHostname(record["NameServer"], false); lMessage != "" {
+                lFail("nameServer", record["NameServer"], lMessage)
+            }
+        case "MX":
+            if lMessage := validateDnsInt(record["Priority"], 0, 65535); lMessage != "" {
+                lFail("priority", record["Priority"], lMessage)
+            }
+            // "." is null MX (RFC 7505)
+            if lMessage := validateDnsHostname(record["Mailserver"], true); lMessage != "" {
+                lFail("mailserver", record["Mailserver"], lMessage)
+            }
+        case "SRV":
+            for _, lField := range []string{ "Priority", "Weight", "Port" } {
+                if lMessage := validateDnsInt(record[lField], 0, 65535); lMessage != "" {
+                    lFail(strings.ToLower(lField), record[lField], lMessage)
+                }
+            }
+            // "." means service is not available (RFC 2782)
+            if lMessage := validateDnsHostname(record["Target"], true); lMessage != "" {
+                lFail("target", record["Target"], lMessage)
+            }
+        case "TXT":
+            if len(record["Text"]) > C_DnsValidate_TxtMaxLength {
+                lFail("text", dnsValidateShort(record["Text"]), fmt.Sprintf("is %d bytes long, maximum is %d (255 strings of 255 bytes)", len(record["Text"]), C_DnsValidate_TxtMaxLength))
+            }
+        case "SSHFP":
+            lAlgorithm, err := strconv.Atoi(record["Algorithm"])
+            if _, isKnown := C_DnsValidate_SshfpAlgorithms[lAlgorithm]; err != nil || !isKnown {
+                lFail("algorithm", record["Algorithm"], "is not 1 (RSA), 2 (DSA), 3 (ECDSA), 4 (Ed25519) or 6 (Ed448)")
+            }
+            lType, err := strconv.Atoi(record["FingerprintType"])
+            lLength, isKnown := C_DnsValidate_SshfpFingerprintTypes[lType]
+            if err != nil || !isKnown {
+                lFail("fingerprintType", record["FingerprintType"], "is not 1 (SHA-1) or 2 (SHA-256)")
+            }
+            if lMessage := validateDnsHex(record["Text"], lLength); lMessage != "" {
+                lFail("text", dnsValidateShort(record["Text"]), lMessage)
+            }
+        case "TLSA":
+            if lMessage := validateDnsInt(record["CertificateUsage"], 0, 3); lMessage != "" {
+                lFail("certificateUsage", record["CertificateUsage"], lMessage + " (0 PKIX-TA, 1 PKIX-EE, 2 DANE-TA, 3 DANE-EE)")
+            }
+            if lMessage := validateDnsInt(record["Selector"], 0, 1); lMessage != "" {
+                lFail("selector", record["Selector"], lMessage + " (0 full certificate, 1 public key)")
+            }
+            lType, err := strconv.Atoi(record["MatchingType"])
+            lLength, isKnown := C_DnsValidate_TlsaMatchingTypes[lType]
+            if err != nil || !isKnown {
+                lFail("matchingType", record["MatchingType"], "is not 0 (full), 1 (SHA-256) or 2 (SHA-512)")
+            }
+            if lMessage := validateDnsHex(record["Hash"], lLength); lMessage != "" {
+                lFail("hash", dnsValidateShort(record["Hash"]), lMessage)
+            }
+        case "CAA":
+            if record["Flags"] != "0" && record["Flags"] != "128" {
+                lFail("flags", record["Flags"], "is not 0 or 128 (critical)")
+            }
+            lTag := strings.ToLower(record["Tag"])
+            if !C_DnsValidate_CaaTags[lTag] {
+                lFail("tag", record["Tag"], "is not issue, issuewild, iodef, issuemail, issuevmc, contactemail or contactphone")
+            }
+            if lTag == "iodef" && !strings.HasPrefix(record["CaaValue"], "mailto:") && !strings.HasPrefix(record["CaaValue"], "https://") && !strings.HasPrefix(record["CaaValue"], "http://") {
+                lFail("caaValue", record["CaaValue"], "of iodef is not a mailto:, http:// or https:// url")
+            }
+    }
+
+    if len(e.Fields) > 0 {
+        return e
+    }
+    return nil
+}
+
+// validateDnsName checks owner name relative to domain or absolute with trailing dot, @ is apex and * is allowed
+// as the first label
+func validateDnsName(name string, wildcard bool) string {
+    if name == "" {
+        return "is empty"
+    }
+    if name == "@" {
+        return ""
+    }
+    if len(strings.TrimSuffix(name, ".")) > C_DnsValidate_NameMaxLength {
+        return fmt.Sprintf("is longer than %d characters", C_DnsValidate_NameMaxLength)
+    }
+    for index, lLabel := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+        if wildcard && index == 0 && lLabel == "*" {
+            continue
+        }
+        if lMessage := validateDnsLabel(lLabel); lMessage != "" {
+            return lMessage
+        }
+    }
+    return ""
+}
+
+// validateDnsHostname checks target host name, root "." is accepted only when allowed
+func validateDnsHostname(name string, root bool) string {
+    if name == "." {
+        if root {
+            return ""
+        }
+        return "must not be root"
+    }
+    if strings.HasPrefix(name, "@") || strings.Contains(name, "*") {
+        return "is not a host name"
+    }
+    if strings.Contains(name, "..") {
+        return "has empty label"
+    }
+    return validateDnsName(name, false)
+}
+
+func validateDnsLabel(label string) string {
+    if label == "" {
+        return "has empty label"
+    }
+    if len(label) > C_DnsValidate_LabelMaxLength {
+        return fmt.Sprintf("has label %s longer than %d characters", label, C_DnsValidate_LabelMaxLength)
+    }
+    if !C_DnsValidate_Label.MatchString(label) {
+        return fmt.Sprintf("has invalid label %s (letters, digits, hyphen and underscore, no hyphen at either end)", label)
+    }
+    return ""
+}
+
+func validateDnsInt(value string, min, max int) string {
+    lValue, err := strconv.Atoi(value)
+    if err != nil {
+        return "is not an integer"
+    }
+    if lValue < min || lValue > max {
+        return fmt.Sprintf("is out of range %d-%d", min, max)
+    }
+    return ""
+}
+
+// validateDnsHex checks hex string, length 0 accepts any even length
+func validateDnsHex(value string, length int) string {
+    if _, err := hex.DecodeString(value); err != nil {
+        return "is not a hex string"
+    }
+    if length > 0 && len(value) != length {
+        return fmt.Sprintf("has %d hex digits, expected %d", len(value), length)
+    }
+    return ""
+}
+
+func dnsValidateShort(value string) string {
+    if len(value) > 40 {
+        return value[:37] + "..."
+    }
+    return value
+}
@@ -0,0 +1,362 @@
+package a24apiclient
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Parse BIND zone file
+// --------------------------------------------------------------------------------------------------------------------
+//
+// Supported are $ORIGIN and $TTL directives, comments, multi-line records in parentheses, quoted strings,
+// omitted owner, ttl and class and record types of C_A24ApiClient_DnsRecordFields. SOA and other types are skipped
+// and reported as warnings. Owner names are returned relative to origin (@ for apex), relative targets are
+// made absolute.
+
+type T_DnsZoneFile struct {
+    Origin          string
+    Records         []map[string]string
+    Warnings        []string
+}
+
+type t_zoneToken struct {
+    text            string
+    quoted          bool
+}
+
+// ParseDnsZoneFile parses zone file, origin is used until $ORIGIN directive (may be empty) and becomes Domain
+// of returned records
+func ParseDnsZoneFile(r io.Reader, origin string) (*T_DnsZoneFile, error) {
+    z := &T_DnsZoneFile{ Origin: dnsCanonicalHost(origin) }
+    lDefaultTtl := ""
+    lOwner := "@"
+    lLineNo := 0
+    var lEntry []t_zoneToken
+    var lEntryLine int
+    var lEntryIndented bool
+    lDepth := 0
+
+    s := bufio.NewScanner(r)
+    s.Buffer(make([]byte, 64 * 1024), 1024 * 1024)
+    for s.Scan() {
+        lLineNo++
+        lText := s.Text()
+        lTokens, lOpen, lClose, err := tokenizeZoneLine(lText)
+        if err != nil {
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Zone file line %d: %s", lLineNo, err))
+        }
+        if lDepth == 0 {
+            if len(lTokens) == 0 && lOpen == 0 {
+                continue
+            }
+            lEntry = nil
+            lEntryLine = lLineNo
+            lEntryIndented = len(lText) > 0 && (lText[0] == ' ' || lText[0] == '\t')
+        }
+        lEntry = append(lEntry, lTokens...)
+        lDepth += lOpen - lClose
+        if lDepth < 0 {
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Zone file line %d: unbalanced parentheses.", lLineNo))
+        }
+        if lDepth > 0 {
+            continue
+        }
+
+        if !lEntryIndented && len(lEntry) > 0 && !lEntry[0].quoted && strings.HasPrefix(lEntry[0].text, "$") {
+            switch strings.ToUpper(lEntry[0].text) {
+                case "$ORIGIN":
+                    if len(lEntry) < 2 {
+                        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Zone file line %d: $ORIGIN without value.", lEntryLine))
+                    }
+                    z.Origin = dnsCanonicalHost(zoneAbsoluteName(lEntry[1].text, z.Origin))
+                case "$TTL":
+                    if len(lEntry) < 2 {
+                        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Zone file line %d: $TTL without value.", lEntryLine))
+                    }
+                    lTtl, err := ParseDnsTtl(lEntry[1].text)
+                    if err != nil {
+                        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Zone file line %d: %s", lEntryLine, err))
+                    }
+                    lDefaultTtl = lTtl
+                default:
+                    z.Warnings = append(z.Warnings, fmt.Sprintf("line %d: directive %s skipped", lEntryLine, lEntry[0].text))
+            }
+            continue
+        }
+
+        lRecord, lWarning, err := z.parseZoneEntry(lEntry, lEntryIndented, &lOwner, lDefaultTtl)
+        if err != nil {
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Zone file line %d: %s", lEntryLine, err))
+        }
+        if lWarning != "" {
+            z.Warnings = append(z.Warnings, fmt.Sprintf("line %d: %s", lEntryLine, lWarning))
+        }
+        if lRecord != nil {
+            z.Records = append(z.Records, lRecord)
+        }
+    }
+    if err := s.Err(); err != nil {
+        return nil, err
+    }
+    if lDepth != 0 {
+        return nil, NewA24ApiClientError("Error: Zone file ends inside parentheses.")
+    }
+    for _, lRecord := range z.Records {
+        lRecord["Domain"] = z.Origin
+    }
+    return z, nil
+}
+
+func (z *T_DnsZoneFile) parseZoneEntry(tokens []t_zoneToken, indented bool, owner *string, defaultTtl string) (map[string]string, string, error) {
+    index := 0
+    if !indented {
+        *owner = tokens[0].text
+        index++
+    }
+    lTtl := defaultTtl
+    // optional ttl and class in any order
+    for lOptional := 0; index < len(tokens) && lOptional < 2; lOptional++ {
+        lToken := strings.ToUpper(tokens[index].text)
+        if lToken == "IN" || lToken == "CH" || lToken == "HS" {
+            index++
+            continue
+        }
+        if lParsed, err := ParseDnsTtl(lToken); err == nil {
+            lTtl = lParsed
+            index++
+            continue
+        }
+        break
+    }
+    if index >= len(tokens) {
+        return nil, "", NewA24ApiClientError("record type not found.")
+    }
+    lType := strings.ToUpper(tokens[index].text)
+    lData := tokens[index + 1:]
+    lFields, isPresent := C_A24ApiClient_DnsRecordFields[lType]
+    if !isPresent {
+        return nil, fmt.Sprintf("record type %s skipped", lType), nil
+    }
+    if lTtl == "" {
+        return nil, "", NewA24ApiClientError(fmt.Sprintf("ttl of %s record not set and no $TTL given.", lType))
+    }
+
+    r := map[string]string{ "Type": lType, "Name": zoneRelativeName(*owner, z.Origin), "Ttl": lTtl }
+    switch lType {
+        case "TXT":
+            // character-strings are concatenated
+            var lText strings.Builder
+            for _, lToken := range lData {
+                lText.WriteString(lToken.text)
+            }
+            r["Text"] = lText.String()
+            return r, "", nil
+        case "CAA":
+            if len(lData) < 3 {
+                return nil, "", NewA24ApiClientError("CAA expects flags, tag and value.")
+            }
+            var lValues []string
+            for _, lToken := range lData[2:] {
+                lValues = append(lValues, lToken.text)
+            }
+            r["Flags"] = lData[0].text
+            r["Tag"] = lData[1].text
+            r["CaaValue"] = strings.Join(lValues, " ")
+            return r, "", nil
+        case "TLSA", "SSHFP":
+            // hash and fingerprint may be split into several tokens
+            if len(lData) < len(lFields) {
+                return nil, "", NewA24ApiClientError(fmt.Sprintf("%s expects %d values.", lType, len(lFields)))
+            }
+            var lHex strings.Builder
+            for _, lToken := range lData[len(lFields) - 1:] {
+                lHex.WriteString(lToken.text)
+            }
+            lData = append(lData[:len(lFields) - 1:len(lFields) - 1], t_zoneToken{ text: lHex.String() })
+    }
+    if len(lData) != len(lFields) {
+        return nil, "", NewA24ApiClientError(fmt.Sprintf("%s expects %d values, %d given.", lType, len(lFields), len(lData)))
+    }
+    for index, lField := range lFields {
+        r[lField.Key] = lData[index].text
+    }
+    if lField, isTarget := C_A24ApiClient_DnsTargetFields[lType]; isTarget {
+        r[lField] = zoneAbsoluteName(r[lField], z.Origin)
+    }
+    return r, "", nil
+}
+
+// tokenizeZoneLine splits line into tokens, strips comment and counts parentheses
+func tokenizeZoneLine(line string) ([]t_zoneToken, int, int, error) {
+    var lTokens []t_zoneToken
+    var lOpen, lClose int
+    index := 0
+    for index < len(line) {
+        ch := line[index]
+        switch {
+            case ch == ';':
+                return lTokens, lOpen, lClose, nil
+            case ch == ' ' || ch == '\t' || ch == '\r':
+                index++
+            case ch == '(':
+                lOpen++
+                index++
+            case ch == ')':
+                lClose++
+                index++
+            case ch == '"':
+                var lText strings.Builder
+                index++
+                lClosed := false
+                for index < len(line) {
+                    if line[index] == '\\' && index + 1 < len(line) {
+                        lText.WriteByte(line[index + 1])
+                        index += 2
+                        continue
+                    }
+                    if line[index] == '"' {
+                        lClosed = true
+                        index++
+                        break
+                    }
+                    lText.WriteByte(line[index])
+                    index++
+                }
+                if !lClosed {
+                    return nil, 0, 0, NewA24ApiClientError("unterminated quoted string.")
+                }
+                lTokens = append(lTokens, t_zoneToken{ text: lText.String(), quoted: true })
+            default:
+                lStart := index
+                for index < len(line) && !strings.ContainsRune(" \t\r;()\"", rune(line[index])) {
+                    index++
+                }
+                lTokens = append(lTokens, t_zoneToken{ text: line[lStart:index] })
+        }
+    }
+    return lTokens, lOpen, lClose, nil
+}
+
+func zoneAbsoluteName(name, origin string) string {
+    if name == "@" {
+        return origin + "."
+    }
+    if strings.HasSuffix(name, ".") || origin == "" {
+        return name
+    }
+    return name + "." + origin + "."
+}
+
+func zoneRelativeName(name, origin string) string {
+    if name == "@" || name == "" {
+        return "@"
+    }
+    if !strings.HasSuffix(name, ".") {
+        return name
+    }
+    lName := strings.TrimSuffix(name, ".")
+    if origin == "" {
+        return name
+    }
+    if strings.EqualFold(lName, origin) {
+        return "@"
+    }
+    if strings.HasSuffix(strings.ToLower(lName), "." + origin) {
+        return lName[:len(lName) - len(origin) - 1]
+    }
+    return name
+}
+
+// ParseDnsTtl converts ttl in seconds or with units (1h30m, 2d, 1w) into seconds
+func ParseDnsTtl(ttl string) (string, error) {
+    if lSeconds, err := strconv.ParseUint(ttl, 10, 32); err == nil {
+        return strconv.FormatUint(lSeconds, 10), nil
+    }
+    var lTotal, lValue uint64
+    lDigits := false
+    for _, ch := range strings.ToLower(ttl) {
+        if ch >= '0' && ch <= '9' {
+            lValue = lValue * 10 + uint64(ch - '0')
+            lDigits = true
+            continue
+        }
+        var lUnit uint64
+        switch ch {
+            case 's':
+                lUnit = 1
+            case 'm':
+                lUnit = 60
+            case 'h':
+                lUnit = 3600
+            case 'd':
+                lUnit = 86400
+            case 'w':
+                lUnit = 604800
+            default:
+                return "", NewA24ApiClientError(fmt.Sprintf("Error: Invalid ttl %s.", ttl))
+        }
+        if !lDigits {
+            return "", NewA24ApiClientError(fmt.Sprintf("Error: Invalid ttl %s.", ttl))
+        }
+        lTotal += lValue * lUnit
+        lValue = 0
+        lDigits = false
+    }
+    if lDigits || ttl == "" {
+        return "", NewA24ApiClientError(fmt.Sprintf("Error: Invalid ttl %s.", ttl))
+    }
+    return strconv.FormatUint(lTotal, 10), nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Write BIND zone file
+// --------------------------------------------------------------------------------------------------------------------
+
+// WriteDnsZoneFile writes records of domain as zone file readable by ParseDnsZoneFile: $ORIGIN of domain, owner
+// names relative to it, explicit ttl and class, absolute targets and quoted TXT character-strings and CAA values
+func WriteDnsZoneFile(w io.Writer, domain string, records []map[string]string) error {
+    if _, err := fmt.Fprintf(w, "$ORIGIN %s.\n", dnsCanonicalHost(domain)); err != nil {
+        return err
+    }
+    for _, lRecord := range records {
+        lFields, isPresent := C_A24ApiClient_DnsRecordFields[lRecord["Type"]]
+        if !isPresent {
+            continue
+        }
+        var lValues []string
+        switch lRecord["Type"] {
+            case "TXT":
+                lChunks := DnsTxtChunks(lRecord["Text"])
+                if len(lChunks) == 0 {
+                    lChunks = []string{ "" }
+                }
+                for _, lChunk := range lChunks {
+                    lValues = append(lValues, zoneQuote(lChunk))
+                }
+            case "CAA":
+                lValues = []string{ lRecord["Flags"], lRecord["Tag"], zoneQuote(lRecord["CaaValue"]) }
+            default:
+                lTarget := C_A24ApiClient_DnsTargetFields[lRecord["Type"]]
+                for _, lField := range lFields {
+                    lValue := lRecord[lField.Key]
+                    // relative target would be completed by origin
+                    if lField.Key == lTarget && !strings.HasSuffix(lValue, ".") {
+                        lValue += "."
+                    }
+                    lValues = append(lValues, lValue)
+                }
+        }
+        if _, err := fmt.Fprintf(w, "%s\t%s\tIN\t%s\t%s\n", DnsNameRelative(lRecord["Name"], domain), lRecord["Ttl"], lRecord["Type"], strings.Join(lValues, " ")); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func zoneQuote(text string) string {
+    return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(text) + "\""
+}
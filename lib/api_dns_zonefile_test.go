@@ -0,0 +1,40 @@
+package a24apiclient
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+)
+
+func TestWriteDnsZoneFileRoundTrip(t *testing.T) {
+    lRecords := []map[string]string{
+        { "Domain": "example.com", "Type": "A", "Name": "@", "Ttl": "300", "Ip": "192.0.2.1" },
+        { "Domain": "example.com", "Type": "CNAME", "Name": "www", "Ttl": "300", "Alias": "Web.Example.NET" },
+        { "Domain": "example.com", "Type": "MX", "Name": "@", "Ttl": "3600", "Priority": "10", "Mailserver": "mail.example.com." },
+        { "Domain": "example.com", "Type": "TXT", "Name": "@", "Ttl": "3600", "Text": "v=spf1 -all" },
+        { "Domain": "example.com", "Type": "TXT", "Name": "long", "Ttl": "3600", "Text": strings.Repeat("a\"b\\", 100) },
+        { "Domain": "example.com", "Type": "TXT", "Name": "empty", "Ttl": "3600", "Text": "" },
+        { "Domain": "example.com", "Type": "CAA", "Name": "@", "Ttl": "3600", "Flags": "0", "Tag": "issue", "CaaValue": "ca.example; account=1" },
+        { "Domain": "example.com", "Type": "SRV", "Name": "_imaps._tcp", "Ttl": "3600", "Priority": "0", "Weight": "1", "Port": "993", "Target": "imap.example.com" },
+        { "Domain": "example.com", "Type": "SSHFP", "Name": "host", "Ttl": "3600", "Algorithm": "4", "FingerprintType": "2", "Text": strings.Repeat("ab", 32) },
+    }
+    var lZone bytes.Buffer
+    if err := WriteDnsZoneFile(&lZone, "example.com", lRecords); err != nil {
+        t.Fatalf("WriteDnsZoneFile failed: %v", err)
+    }
+    z, err := ParseDnsZoneFile(&lZone, "")
+    if err != nil {
+        t.Fatalf("ParseDnsZoneFile failed: %v\n%s", err, lZone.String())
+    }
+    if z.Origin != "example.com" || len(z.Records) != len(lRecords) || len(z.Warnings) != 0 {
+        t.Fatalf("parsed zone %+v", z)
+    }
+    for index, lRecord := range lRecords {
+        if !DnsRecordEqual(NormaliseDnsRecord(lRecord, "example.com"), NormaliseDnsRecord(z.Records[index], "example.com")) {
+            t.Errorf("record %v parsed as %v", lRecord, z.Records[index])
+        }
+    }
+    if z.Records[1]["Alias"] != "Web.Example.NET." {
+        t.Errorf("target written as %s", z.Records[1]["Alias"])
+    }
+}
@@ -0,0 +1,420 @@
+package a24apiclient
+
+import (
+    "fmt"
+    "net/url"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+//
+// Mail authentication policies published as TXT records: DMARC at _dmarc (RFC 7489), MTA-STS id at _mta-sts
+// (RFC 8461) and TLS-RPT at _smtp._tls (RFC 8460). Records use the tag-list syntax of DKIM, version tag first.
+
+// T_MailAuthRecord is policy record of one kind with its findings, Published is false when name has no such record
+type T_MailAuthRecord struct {
+    Kind            string                `json:"kind"`
+    Name            string                `json:"name"`
+    Text            string                `json:"text,omitempty"`
+    Published       bool                  `json:"published"`
+    Findings        []T_SpfFinding        `json:"findings"`
+    Record          map[string]string     `json:"-"`
+
+    count           int
+}
+
+// T_MailAuth is report of policies published for domain
+type T_MailAuth struct {
+    Domain          string                `json:"domain"`
+    Records         []T_MailAuthRecord    `json:"records"`
+}
+
+// T_MailAuthKind describes record name and version tag of policy kind
+type T_MailAuthKind struct {
+    Kind            string
+    Name            string
+    Version         string
+}
+
+const (
+    C_MailAuth_Dmarc = "dmarc"
+    C_MailAuth_MtaSts = "mta-sts"
+    C_MailAuth_TlsRpt = "tls-rpt"
+    C_MailAuth_Ttl = "3600"
+)
+
+var C_MailAuth_Kinds = []T_MailAuthKind {
+    { Kind: C_MailAuth_Dmarc, Name: "_dmarc", Version: "DMARC1" },
+    { Kind: C_MailAuth_MtaSts, Name: "_mta-sts", Version: "STSv1" },
+    { Kind: C_MailAuth_TlsRpt, Name: "_smtp._tls", Version: "TLSRPTv1" },
+}
+
+// DMARC tags in order of generated records
+var C_MailAuth_DmarcTags = []string{ "v", "p", "sp", "pct", "rua", "ruf", "adkim", "aspf", "fo", "rf", "ri" }
+
+var C_MailAuth_DmarcPolicies = map[string]bool{ "none": true, "quarantine": true, "reject": true }
+var C_MailAuth_MtaStsId = regexp.MustCompile(`^[A-Za-z0-9]{1,32}$`)
+var C_MailAuth_DmarcUriSize = regexp.MustCompile(`![0-9]+[kmgt]?$`)
+
+// MailAuthKind returns description of policy kind
+func MailAuthKind(kind string) (T_MailAuthKind, error) {
+    for _, lKind := range C_MailAuth_Kinds {
+        if lKind.Kind == kind {
+            return lKind, nil
+        }
+    }
+    return T_MailAuthKind{}, NewA24ApiClientError(fmt.Sprintf("Error: Unknown mail authentication record %s.", kind))
+}
+
+// IsMailAuth reports whether TXT value is record of kind (version tag first)
+func (k T_MailAuthKind) IsMailAuth(text string) bool {
+    lTags := ParseDkimTags(text)
+    return len(lTags) > 0 && lTags[0].Name == "v" && lTags[0].Value == k.Version
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Validate
+// --------------------------------------------------------------------------------------------------------------------
+
+// ValidateMailAuth checks syntax of record of kind and reports errors and risky values
+func ValidateMailAuth(kind, text string) []T_SpfFinding {
+    lFindings := []T_SpfFinding{}
+    add := func(level, term, message string) {
+        lFindings = append(lFindings, T_SpfFinding{ Level: level, Term: term, Message: message })
+    }
+    lKind, err := MailAuthKind(kind)
+    if err != nil {
+        add(C_SpfLint_Error, "", err.Error())
+        return lFindings
+    }
+    lTags := ParseDkimTags(text)
+    if !lKind.IsMailAuth(text) {
+        add(C_SpfLint_Error, "", fmt.Sprintf("record must start with v=%s", lKind.Version))
+        return lFindings
+    }
+    lSeen := make(map[string]bool)
+    for _, lTag := range lTags {
+        if lSeen[lTag.Name] {
+            add(C_SpfLint_Error, lTag.Name, "tag is given more than once")
+        }
+        lSeen[lTag.Name] = true
+    }
+    switch kind {
+        case C_MailAuth_Dmarc:
+            validateDmarc(lTags, add)
+        case C_MailAuth_MtaSts:
+            lId, isPresent := dkimTag(lTags, "id")
+            if !isPresent {
+                add(C_SpfLint_Error, "id", "id is required")
+            } else if !C_MailAuth_MtaStsId.MatchString(lId) {
+                add(C_SpfLint_Error, "id", "id must be 1-32 letters and digits")
+            }
+            validateMailAuthUnknown(lTags, []string{ "v", "id" }, add)
+        case C_MailAuth_TlsRpt:
+            lRua, isPresent := dkimTag(lTags, "rua")
+            if !isPresent || lRua == "" {
+                add(C_SpfLint_Error, "rua", "rua is required")
+            }
+            for _, lUri := range mailAuthUris(lRua) {
+                if lMessage := validateMailAuthUri(lUri, []string{ "mailto", "https" }); lMessage != "" {
+                    add(C_SpfLint_Error, "rua", lMessage)
+                }
+            }
+            validateMailAuthUnknown(lTags, []string{ "v", "rua" }, add)
+    }
+    return lFindings
+}
+
+func validateDmarc(tags []T_DkimTag, add func(level, term, message string)) {
+    lPolicy, isPresent := dkimTag(tags, "p")
+    if !isPresent {
+        add(C_SpfLint_Error, "p", "p is required")
+    } else if !C_MailAuth_DmarcPolicies[lPolicy] {
+        add(C_SpfLint_Error, "p", "p must be none, quarantine or reject")
+    } else if lPolicy == "none" {
+        add(C_SpfLint_Warning, "p", "p=none only monitors, failing mail is delivered")
+    }
+    if len(tags) > 1 && tags[1].Name != "p" && isPresent {
+        add(C_SpfLint_Warning, "p", "p should directly follow v")
+    }
+    if lPolicy, isPresent := dkimTag(tags, "sp"); isPresent && !C_MailAuth_DmarcPolicies[lPolicy] {
+        add(C_SpfLint_Error, "sp", "sp must be none, quarantine or reject")
+    }
+    if lPct, isPresent := dkimTag(tags, "pct"); isPresent {
+        if lValue, err := strconv.Atoi(lPct); err != nil || lValue < 0 || lValue > 100 {
+            add(C_SpfLint_Error, "pct", "pct must be 0-100")
+        } else if lValue < 100 {
+            add(C_SpfLint_Warning, "pct", fmt.Sprintf("policy applies to %d%% of failing mail only", lValue))
+        }
+    }
+    for _, lName := range []string{ "adkim", "aspf" } {
+        if lMode, isPresent := dkimTag(tags, lName); isPresent && lMode != "r" && lMode != "s" {
+            add(C_SpfLint_Error, lName, fmt.Sprintf("%s must be r (relaxed) or s (strict)", lName))
+        }
+    }
+    if _, isPresent := dkimTag(tags, "rua"); !isPresent {
+        add(C_SpfLint_Warning, "rua", "no aggregate reports are requested (rua)")
+    }
+    for _, lName := range []string{ "rua", "ruf" } {
+        lValue, _ := dkimTag(tags, lName)
+        for _, lUri := range mailAuthUris(lValue) {
+            if lMessage := validateMailAuthUri(C_MailAuth_DmarcUriSize.ReplaceAllString(lUri, ""), []string{ "mailto", "https" }); lMessage != "" {
+                add(C_SpfLint_Error, lName, lMessage)
+            }
+        }
+    }
+    if lOptions, isPresent := dkimTag(tags, "fo"); isPresent {
+        for _, lOption := range strings.Split(lOptions, ":") {
+            if lOption != "0" && lOption != "1" && lOption != "d" && lOption != "s" {
+                add(C_SpfLint_Error, "fo", "fo must be colon separated 0, 1, d or s")
+                break
+            }
+        }
+    }
+    if lInterval, isPresent := dkimTag(tags, "ri"); isPresent {
+        if _, err := strconv.ParseUint(lInterval, 10, 32); err != nil {
+            add(C_SpfLint_Error, "ri", "ri must be number of seconds")
+        }
+    }
+    validateMailAuthUnknown(tags, C_MailAuth_DmarcTags, add)
+}
+
+func validateMailAuthUnknown(tags []T_DkimTag, known []string, add func(level, term, message string)) {
+    for _, lTag := range tags {
+        lKnown := false
+        for _, lName := range known {
+            lKnown = lKnown || lTag.Name == lName
+        }
+        if !lKnown {
+            add(C_SpfLint_Warning, lTag.Name, "unknown tag is ignored")
+        }
+    }
+}
+
+// validateMailAuthUri checks report uri, scheme must be one of schemes
+func validateMailAuthUri(uri string, schemes []string) string {
+    lUrl, err := url.Parse(uri)
+    if err != nil || lUrl.Scheme == "" {
+        return fmt.Sprintf("%s is not an uri", uri)
+    }
+    for _, lScheme := range schemes {
+        if strings.ToLower(lUrl.Scheme) != lScheme {
+            continue
+        }
+        if lScheme == "mailto" && !strings.Contains(lUrl.Opaque, "@") {
+            return fmt.Sprintf("%s is not an e-mail address", uri)
+        }
+        if lScheme == "https" && lUrl.Host == "" {
+            return fmt.Sprintf("%s has no host", uri)
+        }
+        return ""
+    }
+    return fmt.Sprintf("%s must be %s uri", uri, strings.Join(schemes, " or "))
+}
+
+func mailAuthUris(value string) []string {
+    var lUris []string
+    for _, lUri := range strings.Split(value, ",") {
+        if lUri = strings.TrimSpace(lUri); lUri != "" {
+            lUris = append(lUris, lUri)
+        }
+    }
+    return lUris
+}
+
+func mailAuthErrors(findings []T_SpfFinding) []string {
+    var lErrors []string
+    for _, lFinding := range findings {
+        if lFinding.Level == C_SpfLint_Error {
+            lErrors = append(lErrors, lFinding.Message)
+        }
+    }
+    return lErrors
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Generate
+// --------------------------------------------------------------------------------------------------------------------
+
+// NewDmarc returns DMARC record with tags set by options (p, sp, pct, rua, ruf, adkim, aspf), other tags are taken
+// from existing record (may be empty); tags with empty option value are removed
+func NewDmarc(existing string, options map[string]string) string {
+    lValues := map[string]string{ "v": "DMARC1" }
+    var lExtra []string
+    for _, lTag := range ParseDkimTags(existing) {
+        if _, isPresent := lValues[lTag.Name]; !isPresent && !mailAuthKnownDmarcTag(lTag.Name) {
+            lExtra = append(lExtra, lTag.Name)
+        }
+        if lTag.Name != "v" {
+            lValues[lTag.Name] = lTag.Value
+        }
+    }
+    for key, value := range options {
+        if value == "" {
+            delete(lValues, key)
+        } else {
+            lValues[key] = value
+        }
+    }
+    var lTags []T_DkimTag
+    for _, lName := range append(C_MailAuth_DmarcTags, lExtra...) {
+        if lValue, isPresent := lValues[lName]; isPresent {
+            lTags = append(lTags, T_DkimTag{ Name: lName, Value: lValue })
+        }
+    }
+    return DkimTagsString(lTags)
+}
+
+func mailAuthKnownDmarcTag(name string) bool {
+    for _, lName := range C_MailAuth_DmarcTags {
+        if lName == name {
+            return true
+        }
+    }
+    return false
+}
+
+// NewMtaSts returns MTA-STS record, empty id is generated from time (policy changes need new id)
+func NewMtaSts(id string, now time.Time) string {
+    if id == "" {
+        id = now.UTC().Format("20060102150405")
+    }
+    return fmt.Sprintf("v=STSv1; id=%s", id)
+}
+
+// NewTlsRpt returns TLS-RPT record with report uris (comma separated)
+func NewTlsRpt(rua string) string {
+    return fmt.Sprintf("v=TLSRPTv1; rua=%s", strings.Join(mailAuthUris(rua), ","))
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Show
+// --------------------------------------------------------------------------------------------------------------------
+
+// MailAuth returns DMARC, MTA-STS and TLS-RPT records published for domain with findings
+func (r *T_DnsResolver) MailAuth(domain string) (*T_MailAuth, error) {
+    lDomain, err := DnsIdnToAscii(domain)
+    if err != nil {
+        return nil, err
+    }
+    m := &T_MailAuth{ Domain: dnsCanonicalHost(lDomain) }
+    for _, lKind := range C_MailAuth_Kinds {
+        lRecord, err := r.MailAuthRecord(m.Domain, lKind.Kind)
+        if err != nil {
+            return nil, err
+        }
+        m.Records = append(m.Records, *lRecord)
+    }
+    return m, nil
+}
+
+// Errors returns number of error findings
+func (m *T_MailAuth) Errors() int {
+    lCount := 0
+    for _, lRecord := range m.Records {
+        lCount += len(mailAuthErrors(lRecord.Findings))
+    }
+    return lCount
+}
+
+// MailAuthRecord returns record of kind published for domain with findings
+func (r *T_DnsResolver) MailAuthRecord(domain, kind string) (*T_MailAuthRecord, error) {
+    lKind, err := MailAuthKind(kind)
+    if err != nil {
+        return nil, err
+    }
+    if domain, err = DnsIdnToAscii(domain); err != nil {
+        return nil, err
+    }
+    domain = dnsCanonicalHost(domain)
+    a := &T_MailAuthRecord{ Kind: lKind.Kind, Name: lKind.Name, Findings: []T_SpfFinding{} }
+    lRecords, err := r.Lookup(DnsNameAbsolute(lKind.Name, domain), "TXT")
+    if err != nil {
+        return nil, err
+    }
+    for _, lRecord := range lRecords {
+        if !lKind.IsMailAuth(lRecord["Text"]) {
+            continue
+        }
+        a.count++
+        if a.Published {
+            a.Findings = append(a.Findings, T_SpfFinding{ Level: C_SpfLint_Error, Domain: domain, Message: "more than one record is published, policy is ignored" })
+            continue
+        }
+        a.Published = true
+        a.Text = lRecord["Text"]
+        a.Record = lRecord
+    }
+    if !a.Published {
+        a.Findings = append(a.Findings, T_SpfFinding{ Level: C_SpfLint_Warning, Domain: domain, Message: "not published" })
+        return a, nil
+    }
+    for _, lFinding := range ValidateMailAuth(lKind.Kind, a.Text) {
+        lFinding.Domain = domain
+        a.Findings = append(a.Findings, lFinding)
+    }
+    // policy itself is served by https://mta-sts.<domain>/.well-known/mta-sts.txt
+    if lKind.Kind == C_MailAuth_MtaSts {
+        lAddresses, err := r.LookupAddresses("mta-sts." + domain)
+        if err == nil && len(lAddresses) == 0 {
+            a.Findings = append(a.Findings, T_SpfFinding{ Level: C_SpfLint_Warning, Domain: domain, Message: fmt.Sprintf("mta-sts.%s has no address, policy can not be fetched", domain) })
+        }
+    }
+    return a, nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Publish
+// --------------------------------------------------------------------------------------------------------------------
+
+// MailAuthChanges returns change creating or updating record of kind in account domain, no changes when record is
+// published as given. Records with errors are rejected.
+func (r *T_DnsResolver) MailAuthChanges(domain, kind, text string) ([]T_DnsChange, error) {
+    lKind, err := MailAuthKind(kind)
+    if err != nil {
+        return nil, err
+    }
+    if lErrors := mailAuthErrors(ValidateMailAuth(kind, text)); len(lErrors) > 0 {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Invalid %s record %s: %s.", kind, text, strings.Join(lErrors, ", ")))
+    }
+    lDomain, err := DnsIdnToAscii(domain)
+    if err != nil {
+        return nil, err
+    }
+    lDomain = dnsCanonicalHost(lDomain)
+    lZone, err := r.Zone(lDomain)
+    if err != nil {
+        return nil, err
+    }
+    if lZone == "" {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Domain %s is not in account domains.", lDomain))
+    }
+    a, err := r.MailAuthRecord(lDomain, kind)
+    if err != nil {
+        return nil, err
+    }
+    lName := DnsNameRelative(DnsNameAbsolute(lKind.Name, lDomain), lZone)
+    if !a.Published {
+        return []T_DnsChange{ { Action: "create", Record: map[string]string{ "Domain": lZone, "Type": "TXT", "Name": lName, "Ttl": C_MailAuth_Ttl, "Text": text } } }, nil
+    }
+    if a.count > 1 {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: %s.%s has more than one %s record, delete extra records first.", lName, lZone, kind))
+    }
+    if a.Record["Domain"] != lZone || !DnsNameEqual(a.Record["Name"], lName) {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: %s record of %s is published through CNAME, update %s.%s instead.", kind, lDomain, a.Record["Name"], a.Record["Domain"]))
+    }
+    if a.Text == text {
+        return []T_DnsChange{}, nil
+    }
+    lRecord := make(map[string]string)
+    for key, value := range a.Record {
+        lRecord[key] = value
+    }
+    lRecord["Text"] = text
+    return []T_DnsChange{ { Action: "update", Record: lRecord, Previous: a.Record } }, nil
+}
@@ -8,7 +8,7 @@ type T_DnsDomainList []string
 type T_DnsRecordList []map[string]interface{}
 
 type T_DnsRecordCNAME struct {
-    Domain         string
+    Domain         string     `json:"domain"`
     HashId         string     `json:"hashId"`
     Type           string     `json:"type"`
     Name           string     `json:"name"`
@@ -17,7 +17,7 @@ type T_DnsRecordCNAME struct {
 }
 
 type T_DnsRecordTXT struct {
-    Domain         string
+    Domain         string     `json:"domain"`
     HashId         string     `json:"hashId"`
     Type           string     `json:"type"`
     Name           string     `json:"name"`
@@ -26,10 +26,70 @@ type T_DnsRecordTXT struct {
 }
 
 type T_DnsRecordNS struct {
-    Domain         string
+    Domain         string     `json:"domain"`
     HashId         string     `json:"hashId"`
     Type           string     `json:"type"`
     Name           string     `json:"name"`
     NameServer     string     `json:"nameServer"`
     Ttl            float64    `json:"ttl"`
 }
+
+type T_DnsRecordSSHFP struct {
+    Domain         string     `json:"domain"`
+    HashId         string     `json:"hashId"`
+    Type           string     `json:"type"`
+    Name           string     `json:"name"`
+    Algorithm      float64    `json:"algorithm"`
+    FingerprintType float64   `json:"fingerprintType"`
+    Text           string     `json:"text"`
+    Ttl            float64    `json:"ttl"`
+}
+
+type T_DnsRecordSRV struct {
+    Domain         string     `json:"domain"`
+    HashId         string     `json:"hashId"`
+    Type           string     `json:"type"`
+    Name           string     `json:"name"`
+    Priority       float64    `json:"priority"`
+    Weight         float64    `json:"weight"`
+    Port           float64    `json:"port"`
+    Target         string     `json:"target"`
+    Ttl            float64    `json:"ttl"`
+}
+
+type T_DnsRecordTLSA struct {
+    Domain         string     `json:"domain"`
+    HashId         string     `json:"hashId"`
+    Type           string     `json:"type"`
+    Name           string     `json:"name"`
+    CertificateUsage float64  `json:"certificateUsage"`
+    Selector       float64    `json:"selector"`
+    MatchingType   float64    `json:"matchingType"`
+    Hash           string     `json:"hash"`
+    Ttl            float64    `json:"ttl"`
+}
+
+type T_DnsRecordCAA struct {
+    Domain         string     `json:"domain"`
+    HashId         string     `json:"hashId"`
+    Type           string     `json:"type"`
+    Name           string     `json:"name"`
+    Flags          float64    `json:"flags"`
+    Tag            string     `json:"tag"`
+    CaaValue       string     `json:"caaValue"`
+    Ttl            float64    `json:"ttl"`
+}
+
+type T_DnsRecordMX struct {
+    Domain         string     `json:"domain"`
+    HashId         string     `json:"hashId"`
+    Type           string     `json:"type"`
+    Name           string     `json:"name"`
+    Priority       float64    `json:"priority"`
+    Mailserver     string     `json:"mailserver"`
+    Ttl            float64    `json:"ttl"`
+}
+
+type T_DnsDomain struct {
+    Domain         string     `json:"domain"`
+}
@@ -0,0 +1,661 @@
+package a24apiclient
+
+import (
+    "fmt"
+    "net"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+
+// T_SpfTerm is one mechanism (with qualifier + - ~ ?) or modifier (redirect=, exp=, ...) of SPF record, Lookup marks
+// terms counted against the DNS lookup limit
+type T_SpfTerm struct {
+    Text            string                `json:"text"`
+    Qualifier       string                `json:"qualifier,omitempty"`
+    Mechanism       string                `json:"mechanism,omitempty"`
+    Modifier        string                `json:"modifier,omitempty"`
+    Value           string                `json:"value,omitempty"`
+    Lookup          bool                  `json:"lookup"`
+    Error           string                `json:"error,omitempty"`
+}
+
+// T_SpfRecord is parsed SPF record of name, Record is the TXT record when it comes from zone data
+type T_SpfRecord struct {
+    Domain          string                `json:"domain"`
+    Name            string                `json:"name"`
+    Text            string                `json:"text"`
+    Terms           []T_SpfTerm           `json:"terms"`
+    Record          map[string]string     `json:"-"`
+}
+
+// T_SpfFinding is one problem found by lint
+type T_SpfFinding struct {
+    Level           string                `json:"level"`
+    Domain          string                `json:"domain"`
+    Term            string                `json:"term,omitempty"`
+    Message         string                `json:"message"`
+}
+
+// T_SpfLint is lint result, Lookups counts DNS lookups of the whole evaluation including nested includes
+type T_SpfLint struct {
+    Domain          string                `json:"domain"`
+    Name            string                `json:"name"`
+    Text            string                `json:"text"`
+    Lookups         int                   `json:"lookups"`
+    VoidLookups     int                   `json:"voidLookups"`
+    Findings        []T_SpfFinding        `json:"findings"`
+}
+
+// T_SpfFlatten is flattened SPF record with remaining lookups
+type T_SpfFlatten struct {
+    Domain          string                `json:"domain"`
+    Name            string                `json:"name"`
+    Text            string                `json:"text"`
+    Flattened       string                `json:"flattened"`
+    Lookups         int                   `json:"lookups"`
+    Record          map[string]string     `json:"-"`
+}
+
+const (
+    C_Spf_Version = "v=spf1"
+    C_Spf_MaxLookups = 10
+    C_Spf_MaxVoidLookups = 2
+    C_Spf_MaxMxHosts = 10
+    C_Spf_MaxDepth = 10
+
+    C_SpfLint_Error = "error"
+    C_SpfLint_Warning = "warning"
+)
+
+// mechanisms with flag whether they need DNS lookup
+var C_Spf_Mechanisms = map[string]bool {
+    "all": false,
+    "include": true,
+    "a": true,
+    "mx": true,
+    "ptr": true,
+    "ip4": false,
+    "ip6": false,
+    "exists": true,
+}
+
+var C_Spf_Qualifiers = map[string]string {
+    "+": "pass",
+    "-": "fail",
+    "~": "softfail",
+    "?": "neutral",
+}
+
+var C_Spf_Modifier = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9_.-]*)=(.*)$`)
+var C_Spf_DualCidr = regexp.MustCompile(`^(.*?)(/([0-9]+))?(//([0-9]+))?$`)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Parse
+// --------------------------------------------------------------------------------------------------------------------
+
+// IsSpf reports whether TXT value is SPF record
+func IsSpf(text string) bool {
+    lText := strings.ToLower(strings.TrimSpace(text))
+    return lText == C_Spf_Version || strings.HasPrefix(lText, C_Spf_Version + " ")
+}
+
+// ParseSpf splits SPF record into terms, syntax errors are set on terms
+func ParseSpf(text string) ([]T_SpfTerm, error) {
+    lFields := strings.Fields(text)
+    if len(lFields) == 0 || strings.ToLower(lFields[0]) != C_Spf_Version {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: SPF record does not start with %s.", C_Spf_Version))
+    }
+    var lTerms []T_SpfTerm
+    for _, lField := range lFields[1:] {
+        lTerms = append(lTerms, parseSpfTerm(lField))
+    }
+    return lTerms, nil
+}
+
+func parseSpfTerm(text string) T_SpfTerm {
+    t := T_SpfTerm{ Text: text }
+    lText := text
+    if lMatch := C_Spf_Modifier.FindStringSubmatch(lText); lMatch != nil {
+        t.Modifier = strings.ToLower(lMatch[1])
+        t.Value = lMatch[2]
+        t.Lookup = t.Modifier == "redirect"
+        if (t.Modifier == "redirect" || t.Modifier == "exp") && t.Value == "" {
+            t.Error = fmt.Sprintf("%s requires domain", t.Modifier)
+        }
+        return t
+    }
+    t.Qualifier = "+"
+    if _, isQualifier := C_Spf_Qualifiers[lText[:1]]; isQualifier {
+        t.Qualifier = lText[:1]
+        lText = lText[1:]
+    }
+    lName := lText
+    if lIndex := strings.IndexAny(lText, ":/"); lIndex >= 0 {
+        lName = lText[:lIndex]
+        t.Value = strings.TrimPrefix(lText[lIndex:], ":")
+    }
+    t.Mechanism = strings.ToLower(lName)
+    lLookup, isKnown := C_Spf_Mechanisms[t.Mechanism]
+    if !isKnown {
+        t.Error = "unknown mechanism"
+        return t
+    }
+    t.Lookup = lLookup
+    switch t.Mechanism {
+        case "all":
+            if t.Value != "" {
+                t.Error = "all takes no value"
+            }
+        case "include", "exists":
+            if t.Value == "" || strings.HasPrefix(t.Value, "/") {
+                t.Error = fmt.Sprintf("%s requires domain", t.Mechanism)
+            }
+        case "ptr":
+            if strings.HasPrefix(t.Value, "/") {
+                t.Error = "ptr takes no prefix length"
+            }
+        case "a", "mx":
+            lMatch := C_Spf_DualCidr.FindStringSubmatch(t.Value)
+            if lMatch == nil || !spfPrefixValid(lMatch[3], 32) || !spfPrefixValid(lMatch[5], 128) {
+                t.Error = "invalid prefix length"
+            }
+        case "ip4":
+            if !spfNetworkValid(t.Value, false) {
+                t.Error = "invalid IPv4 network"
+            }
+        case "ip6":
+            if !spfNetworkValid(t.Value, true) {
+                t.Error = "invalid IPv6 network"
+            }
+    }
+    return t
+}
+
+// SpfTermDomain returns domain of include, exists, a, mx, ptr mechanism or redirect modifier, empty when the
+// mechanism refers to current domain
+func SpfTermDomain(term T_SpfTerm) string {
+    switch {
+        case term.Modifier == "redirect":
+            return term.Value
+        case term.Mechanism == "a" || term.Mechanism == "mx":
+            return C_Spf_DualCidr.FindStringSubmatch(term.Value)[1]
+        case term.Mechanism == "include" || term.Mechanism == "exists" || term.Mechanism == "ptr":
+            return term.Value
+    }
+    return ""
+}
+
+// SpfTermString formats term back into record syntax, pass qualifier is omitted
+func SpfTermString(term T_SpfTerm) string {
+    if term.Modifier != "" {
+        return term.Modifier + "=" + term.Value
+    }
+    lText := term.Mechanism
+    if term.Qualifier != "+" {
+        lText = term.Qualifier + lText
+    }
+    if term.Value != "" {
+        if strings.HasPrefix(term.Value, "/") {
+            return lText + term.Value
+        }
+        return lText + ":" + term.Value
+    }
+    return lText
+}
+
+func spfPrefixValid(prefix string, max int) bool {
+    if prefix == "" {
+        return true
+    }
+    lValue, err := strconv.Atoi(prefix)
+    return err == nil && lValue >= 0 && lValue <= max
+}
+
+func spfNetworkValid(value string, ipv6 bool) bool {
+    lIp := value
+    if lIndex := strings.Index(value, "/"); lIndex >= 0 {
+        lIp = value[:lIndex]
+        lMax := 32
+        if ipv6 {
+            lMax = 128
+        }
+        if !spfPrefixValid(value[lIndex + 1:], lMax) || value[lIndex + 1:] == "" {
+            return false
+        }
+    }
+    lParsed := net.ParseIP(lIp)
+    if lParsed == nil {
+        return false
+    }
+    return (lParsed.To4() != nil && !strings.Contains(lIp, ":")) != ipv6
+}
+
+func spfHasMacro(domain string) bool {
+    return strings.Contains(domain, "%")
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Show
+// --------------------------------------------------------------------------------------------------------------------
+
+// Spf returns parsed SPF record of name (relative to domain, @ for apex). Record of account domain is read from zone
+// data, other domains need resolver.
+func (r *T_DnsResolver) Spf(domain, name string) (*T_SpfRecord, error) {
+    lName, err := NormaliseDnsName(name, domain)
+    if err != nil {
+        return nil, err
+    }
+    s := &T_SpfRecord{ Domain: dnsCanonicalHost(domain), Name: lName }
+    lRecords, err := r.Lookup(DnsNameAbsolute(lName, domain), "TXT")
+    if err != nil {
+        return nil, err
+    }
+    for _, lRecord := range lRecords {
+        if !IsSpf(lRecord["Text"]) {
+            continue
+        }
+        if s.Record != nil {
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: %s has more than one SPF record.", DnsNameAbsolute(lName, domain)))
+        }
+        s.Record = lRecord
+        s.Text = lRecord["Text"]
+    }
+    if s.Record == nil {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: %s has no SPF record.", DnsNameAbsolute(lName, domain)))
+    }
+    if s.Terms, err = ParseSpf(s.Text); err != nil {
+        return nil, err
+    }
+    return s, nil
+}
+
+// spfOf returns SPF record of absolute name
+func (r *T_DnsResolver) spfOf(name string) (*T_SpfRecord, error) {
+    return r.Spf(name, C_DnsName_Apex)
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Lint
+// --------------------------------------------------------------------------------------------------------------------
+
+// LintSpf checks syntax of SPF record and of included records, counts DNS lookups (limit 10) and void lookups
+// (limit 2) of the whole evaluation and reports risky terms
+func (r *T_DnsResolver) LintSpf(domain, name string) (*T_SpfLint, error) {
+    s, err := r.Spf(domain, name)
+    if err != nil {
+        return nil, err
+    }
+    l := &T_SpfLint{ Domain: s.Domain, Name: s.Name, Text: s.Text, Findings: []T_SpfFinding{} }
+    lFqdn := dnsCanonicalHost(DnsNameAbsolute(s.Name, s.Domain))
+    r.lintSpf(l, lFqdn, s.Terms, map[string]bool{ lFqdn: true }, 0)
+    if l.Lookups > C_Spf_MaxLookups {
+        l.add(C_SpfLint_Error, lFqdn, "", fmt.Sprintf("%d DNS lookups, limit is %d", l.Lookups, C_Spf_MaxLookups))
+    }
+    if l.VoidLookups > C_Spf_MaxVoidLookups {
+        l.add(C_SpfLint_Error, lFqdn, "", fmt.Sprintf("%d void lookups, limit is %d", l.VoidLookups, C_Spf_MaxVoidLookups))
+    }
+    return l, nil
+}
+
+// Errors returns number of error findings
+func (l *T_SpfLint) Errors() int {
+    lCount := 0
+    for _, lFinding := range l.Findings {
+        if lFinding.Level == C_SpfLint_Error {
+            lCount++
+        }
+    }
+    return lCount
+}
+
+func (l *T_SpfLint) add(level, domain, term, message string) {
+    l.Findings = append(l.Findings, T_SpfFinding{ Level: level, Domain: domain, Term: term, Message: message })
+}
+
+func (r *T_DnsResolver) lintSpf(l *T_SpfLint, domain string, terms []T_SpfTerm, visited map[string]bool, depth int) {
+    lAll := ""
+    lRedirect := ""
+    lModifiers := make(map[string]bool)
+    for _, t := range terms {
+        if t.Error != "" {
+            l.add(C_SpfLint_Error, domain, t.Text, t.Error)
+            continue
+        }
+        if t.Modifier != "" {
+            if lModifiers[t.Modifier] && (t.Modifier == "redirect" || t.Modifier == "exp") {
+                l.add(C_SpfLint_Error, domain, t.Text, fmt.Sprintf("%s is given more than once", t.Modifier))
+            }
+            lModifiers[t.Modifier] = true
+            if t.Modifier == "redirect" {
+                lRedirect = t.Value
+                l.Lookups++
+            } else if t.Modifier != "exp" {
+                l.add(C_SpfLint_Warning, domain, t.Text, "unknown modifier is ignored")
+            }
+            continue
+        }
+        if lAll != "" {
+            l.add(C_SpfLint_Warning, domain, t.Text, "term after all is never evaluated")
+        }
+        if t.Lookup {
+            l.Lookups++
+        }
+        switch t.Mechanism {
+            case "all":
+                lAll = t.Text
+                if depth == 0 && (t.Qualifier == "+" || t.Qualifier == "?") {
+                    l.add(C_SpfLint_Warning, domain, t.Text, fmt.Sprintf("%s all lets any host send mail", C_Spf_Qualifiers[t.Qualifier]))
+                }
+            case "ptr":
+                l.add(C_SpfLint_Warning, domain, t.Text, "ptr is deprecated (RFC 7208)")
+            case "include":
+                r.lintSpfInclude(l, domain, t, t.Value, visited, depth)
+            case "a", "mx", "exists":
+                r.lintSpfVoid(l, domain, t)
+        }
+    }
+    if lRedirect != "" {
+        if lAll != "" {
+            l.add(C_SpfLint_Warning, domain, "redirect=" + lRedirect, "redirect is ignored when all is present")
+            return
+        }
+        r.lintSpfInclude(l, domain, T_SpfTerm{ Text: "redirect=" + lRedirect }, lRedirect, visited, depth)
+    }
+    if depth == 0 && lAll == "" && lRedirect == "" {
+        l.add(C_SpfLint_Warning, domain, "", "record has no all mechanism, default result is neutral")
+    }
+}
+
+func (r *T_DnsResolver) lintSpfInclude(l *T_SpfLint, domain string, term T_SpfTerm, target string, visited map[string]bool, depth int) {
+    if spfHasMacro(target) {
+        l.add(C_SpfLint_Warning, domain, term.Text, "macro is not expanded, nested lookups are not counted")
+        return
+    }
+    lTarget := dnsCanonicalHost(target)
+    if visited[lTarget] {
+        l.add(C_SpfLint_Error, domain, term.Text, "include loop")
+        return
+    }
+    if depth >= C_Spf_MaxDepth {
+        l.add(C_SpfLint_Error, domain, term.Text, "includes are nested too deep")
+        return
+    }
+    s, err := r.spfOf(lTarget)
+    if lResolverError, isResolverError := err.(*T_DnsResolverError); isResolverError {
+        l.add(C_SpfLint_Warning, domain, term.Text, fmt.Sprintf("not resolved, %s is not in account domains (use --resolver)", lResolverError.Name))
+        return
+    }
+    if err != nil {
+        l.add(C_SpfLint_Error, domain, term.Text, strings.TrimSuffix(strings.TrimPrefix(err.Error(), "Error: "), "."))
+        return
+    }
+    visited[lTarget] = true
+    r.lintSpf(l, lTarget, s.Terms, visited, depth + 1)
+    delete(visited, lTarget)
+}
+
+// lintSpfVoid counts lookups of a, mx and exists which return no records
+func (r *T_DnsResolver) lintSpfVoid(l *T_SpfLint, domain string, term T_SpfTerm) {
+    lTarget := SpfTermDomain(term)
+    if lTarget == "" {
+        lTarget = domain
+    }
+    if spfHasMacro(lTarget) {
+        return
+    }
+    var lFound int
+    var err error
+    switch term.Mechanism {
+        case "mx":
+            var lHosts []string
+            if lHosts, err = r.LookupMx(lTarget); err == nil && len(lHosts) > C_Spf_MaxMxHosts {
+                l.add(C_SpfLint_Error, domain, term.Text, fmt.Sprintf("%d mail servers, limit is %d", len(lHosts), C_Spf_MaxMxHosts))
+            }
+            lFound = len(lHosts)
+        default:
+            var lAddresses []string
+            lAddresses, err = r.LookupAddresses(lTarget)
+            lFound = len(lAddresses)
+    }
+    if lResolverError, isResolverError := err.(*T_DnsResolverError); isResolverError {
+        l.add(C_SpfLint_Warning, domain, term.Text, fmt.Sprintf("not resolved, %s is not in account domains (use --resolver)", lResolverError.Name))
+        return
+    }
+    if err != nil {
+        l.add(C_SpfLint_Error, domain, term.Text, strings.TrimSuffix(strings.TrimPrefix(err.Error(), "Error: "), "."))
+        return
+    }
+    if lFound == 0 {
+        l.VoidLookups++
+        l.add(C_SpfLint_Warning, domain, term.Text, "lookup returns no records")
+    }
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Flatten
+// --------------------------------------------------------------------------------------------------------------------
+
+// FlattenSpf replaces include, a, mx and redirect by ip4/ip6 networks they resolve to. Pass terms of included
+// records take qualifier of include, other results of included records do not match and are dropped; included record
+// with non-pass term before a pass term can not be flattened this way and is refused. Terms which can not be resolved
+// ahead of time (exists, ptr, macros) are kept, included or redirected record with such term referring to its own
+// domain (ptr without domain, %{d} macro) is refused. Record must be in zone data of account.
+func (r *T_DnsResolver) FlattenSpf(domain, name string) (*T_SpfFlatten, error) {
+    s, err := r.Spf(domain, name)
+    if err != nil {
+        return nil, err
+    }
+    if s.Record["HashId"] == "" {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: SPF record of %s is not in account domains.", DnsNameAbsolute(s.Name, s.Domain)))
+    }
+    lFqdn := dnsCanonicalHost(DnsNameAbsolute(s.Name, s.Domain))
+    lTerms, lTail, err := r.flattenSpf(lFqdn, s.Terms, "", map[string]bool{ lFqdn: true }, 0)
+    if err != nil {
+        return nil, err
+    }
+    f := &T_SpfFlatten{ Domain: s.Domain, Name: s.Name, Text: s.Text, Record: s.Record }
+    lSeen := make(map[string]bool)
+    lTexts := []string{ C_Spf_Version }
+    for _, t := range append(lTerms, lTail...) {
+        lText := SpfTermString(t)
+        if lSeen[strings.ToLower(lText)] {
+            continue
+        }
+        lSeen[strings.ToLower(lText)] = true
+        lTexts = append(lTexts, lText)
+        if t.Lookup {
+            f.Lookups++
+        }
+    }
+    f.Flattened = strings.Join(lTexts, " ")
+    return f, nil
+}
+
+// flattenSpf returns flattened mechanisms and trailing terms (all and modifiers of top record), qualifier overrides
+// pass qualifier of included terms
+func (r *T_DnsResolver) flattenSpf(domain string, terms []T_SpfTerm, qualifier string, visited map[string]bool, depth int) ([]T_SpfTerm, []T_SpfTerm, error) {
+    var lTerms, lTail []T_SpfTerm
+    lRedirect := ""
+    lAll := false
+    lNonPass := ""
+    for _, t := range terms {
+        if t.Error != "" {
+            return nil, nil, NewA24ApiClientError(fmt.Sprintf("Error: Invalid term %s of %s: %s.", t.Text, domain, t.Error))
+        }
+        if t.Modifier == "redirect" {
+            lRedirect = t.Value
+            continue
+        }
+        if t.Modifier != "" {
+            if depth == 0 {
+                lTail = append(lTail, t)
+            }
+            continue
+        }
+        if lAll {
+            continue
+        }
+        // included record contributes only its pass terms, which holds only when no other result can match first
+        if qualifier != "" {
+            if t.Qualifier != "+" {
+                if t.Mechanism == "all" {
+                    lAll = true
+                } else if lNonPass == "" {
+                    lNonPass = t.Text
+                }
+                continue
+            }
+            if lNonPass != "" {
+                return nil, nil, NewA24ApiClientError(fmt.Sprintf("Error: Include %s has %s before pass term %s and can not be flattened.", domain, lNonPass, t.Text))
+            }
+            t.Qualifier = qualifier
+        }
+        switch t.Mechanism {
+            case "all":
+                lAll = true
+                if qualifier != "" {
+                    // included +all matches everything
+                    lTerms = append(lTerms, T_SpfTerm{ Qualifier: t.Qualifier, Mechanism: "ip4", Value: "0.0.0.0/0" }, T_SpfTerm{ Qualifier: t.Qualifier, Mechanism: "ip6", Value: "::/0" })
+                } else if depth == 0 {
+                    lTail = append([]T_SpfTerm{ t }, lTail...)
+                }
+            case "ip4", "ip6":
+                lTerms = append(lTerms, t)
+            case "include":
+                if spfHasMacro(t.Value) {
+                    if err := spfFlattenKept(domain, t, depth); err != nil {
+                        return nil, nil, err
+                    }
+                    lTerms = append(lTerms, t)
+                    continue
+                }
+                lIncluded, err := r.flattenSpfTarget(domain, t.Value, t.Qualifier, visited, depth)
+                if err != nil {
+                    return nil, nil, err
+                }
+                lTerms = append(lTerms, lIncluded...)
+            case "a", "mx":
+                if err := spfFlattenKept(domain, t, depth); err != nil {
+                    return nil, nil, err
+                }
+                lNetworks, err := r.flattenSpfAddresses(domain, t)
+                if err != nil {
+                    return nil, nil, err
+                }
+                lTerms = append(lTerms, lNetworks...)
+            default:
+                // exists, ptr
+                if err := spfFlattenKept(domain, t, depth); err != nil {
+                    return nil, nil, err
+                }
+                lTerms = append(lTerms, t)
+        }
+    }
+    if lRedirect != "" && !lAll {
+        lRedirected, err := r.flattenSpfTarget(domain, lRedirect, qualifier, visited, depth)
+        if err != nil {
+            return nil, nil, err
+        }
+        lTerms = append(lTerms, lRedirected...)
+    }
+    return lTerms, lTail, nil
+}
+
+// spfFlattenKept refuses term of included or redirected record which is kept as it is but refers to domain of that
+// record, in top record it would refer to top domain
+func spfFlattenKept(domain string, term T_SpfTerm, depth int) error {
+    if depth == 0 {
+        return nil
+    }
+    if term.Mechanism == "ptr" && term.Value == "" {
+        return NewA24ApiClientError(fmt.Sprintf("Error: Term %s of %s refers to its domain and can not be flattened.", term.Text, domain))
+    }
+    if strings.Contains(strings.ToLower(SpfTermDomain(term)), "%{d") {
+        return NewA24ApiClientError(fmt.Sprintf("Error: Term %s of %s uses %%{d} macro and can not be flattened.", term.Text, domain))
+    }
+    return nil
+}
+
+// flattenSpfTarget flattens included record (qualifier set) or record redirected from top record (qualifier empty)
+func (r *T_DnsResolver) flattenSpfTarget(domain, target, qualifier string, visited map[string]bool, depth int) ([]T_SpfTerm, error) {
+    if spfHasMacro(target) {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Redirect %s of %s uses macro and can not be flattened.", target, domain))
+    }
+    lTarget := dnsCanonicalHost(target)
+    if visited[lTarget] {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Include loop at %s.", lTarget))
+    }
+    if depth >= C_Spf_MaxDepth {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: Includes of %s are nested too deep.", domain))
+    }
+    s, err := r.spfOf(lTarget)
+    if err != nil {
+        return nil, err
+    }
+    visited[lTarget] = true
+    defer delete(visited, lTarget)
+    lDepth := depth + 1
+    if qualifier == "" {
+        // redirect replaces evaluation of current record, its all is kept
+        lTerms, lTail, err := r.flattenSpf(lTarget, s.Terms, "", visited, lDepth)
+        if err != nil {
+            return nil, err
+        }
+        for _, t := range s.Terms {
+            if t.Mechanism == "all" {
+                lTail = append(lTail, t)
+            }
+        }
+        return append(lTerms, lTail...), nil
+    }
+    lTerms, _, err := r.flattenSpf(lTarget, s.Terms, qualifier, visited, lDepth)
+    return lTerms, err
+}
+
+// flattenSpfAddresses resolves a and mx mechanism into ip4/ip6 networks with prefix lengths of the mechanism
+func (r *T_DnsResolver) flattenSpfAddresses(domain string, term T_SpfTerm) ([]T_SpfTerm, error) {
+    lMatch := C_Spf_DualCidr.FindStringSubmatch(term.Value)
+    lTarget := lMatch[1]
+    if lTarget == "" {
+        lTarget = domain
+    }
+    if spfHasMacro(lTarget) {
+        return []T_SpfTerm{ term }, nil
+    }
+    lHosts := []string{ lTarget }
+    if term.Mechanism == "mx" {
+        var err error
+        if lHosts, err = r.LookupMx(lTarget); err != nil {
+            return nil, err
+        }
+    }
+    var lTerms []T_SpfTerm
+    for _, lHost := range lHosts {
+        lAddresses, err := r.LookupAddresses(lHost)
+        if err != nil {
+            return nil, err
+        }
+        for _, lAddress := range lAddresses {
+            t := T_SpfTerm{ Qualifier: term.Qualifier, Mechanism: "ip4", Value: lAddress }
+            lPrefix := lMatch[3]
+            if strings.Contains(lAddress, ":") {
+                t.Mechanism = "ip6"
+                lPrefix = lMatch[5]
+            }
+            if lPrefix != "" {
+                _, lNetwork, err := net.ParseCIDR(lAddress + "/" + lPrefix)
+                if err != nil {
+                    return nil, err
+                }
+                t.Value = lNetwork.String()
+            }
+            lTerms = append(lTerms, t)
+        }
+    }
+    return lTerms, nil
+}
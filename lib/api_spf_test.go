@@ -0,0 +1,91 @@
+package a24apiclient
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestParseSpf(t *testing.T) {
+    lTests := []struct {
+        text            string
+        terms           []string
+        errors          []string
+        isError         bool
+    }{
+        { "v=spf1 -all", []string{ "-all" }, []string{ "" }, false },
+        { "V=SPF1 ip4:192.0.2.0/24 ~include:_spf.example.com a/24//64 mx ptr", []string{ "ip4:192.0.2.0/24", "~include:_spf.example.com", "a/24//64", "mx", "ptr" }, []string{ "", "", "", "", "" }, false },
+        { "v=spf1 redirect=_spf.example.com exp=explain.example.com", []string{ "redirect=_spf.example.com", "exp=explain.example.com" }, []string{ "", "" }, false },
+        { "v=spf1 ip4:192.0.2.300 ip6:192.0.2.1 a/33 include all:x foo", []string{ "ip4:192.0.2.300", "ip6:192.0.2.1", "a/33", "include", "all:x", "foo" }, []string{ "invalid IPv4 network", "invalid IPv6 network", "invalid prefix length", "include requires domain", "all takes no value", "unknown mechanism" }, false },
+        { "v=spf1 redirect=", []string{ "redirect=" }, []string{ "redirect requires domain" }, false },
+        { "spf1 -all", nil, nil, true },
+        { "", nil, nil, true },
+    }
+    for _, lTest := range lTests {
+        lTerms, err := ParseSpf(lTest.text)
+        if (err != nil) != lTest.isError {
+            t.Errorf("ParseSpf(%q) error %v", lTest.text, err)
+            continue
+        }
+        if len(lTerms) != len(lTest.terms) {
+            t.Errorf("ParseSpf(%q) got %d terms, want %d", lTest.text, len(lTerms), len(lTest.terms))
+            continue
+        }
+        for index, lTerm := range lTerms {
+            if SpfTermString(lTerm) != lTest.terms[index] || lTerm.Error != lTest.errors[index] {
+                t.Errorf("ParseSpf(%q) term %d = %q error %q, want %q error %q", lTest.text, index, SpfTermString(lTerm), lTerm.Error, lTest.terms[index], lTest.errors[index])
+            }
+        }
+    }
+}
+
+// spfTestResolver returns resolver over zone data of example.com without api client and local resolver
+func spfTestResolver(spf map[string]string) *T_DnsResolver {
+    lRecords := []map[string]string{
+        { "Domain": "example.com", "Type": "A", "Name": "mail", "Ttl": "3600", "Ip": "192.0.2.25" },
+        { "Domain": "example.com", "Type": "MX", "Name": "@", "Ttl": "3600", "Priority": "10", "Mailserver": "mail.example.com" },
+    }
+    for lName, lText := range spf {
+        lRecords = append(lRecords, map[string]string{ "Domain": "example.com", "HashId": "h-" + lName, "Type": "TXT", "Name": lName, "Ttl": "3600", "Text": lText })
+    }
+    return &T_DnsResolver{ domains: []string{ "example.com" }, zones: map[string][]map[string]string{ "example.com": lRecords } }
+}
+
+func TestFlattenSpf(t *testing.T) {
+    lTests := []struct {
+        name            string
+        included        string
+        flattened       string
+        isError         bool
+    }{
+        { "pass terms take include qualifier", "v=spf1 ip4:192.0.2.0/24 a:mail.example.com ~all", "v=spf1 ip4:192.0.2.0/24 ip4:192.0.2.25 -all", false },
+        { "non-pass terms after pass terms are dropped", "v=spf1 ip4:192.0.2.0/24 -ip4:198.51.100.1 -all", "v=spf1 ip4:192.0.2.0/24 ip4:192.0.2.25 -all", false },
+        { "non-pass all ends included record", "v=spf1 -all ip4:192.0.2.0/24", "v=spf1 ip4:192.0.2.25 -all", false },
+        { "non-pass term before pass term", "v=spf1 -ip4:192.0.2.4 ip4:192.0.2.0/24 -all", "", true },
+        { "ptr without domain", "v=spf1 ptr -all", "", true },
+        { "ptr with domain is kept", "v=spf1 ptr:example.org -all", "v=spf1 ptr:example.org ip4:192.0.2.25 -all", false },
+        { "exists with domain macro", "v=spf1 exists:%{i}._ip.%{d} -all", "", true },
+        { "exists with other macro is kept", "v=spf1 exists:%{i}._ip.example.org -all", "v=spf1 exists:%{i}._ip.example.org ip4:192.0.2.25 -all", false },
+    }
+    for _, lTest := range lTests {
+        r := spfTestResolver(map[string]string{ "@": "v=spf1 include:_spf.example.com mx -all", "_spf": lTest.included })
+        f, err := r.FlattenSpf("example.com", "@")
+        if (err != nil) != lTest.isError {
+            t.Errorf("%s: error %v", lTest.name, err)
+            continue
+        }
+        if err == nil && f.Flattened != lTest.flattened {
+            t.Errorf("%s: got %q, want %q", lTest.name, f.Flattened, lTest.flattened)
+        }
+    }
+}
+
+func TestFlattenSpfRedirectAndLoop(t *testing.T) {
+    r := spfTestResolver(map[string]string{ "@": "v=spf1 redirect=_spf.example.com", "_spf": "v=spf1 ip4:192.0.2.0/24 ~all" })
+    if f, err := r.FlattenSpf("example.com", "@"); err != nil || f.Flattened != "v=spf1 ip4:192.0.2.0/24 ~all" {
+        t.Errorf("redirect flattened %+v error %v", f, err)
+    }
+    r = spfTestResolver(map[string]string{ "@": "v=spf1 include:_spf.example.com -all", "_spf": "v=spf1 include:example.com -all" })
+    if _, err := r.FlattenSpf("example.com", "@"); err == nil || !strings.Contains(err.Error(), "loop") {
+        t.Errorf("include loop error %v", err)
+    }
+}
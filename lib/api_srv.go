@@ -0,0 +1,301 @@
+package a24apiclient
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+//
+// SRV records (RFC 2782) are published at _<service>._<proto>.<name>; clients try targets in order of priority
+// (lowest first) and pick among targets of the same priority randomly in proportion to their weight. Target must
+// be a host name with address records, not an alias (CNAME); "." alone means service is not available.
+
+// T_SrvService is well-known service with its default port
+type T_SrvService struct {
+    Service         string                `json:"service"`
+    Proto           string                `json:"proto"`
+    Port            int                   `json:"port"`
+    Description     string                `json:"description"`
+}
+
+// T_SrvTarget is one member of SRV rrset
+type T_SrvTarget struct {
+    Target          string
+    Port            int
+    Priority        int
+    Weight          int
+    weightSet       bool
+}
+
+// T_SrvEntry is SRV record with share of traffic within its priority
+type T_SrvEntry struct {
+    Domain          string                `json:"domain"`
+    Name            string                `json:"name"`
+    Service         string                `json:"service"`
+    Proto           string                `json:"proto"`
+    Priority        int                   `json:"priority"`
+    Weight          int                   `json:"weight"`
+    Share           float64               `json:"share"`
+    Port            int                   `json:"port"`
+    Target          string                `json:"target"`
+    Ttl             string                `json:"ttl"`
+}
+
+const (
+    C_Srv_Priority = 10
+    C_Srv_Weight = 10
+    C_Srv_Ttl = "3600"
+    C_Srv_NoService = "."
+)
+
+// well-known services, first entry of service gives its default proto
+var C_Srv_Services = []T_SrvService {
+    { Service: "sip", Proto: "udp", Port: 5060, Description: "SIP" },
+    { Service: "sip", Proto: "tcp", Port: 5060, Description: "SIP over TCP" },
+    { Service: "sip", Proto: "tls", Port: 5061, Description: "SIP over TLS" },
+    { Service: "sips", Proto: "tcp", Port: 5061, Description: "SIP over TLS" },
+    { Service: "sipfederationtls", Proto: "tcp", Port: 5061, Description: "Skype for Business federation" },
+    { Service: "xmpp-client", Proto: "tcp", Port: 5222, Description: "XMPP client" },
+    { Service: "xmpps-client", Proto: "tcp", Port: 5223, Description: "XMPP client over TLS" },
+    { Service: "xmpp-server", Proto: "tcp", Port: 5269, Description: "XMPP server" },
+    { Service: "xmpps-server", Proto: "tcp", Port: 5270, Description: "XMPP server over TLS" },
+    { Service: "imap", Proto: "tcp", Port: 143, Description: "IMAP (RFC 6186)" },
+    { Service: "imaps", Proto: "tcp", Port: 993, Description: "IMAP over TLS (RFC 6186)" },
+    { Service: "pop3", Proto: "tcp", Port: 110, Description: "POP3 (RFC 6186)" },
+    { Service: "pop3s", Proto: "tcp", Port: 995, Description: "POP3 over TLS (RFC 6186)" },
+    { Service: "submission", Proto: "tcp", Port: 587, Description: "mail submission (RFC 6186)" },
+    { Service: "submissions", Proto: "tcp", Port: 465, Description: "mail submission over TLS (RFC 8314)" },
+    { Service: "autodiscover", Proto: "tcp", Port: 443, Description: "Exchange autodiscover" },
+    { Service: "caldav", Proto: "tcp", Port: 80, Description: "CalDAV (RFC 6764)" },
+    { Service: "caldavs", Proto: "tcp", Port: 443, Description: "CalDAV over TLS (RFC 6764)" },
+    { Service: "carddav", Proto: "tcp", Port: 80, Description: "CardDAV (RFC 6764)" },
+    { Service: "carddavs", Proto: "tcp", Port: 443, Description: "CardDAV over TLS (RFC 6764)" },
+    { Service: "ldap", Proto: "tcp", Port: 389, Description: "LDAP" },
+    { Service: "ldaps", Proto: "tcp", Port: 636, Description: "LDAP over TLS" },
+    { Service: "kerberos", Proto: "udp", Port: 88, Description: "Kerberos KDC" },
+    { Service: "kerberos", Proto: "tcp", Port: 88, Description: "Kerberos KDC over TCP" },
+    { Service: "kpasswd", Proto: "udp", Port: 464, Description: "Kerberos password change" },
+    { Service: "stun", Proto: "udp", Port: 3478, Description: "STUN" },
+    { Service: "turn", Proto: "udp", Port: 3478, Description: "TURN" },
+    { Service: "turns", Proto: "tcp", Port: 5349, Description: "TURN over TLS" },
+    { Service: "matrix-fed", Proto: "tcp", Port: 8448, Description: "Matrix federation" },
+    { Service: "minecraft", Proto: "tcp", Port: 25565, Description: "Minecraft server" },
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Service
+// --------------------------------------------------------------------------------------------------------------------
+
+// SrvService returns well-known service of proto (default proto of service when empty), Port is 0 for unknown
+// services
+func SrvService(service, proto string) T_SrvService {
+    lService := strings.ToLower(strings.TrimPrefix(service, "_"))
+    lProto := strings.ToLower(strings.TrimPrefix(proto, "_"))
+    for _, element := range C_Srv_Services {
+        if element.Service == lService && (lProto == "" || element.Proto == lProto) {
+            return element
+        }
+    }
+    if lProto == "" {
+        lProto = "tcp"
+    }
+    return T_SrvService{ Service: lService, Proto: lProto }
+}
+
+// SrvName returns owner name _<service>._<proto>.<name> relative to domain
+func SrvName(domain, name, service, proto string) (string, error) {
+    lName, err := NormaliseDnsName(name, domain)
+    if err != nil {
+        return "", err
+    }
+    lPrefix := "_" + strings.ToLower(strings.TrimPrefix(service, "_")) + "._" + strings.ToLower(strings.TrimPrefix(proto, "_"))
+    if lMessage := validateDnsName(lPrefix, false); lMessage != "" || service == "" || proto == "" {
+        return "", NewA24ApiClientError(fmt.Sprintf("Error: Invalid service %s or proto %s.", service, proto))
+    }
+    if lName == C_DnsName_Apex {
+        return lPrefix, nil
+    }
+    return lPrefix + "." + lName, nil
+}
+
+// ParseSrvTarget parses target "<host>[:<port>][/<priority>[/<weight>]]", missing values are taken from defaults
+func ParseSrvTarget(spec string, port, priority int) (T_SrvTarget, error) {
+    lParts := strings.Split(strings.TrimSpace(spec), "/")
+    t := T_SrvTarget{ Target: lParts[0], Port: port, Priority: priority }
+    if index := strings.LastIndex(t.Target, ":"); index >= 0 {
+        lPort, err := strconv.Atoi(t.Target[index + 1:])
+        if err != nil || lPort < 1 || lPort > 65535 {
+            return t, NewA24ApiClientError(fmt.Sprintf("Error: Invalid port in target %s.", spec))
+        }
+        t.Target, t.Port = t.Target[:index], lPort
+    }
+    if len(lParts) > 3 {
+        return t, NewA24ApiClientError(fmt.Sprintf("Error: Invalid target %s (expected <host>[:<port>][/<priority>[/<weight>]]).", spec))
+    }
+    for index, lValue := range lParts[1:] {
+        lNumber, err := strconv.Atoi(lValue)
+        if err != nil || lNumber < 0 || lNumber > 65535 {
+            return t, NewA24ApiClientError(fmt.Sprintf("Error: Invalid priority or weight in target %s.", spec))
+        }
+        if index == 0 {
+            t.Priority = lNumber
+        } else {
+            t.Weight, t.weightSet = lNumber, true
+        }
+    }
+    if t.Target == C_Srv_NoService {
+        return T_SrvTarget{ Target: C_Srv_NoService, weightSet: true }, nil
+    }
+    if lMessage := validateDnsHostname(t.Target, false); lMessage != "" {
+        return t, NewA24ApiClientError(fmt.Sprintf("Error: Target %s %s.", t.Target, lMessage))
+    }
+    if t.Port == 0 {
+        return t, NewA24ApiClientError(fmt.Sprintf("Error: Port of target %s not known, use --port or <host>:<port>.", t.Target))
+    }
+    t.Target = DnsNameAbsolute(t.Target, "")
+    return t, nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Set
+// --------------------------------------------------------------------------------------------------------------------
+
+// NewSrvRecords returns SRV rrset of targets; targets without weight get 0 when alone in their priority and equal
+// weight otherwise
+func NewSrvRecords(domain, name, ttl string, targets []T_SrvTarget) ([]map[string]string, error) {
+    if len(targets) == 0 {
+        return nil, NewA24ApiClientError("Error: No target given (--target).")
+    }
+    lCounts := make(map[int]int)
+    lSeen := make(map[string]bool)
+    for _, t := range targets {
+        if t.Target == C_Srv_NoService && len(targets) > 1 {
+            return nil, NewA24ApiClientError("Error: Target . (service not available) must be the only target.")
+        }
+        lKey := fmt.Sprintf("%s:%d", t.Target, t.Port)
+        if lSeen[lKey] {
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Target %s given twice.", lKey))
+        }
+        lSeen[lKey] = true
+        lCounts[t.Priority]++
+    }
+    var lRecords []map[string]string
+    for _, t := range targets {
+        if !t.weightSet {
+            t.Weight = 0
+            if lCounts[t.Priority] > 1 {
+                t.Weight = C_Srv_Weight
+            }
+        }
+        lRecord, err := NewDnsRecord(domain, []string{ "SRV", name, ttl, strconv.Itoa(t.Priority), strconv.Itoa(t.Weight), strconv.Itoa(t.Port), t.Target })
+        if err != nil {
+            return nil, err
+        }
+        lRecords = append(lRecords, lRecord)
+    }
+    return lRecords, nil
+}
+
+// CheckSrvTargets returns errors of targets which are aliases and warnings of targets without address; targets
+// outside account domains are checked only with local resolver
+func (r *T_DnsResolver) CheckSrvTargets(records []map[string]string) ([]T_SpfFinding, error) {
+    lFindings := []T_SpfFinding{}
+    for _, lRecord := range records {
+        lTarget := dnsCanonicalHost(lRecord["Target"])
+        if lTarget == "" {
+            continue
+        }
+        lAliases, err := r.Lookup(lTarget, "CNAME")
+        if _, isUnresolved := err.(*T_DnsResolverError); isUnresolved {
+            lFindings = append(lFindings, T_SpfFinding{ Level: C_SpfLint_Warning, Domain: lTarget, Message: "not checked (use --resolver)" })
+            continue
+        }
+        if err != nil {
+            return nil, err
+        }
+        if len(lAliases) > 0 {
+            lFindings = append(lFindings, T_SpfFinding{ Level: C_SpfLint_Error, Domain: lTarget, Term: "CNAME", Message: fmt.Sprintf("is alias of %s, SRV target must be canonical name", dnsCanonicalHost(lAliases[0]["Alias"])) })
+            continue
+        }
+        lAddresses, err := r.LookupAddresses(lTarget)
+        if err != nil {
+            return nil, err
+        }
+        if len(lAddresses) == 0 {
+            lFindings = append(lFindings, T_SpfFinding{ Level: C_SpfLint_Warning, Domain: lTarget, Message: "has no A or AAAA record" })
+        }
+    }
+    return lFindings, nil
+}
+
+// DnsPlanSrv returns changes making SRV rrset of name equal to records, with findings of their targets
+func (r *T_DnsResolver) DnsPlanSrv(domain, name string, records []map[string]string) ([]T_DnsChange, []T_SpfFinding, error) {
+    lFindings, err := r.CheckSrvTargets(records)
+    if err != nil {
+        return nil, nil, err
+    }
+    _, lExisting, err := r.Client.DnsGetRRset(domain, "SRV", name)
+    if err != nil {
+        return nil, nil, err
+    }
+    lChanges := DnsPlanRRset(lExisting, records)
+    if lChanges == nil {
+        lChanges = []T_DnsChange{}
+    }
+    return lChanges, lFindings, nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// List
+// --------------------------------------------------------------------------------------------------------------------
+
+// DnsSrvEntries returns SRV records of domain ordered by name, priority and weight with share of traffic
+func (c *T_A24ApiClient) DnsSrvEntries(domain string) ([]T_SrvEntry, error) {
+    lDomain, lZone, err := c.DnsZoneRecords(domain)
+    if err != nil {
+        return nil, err
+    }
+    lEntries := []T_SrvEntry{}
+    lTotals := make(map[string]int)
+    lCounts := make(map[string]int)
+    for _, lRecord := range lZone {
+        if lRecord["Type"] != "SRV" {
+            continue
+        }
+        e := T_SrvEntry{ Domain: lDomain, Name: DnsNameRelative(lRecord["Name"], lDomain), Target: lRecord["Target"], Ttl: lRecord["Ttl"] }
+        e.Priority, _ = strconv.Atoi(lRecord["Priority"])
+        e.Weight, _ = strconv.Atoi(lRecord["Weight"])
+        e.Port, _ = strconv.Atoi(lRecord["Port"])
+        if lLabels := strings.SplitN(e.Name, ".", 3); len(lLabels) >= 2 {
+            e.Service, e.Proto = strings.TrimPrefix(lLabels[0], "_"), strings.TrimPrefix(lLabels[1], "_")
+        }
+        lGroup := fmt.Sprintf("%s %d", strings.ToLower(e.Name), e.Priority)
+        lTotals[lGroup] += e.Weight
+        lCounts[lGroup]++
+        lEntries = append(lEntries, e)
+    }
+    // weight 0 everywhere in priority means equal selection
+    for index := range lEntries {
+        lGroup := fmt.Sprintf("%s %d", strings.ToLower(lEntries[index].Name), lEntries[index].Priority)
+        if lTotals[lGroup] > 0 {
+            lEntries[index].Share = float64(lEntries[index].Weight) / float64(lTotals[lGroup])
+        } else {
+            lEntries[index].Share = 1 / float64(lCounts[lGroup])
+        }
+    }
+    sort.SliceStable(lEntries, func(i, j int) bool {
+        if lEntries[i].Name != lEntries[j].Name {
+            return lEntries[i].Name < lEntries[j].Name
+        }
+        if lEntries[i].Priority != lEntries[j].Priority {
+            return lEntries[i].Priority < lEntries[j].Priority
+        }
+        return lEntries[i].Weight > lEntries[j].Weight
+    })
+    return lEntries, nil
+}
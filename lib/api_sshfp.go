@@ -0,0 +1,202 @@
+package a24apiclient
+
+import (
+    "bufio"
+    "bytes"
+    "crypto/sha1"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/binary"
+    "encoding/hex"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+//
+// SSHFP records (RFC 4255, RFC 6594, RFC 7479) carry SHA-1 (fp_type 1) or SHA-256 (fp_type 2) fingerprint of host
+// key blob, i.e. base64-decoded key of OpenSSH public key line.
+
+// T_SshHostKey is public host key read from OpenSSH public key file or ssh-keyscan output
+type T_SshHostKey struct {
+    Type            string
+    Algorithm       int
+    Blob            []byte
+}
+
+// ssh key types with SSHFP algorithm numbers
+var C_Sshfp_Algorithms = map[string]int {
+    "ssh-rsa": 1,
+    "ssh-dss": 2,
+    "ecdsa-sha2-nistp256": 3,
+    "ecdsa-sha2-nistp384": 3,
+    "ecdsa-sha2-nistp521": 3,
+    "ssh-ed25519": 4,
+    "ssh-ed448": 6,
+}
+
+const (
+    C_Sshfp_FingerprintTypes = "2"
+    C_Sshfp_Ttl = "3600"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Parse
+// --------------------------------------------------------------------------------------------------------------------
+
+// ParseSshHostKeys reads public key lines "<type> <base64> [comment]" with optional leading host field of
+// ssh-keyscan and known_hosts ("<host> <type> <base64>"); comments and unsupported key types are skipped, duplicate
+// keys are returned once
+func ParseSshHostKeys(data []byte) ([]T_SshHostKey, error) {
+    var lKeys []T_SshHostKey
+    lSeen := make(map[string]bool)
+    s := bufio.NewScanner(bytes.NewReader(data))
+    s.Buffer(make([]byte, 64 * 1024), 1024 * 1024)
+    lLine := 0
+    for s.Scan() {
+        lLine++
+        lFields := strings.Fields(s.Text())
+        if len(lFields) == 0 || strings.HasPrefix(lFields[0], "#") || strings.HasPrefix(lFields[0], "@") {
+            continue
+        }
+        if _, isKeyType := C_Sshfp_Algorithms[lFields[0]]; !isKeyType && len(lFields) > 1 {
+            lFields = lFields[1:]
+        }
+        lAlgorithm, isKnown := C_Sshfp_Algorithms[lFields[0]]
+        if !isKnown || len(lFields) < 2 {
+            continue
+        }
+        lBlob, err := base64.StdEncoding.DecodeString(lFields[1])
+        if err != nil {
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Line %d: Invalid base64 key data.", lLine))
+        }
+        // key blob starts with its own type name
+        if len(lBlob) < 4 || int(binary.BigEndian.Uint32(lBlob)) + 4 > len(lBlob) || string(lBlob[4:4 + binary.BigEndian.Uint32(lBlob)]) != lFields[0] {
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Line %d: Key data does not match key type %s.", lLine, lFields[0]))
+        }
+        if lSeen[string(lBlob)] {
+            continue
+        }
+        lSeen[string(lBlob)] = true
+        lKeys = append(lKeys, T_SshHostKey{ Type: lFields[0], Algorithm: lAlgorithm, Blob: lBlob })
+    }
+    if err := s.Err(); err != nil {
+        return nil, err
+    }
+    return lKeys, nil
+}
+
+// Fingerprint returns hex fingerprint of key, fpType 1 is SHA-1, 2 SHA-256
+func (k T_SshHostKey) Fingerprint(fpType int) (string, error) {
+    switch fpType {
+        case 1:
+            lHash := sha1.Sum(k.Blob)
+            return hex.EncodeToString(lHash[:]), nil
+        case 2:
+            lHash := sha256.Sum256(k.Blob)
+            return hex.EncodeToString(lHash[:]), nil
+    }
+    return "", NewA24ApiClientError(fmt.Sprintf("Error: Unsupported fingerprint type %d (1 SHA-1, 2 SHA-256).", fpType))
+}
+
+// ParseSshfpFingerprintTypes parses comma separated fingerprint types, e.g. "1,2"
+func ParseSshfpFingerprintTypes(value string) ([]int, error) {
+    var lTypes []int
+    for _, lValue := range strings.Split(value, ",") {
+        lType, err := strconv.Atoi(strings.TrimSpace(lValue))
+        if err != nil || (lType != 1 && lType != 2) {
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Invalid fingerprint type %s (1 SHA-1, 2 SHA-256).", lValue))
+        }
+        lTypes = append(lTypes, lType)
+    }
+    return lTypes, nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Sync
+// --------------------------------------------------------------------------------------------------------------------
+
+// NewSshfpRecords returns SSHFP records of host keys for each fingerprint type, ordered by algorithm and type
+func NewSshfpRecords(domain, name, ttl string, keys []T_SshHostKey, fpTypes []int) ([]map[string]string, error) {
+    if len(keys) == 0 {
+        return nil, NewA24ApiClientError("Error: No supported host keys found.")
+    }
+    lKeys := make([]T_SshHostKey, len(keys))
+    copy(lKeys, keys)
+    sort.SliceStable(lKeys, func(i, j int) bool {
+        return lKeys[i].Algorithm < lKeys[j].Algorithm
+    })
+    var lRecords []map[string]string
+    for _, lKey := range lKeys {
+        for _, lType := range fpTypes {
+            lFingerprint, err := lKey.Fingerprint(lType)
+            if err != nil {
+                return nil, err
+            }
+            lRecord, err := NewDnsRecord(domain, []string{ "SSHFP", name, ttl, strconv.Itoa(lKey.Algorithm), strconv.Itoa(lType), lFingerprint })
+            if err != nil {
+                return nil, err
+            }
+            lRecords = append(lRecords, lRecord)
+        }
+    }
+    return lRecords, nil
+}
+
+// DnsPlanSshfp returns changes making SSHFP rrset of name equal to records: new keys are added, stale ones deleted
+// and records of kept keys updated when ttl differs
+func (c *T_A24ApiClient) DnsPlanSshfp(domain, name string, records []map[string]string) ([]T_DnsChange, error) {
+    _, lExisting, err := c.DnsGetRRset(domain, "SSHFP", name)
+    if err != nil {
+        return nil, err
+    }
+    return dnsPlanSshfpRRset(lExisting, records), nil
+}
+
+// dnsPlanSshfpRRset plans SSHFP rrset keyed on algorithm, fingerprint type and fingerprint, as RFC 4255 allows
+// several keys per algorithm (e.g. ECDSA keys of different curves): records with the same key are kept or updated
+// when ttl differs, new keys are created and stale ones deleted
+func dnsPlanSshfpRRset(existing, desired []map[string]string) []T_DnsChange {
+    lChanges := []T_DnsChange{}
+    // first existing record of each key, duplicates are deleted
+    lIndex := make(map[string]int)
+    for index, lRecord := range existing {
+        if _, isPresent := lIndex[sshfpRecordKey(lRecord)]; !isPresent {
+            lIndex[sshfpRecordKey(lRecord)] = index
+        }
+    }
+    lUsed := make(map[int]bool)
+    lSeen := make(map[string]bool)
+    for _, lRecord := range desired {
+        lKey := sshfpRecordKey(lRecord)
+        if lSeen[lKey] {
+            continue
+        }
+        lSeen[lKey] = true
+        index, isPresent := lIndex[lKey]
+        if !isPresent {
+            lChanges = append(lChanges, T_DnsChange{ Action: "create", Record: lRecord })
+            continue
+        }
+        lUsed[index] = true
+        if !dnsRecordFieldEqual(true, existing[index]["Ttl"], lRecord["Ttl"]) {
+            lChanges = append(lChanges, dnsUpdateChange(existing[index], lRecord))
+        }
+    }
+    for index, lRecord := range existing {
+        if !lUsed[index] {
+            lChanges = append(lChanges, T_DnsChange{ Action: "delete", Record: lRecord })
+        }
+    }
+    return lChanges
+}
+
+// sshfpRecordKey returns algorithm, fingerprint type and fingerprint of SSHFP record in canonical form
+func sshfpRecordKey(record map[string]string) string {
+    r := NormaliseDnsRecord(record, "")
+    return r["Algorithm"] + " " + r["FingerprintType"] + " " + r["Text"]
+}
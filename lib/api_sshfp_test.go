@@ -0,0 +1,46 @@
+package a24apiclient
+
+import (
+    "strings"
+    "testing"
+)
+
+func sshfpTestRecord(hashId, ttl, algorithm, fingerprint string) map[string]string {
+    return map[string]string{ "Domain": "example.com", "HashId": hashId, "Type": "SSHFP", "Name": "host", "Ttl": ttl, "Algorithm": algorithm, "FingerprintType": "2", "Text": fingerprint }
+}
+
+func TestDnsPlanSshfpRRset(t *testing.T) {
+    lP256 := "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+    lP384 := "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+    lRsa := "cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc"
+    lOld := "dddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddd"
+    lExisting := []map[string]string{
+        sshfpTestRecord("h1", "3600", "3", lP384),
+        sshfpTestRecord("h2", "3600", "3", strings.ToUpper(lP256)),
+        sshfpTestRecord("h3", "3600", "1", lOld),
+        sshfpTestRecord("h4", "300", "1", lRsa),
+    }
+    // two ECDSA keys share algorithm 3 and fingerprint type 2
+    lDesired := []map[string]string{
+        sshfpTestRecord("", "3600", "1", lRsa),
+        sshfpTestRecord("", "3600", "3", lP256),
+        sshfpTestRecord("", "3600", "3", lP384),
+        sshfpTestRecord("", "3600", "4", lOld),
+    }
+    lChanges := dnsPlanSshfpRRset(lExisting, lDesired)
+    lWant := []string{ "update h4", "create ", "delete h3" }
+    if len(lChanges) != len(lWant) {
+        t.Fatalf("changes %+v", lChanges)
+    }
+    for index, lChange := range lChanges {
+        if lChange.Action + " " + lChange.Record["HashId"] != lWant[index] {
+            t.Errorf("change %d is %s %s, want %s", index, lChange.Action, lChange.Record["HashId"], lWant[index])
+        }
+    }
+    if lChanges[1].Record["Algorithm"] != "4" {
+        t.Errorf("created record %v", lChanges[1].Record)
+    }
+    if lChanges := dnsPlanSshfpRRset(lExisting[:2], lDesired[1:3]); len(lChanges) != 0 {
+        t.Errorf("changes of equal rrset %+v", lChanges)
+    }
+}
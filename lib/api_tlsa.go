@@ -0,0 +1,320 @@
+package a24apiclient
+
+import (
+    "crypto"
+    "crypto/sha256"
+    "crypto/sha512"
+    "crypto/tls"
+    "crypto/x509"
+    "encoding/hex"
+    "encoding/pem"
+    "fmt"
+    "io/ioutil"
+    "net"
+    "net/smtp"
+    "strconv"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Type
+// --------------------------------------------------------------------------------------------------------------------
+//
+// TLSA records (RFC 6698, RFC 7671) are published at _<port>._<proto>.<name>. Usage 1 (PKIX-EE) and 3 (DANE-EE)
+// match the server certificate, usage 0 (PKIX-TA) and 2 (DANE-TA) the last certificate of the chain (trust anchor).
+// Selector 0 hashes the whole certificate, 1 its public key (SubjectPublicKeyInfo); matching type 0 is full data,
+// 1 SHA-256 and 2 SHA-512.
+
+// T_TlsaParams is usage, selector and matching type of TLSA record
+type T_TlsaParams struct {
+    Usage           int                   `json:"usage"`
+    Selector        int                   `json:"selector"`
+    MatchingType    int                   `json:"matchingType"`
+}
+
+// T_TlsaSource is certificate chain (server certificate first) or public key alone
+type T_TlsaSource struct {
+    Certificates    []*x509.Certificate
+    PublicKey       []byte
+}
+
+// T_TlsaCheck is published TLSA record compared to source
+type T_TlsaCheck struct {
+    Name            string                `json:"name"`
+    Usage           string                `json:"usage"`
+    Selector        string                `json:"selector"`
+    MatchingType    string                `json:"matchingType"`
+    Hash            string                `json:"hash"`
+    Expected        string                `json:"expected,omitempty"`
+    Match           bool                  `json:"match"`
+    Error           string                `json:"error,omitempty"`
+}
+
+const (
+    C_Tlsa_Params = "3 1 1"
+    C_Tlsa_Proto = "tcp"
+    C_Tlsa_Ttl = "3600"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Source
+// --------------------------------------------------------------------------------------------------------------------
+
+// LoadTlsaSource reads PEM file with certificate or chain, public key or private key (its public key is used)
+func LoadTlsaSource(path string) (*T_TlsaSource, error) {
+    lData, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    s := &T_TlsaSource{}
+    for {
+        var lBlock *pem.Block
+        lBlock, lData = pem.Decode(lData)
+        if lBlock == nil {
+            break
+        }
+        switch lBlock.Type {
+            case "CERTIFICATE":
+                lCertificate, err := x509.ParseCertificate(lBlock.Bytes)
+                if err != nil {
+                    return nil, err
+                }
+                s.Certificates = append(s.Certificates, lCertificate)
+            case "PUBLIC KEY":
+                if _, err := x509.ParsePKIXPublicKey(lBlock.Bytes); err != nil {
+                    return nil, err
+                }
+                s.PublicKey = lBlock.Bytes
+            case "RSA PUBLIC KEY":
+                lKey, err := x509.ParsePKCS1PublicKey(lBlock.Bytes)
+                if err != nil {
+                    return nil, err
+                }
+                if s.PublicKey, err = x509.MarshalPKIXPublicKey(lKey); err != nil {
+                    return nil, err
+                }
+            case "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY":
+                lKey, err := tlsaParsePrivateKey(lBlock)
+                if err != nil {
+                    return nil, err
+                }
+                if s.PublicKey, err = x509.MarshalPKIXPublicKey(lKey.Public()); err != nil {
+                    return nil, err
+                }
+        }
+    }
+    if len(s.Certificates) == 0 && s.PublicKey == nil {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: No certificate or key found in %s.", path))
+    }
+    if len(s.Certificates) > 0 && s.PublicKey == nil {
+        s.PublicKey = s.Certificates[0].RawSubjectPublicKeyInfo
+    }
+    return s, nil
+}
+
+func tlsaParsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+    var lKey interface{}
+    var err error
+    switch block.Type {
+        case "RSA PRIVATE KEY":
+            lKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+        case "EC PRIVATE KEY":
+            lKey, err = x509.ParseECPrivateKey(block.Bytes)
+        default:
+            lKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+    }
+    if err != nil {
+        return nil, err
+    }
+    lSigner, isSigner := lKey.(crypto.Signer)
+    if !isSigner {
+        return nil, NewA24ApiClientError("Error: Unsupported private key type.")
+    }
+    return lSigner, nil
+}
+
+// FetchTlsaSource connects to TLS endpoint (host:port) and returns certificate chain it presents, starttls smtp
+// upgrades plain SMTP connection first. Chain is not verified, it is only read.
+func FetchTlsaSource(address, starttls string) (*T_TlsaSource, error) {
+    lHost, _, err := net.SplitHostPort(address)
+    if err != nil {
+        return nil, err
+    }
+    lConfig := &tls.Config{ ServerName: lHost, InsecureSkipVerify: true }
+    var lState tls.ConnectionState
+    switch starttls {
+        case "":
+            lConn, err := tls.DialWithDialer(&net.Dialer{ Timeout: C_DnsResolver_Timeout }, "tcp", address, lConfig)
+            if err != nil {
+                return nil, err
+            }
+            defer lConn.Close()
+            lState = lConn.ConnectionState()
+        case "smtp":
+            lConn, err := net.DialTimeout("tcp", address, C_DnsResolver_Timeout)
+            if err != nil {
+                return nil, err
+            }
+            lClient, err := smtp.NewClient(lConn, lHost)
+            if err != nil {
+                lConn.Close()
+                return nil, err
+            }
+            defer lClient.Close()
+            if err := lClient.StartTLS(lConfig); err != nil {
+                return nil, err
+            }
+            lState, _ = lClient.TLSConnectionState()
+        default:
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Unsupported starttls protocol %s (smtp).", starttls))
+    }
+    if len(lState.PeerCertificates) == 0 {
+        return nil, NewA24ApiClientError(fmt.Sprintf("Error: %s presented no certificate.", address))
+    }
+    return &T_TlsaSource{ Certificates: lState.PeerCertificates, PublicKey: lState.PeerCertificates[0].RawSubjectPublicKeyInfo }, nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Generate
+// --------------------------------------------------------------------------------------------------------------------
+
+// ParseTlsaParams parses comma separated "usage selector matching_type" triples, e.g. "3 1 1,2 1 1"
+func ParseTlsaParams(value string) ([]T_TlsaParams, error) {
+    var lParams []T_TlsaParams
+    for _, lTriple := range strings.Split(value, ",") {
+        lFields := strings.Fields(lTriple)
+        if len(lFields) != 3 {
+            return nil, NewA24ApiClientError(fmt.Sprintf("Error: Invalid tlsa parameters %s (expected usage selector matching_type).", lTriple))
+        }
+        var lNumbers [3]int
+        for index, lField := range lFields {
+            lNumber, err := strconv.Atoi(lField)
+            if err != nil || lNumber < 0 || lNumber > []int{ 3, 1, 2 }[index] {
+                return nil, NewA24ApiClientError(fmt.Sprintf("Error: Invalid tlsa parameters %s (usage 0-3, selector 0-1, matching_type 0-2).", lTriple))
+            }
+            lNumbers[index] = lNumber
+        }
+        lParams = append(lParams, T_TlsaParams{ Usage: lNumbers[0], Selector: lNumbers[1], MatchingType: lNumbers[2] })
+    }
+    return lParams, nil
+}
+
+// TlsaData returns hex association data of source for params
+func TlsaData(source *T_TlsaSource, params T_TlsaParams) (string, error) {
+    var lData []byte
+    switch {
+        case params.Usage == 0 || params.Usage == 2:
+            if len(source.Certificates) < 2 {
+                return "", NewA24ApiClientError(fmt.Sprintf("Error: Usage %d needs certificate chain with trust anchor.", params.Usage))
+            }
+            lAnchor := source.Certificates[len(source.Certificates) - 1]
+            lData = lAnchor.Raw
+            if params.Selector == 1 {
+                lData = lAnchor.RawSubjectPublicKeyInfo
+            }
+        case params.Selector == 0:
+            if len(source.Certificates) == 0 {
+                return "", NewA24ApiClientError("Error: Selector 0 needs certificate, public key allows selector 1 only.")
+            }
+            lData = source.Certificates[0].Raw
+        default:
+            lData = source.PublicKey
+    }
+    switch params.MatchingType {
+        case 1:
+            lHash := sha256.Sum256(lData)
+            lData = lHash[:]
+        case 2:
+            lHash := sha512.Sum512(lData)
+            lData = lHash[:]
+    }
+    return hex.EncodeToString(lData), nil
+}
+
+// TlsaName returns owner name _<port>._<proto>.<name> relative to domain
+func TlsaName(domain, name, port, proto string) (string, error) {
+    lName, err := NormaliseDnsName(name, domain)
+    if err != nil {
+        return "", err
+    }
+    if lPort, err := strconv.Atoi(port); err != nil || lPort < 1 || lPort > 65535 {
+        return "", NewA24ApiClientError(fmt.Sprintf("Error: Invalid port %s.", port))
+    }
+    lPrefix := "_" + port + "._" + strings.ToLower(strings.TrimPrefix(proto, "_"))
+    if lName == C_DnsName_Apex {
+        return lPrefix, nil
+    }
+    return lPrefix + "." + lName, nil
+}
+
+// NewTlsaRecords returns TLSA records of source for each params
+func NewTlsaRecords(domain, name, ttl string, source *T_TlsaSource, params []T_TlsaParams) ([]map[string]string, error) {
+    var lRecords []map[string]string
+    for _, lParams := range params {
+        lHash, err := TlsaData(source, lParams)
+        if err != nil {
+            return nil, err
+        }
+        lRecord, err := NewDnsRecord(domain, []string{ "TLSA", name, ttl, strconv.Itoa(lParams.Usage), strconv.Itoa(lParams.Selector), strconv.Itoa(lParams.MatchingType), lHash })
+        if err != nil {
+            return nil, err
+        }
+        lRecords = append(lRecords, lRecord)
+    }
+    return lRecords, nil
+}
+
+// DnsPlanTlsa returns changes publishing records at name: with replace the rrset becomes exactly records, otherwise
+// records are added and other published records are kept (e.g. next key during rollover) and returned
+func (c *T_A24ApiClient) DnsPlanTlsa(domain, name string, records []map[string]string, replace bool) ([]T_DnsChange, []map[string]string, error) {
+    lDomain, err := DnsIdnToAscii(domain)
+    if err != nil {
+        return nil, nil, err
+    }
+    _, lExisting, err := c.DnsGetRRset(lDomain, "TLSA", name)
+    if err != nil {
+        return nil, nil, err
+    }
+    if replace {
+        lChanges := DnsPlanRRset(lExisting, records)
+        if lChanges == nil {
+            lChanges = []T_DnsChange{}
+        }
+        return lChanges, nil, nil
+    }
+    lChanges, lKept := DnsPlanUpsert(lExisting, records, lDomain)
+    return lChanges, lKept, nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+// Check
+// --------------------------------------------------------------------------------------------------------------------
+
+// CheckTlsa compares published TLSA records with data computed from source
+func CheckTlsa(records []map[string]string, source *T_TlsaSource) []T_TlsaCheck {
+    lChecks := []T_TlsaCheck{}
+    for _, lRecord := range records {
+        t := T_TlsaCheck{ Name: lRecord["Name"], Usage: lRecord["CertificateUsage"], Selector: lRecord["Selector"], MatchingType: lRecord["MatchingType"], Hash: strings.ToLower(lRecord["Hash"]) }
+        lParams, err := ParseTlsaParams(strings.Join([]string{ t.Usage, t.Selector, t.MatchingType }, " "))
+        if err == nil {
+            t.Expected, err = TlsaData(source, lParams[0])
+        }
+        if err != nil {
+            t.Error = strings.TrimSuffix(strings.TrimPrefix(err.Error(), "Error: "), ".")
+        }
+        t.Match = err == nil && t.Expected == t.Hash
+        lChecks = append(lChecks, t)
+    }
+    return lChecks
+}
+
+// TlsaMatches returns number of matching checks, DANE succeeds when at least one record matches
+func TlsaMatches(checks []T_TlsaCheck) int {
+    lCount := 0
+    for _, lCheck := range checks {
+        if lCheck.Match {
+            lCount++
+        }
+    }
+    return lCount
+}
@@ -0,0 +1,63 @@
+package a24apiclient
+
+import (
+    "sync"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Worker pool
+// --------------------------------------------------------------------------------------------------------------------
+//
+// Jobs are identified by index, so callers keep results in a slice and get them in stable (input) order regardless
+// of completion order. Api requests made by jobs still pass through the client rate limiter.
+
+// RunWorkerPool runs job for indexes 0..count-1 using at most concurrency goroutines. When stopOnError is set,
+// jobs not yet started after the first failure are skipped. Returned slice tells which jobs were started.
+func RunWorkerPool(count, concurrency int, stopOnError bool, job func(index int) error) []bool {
+    if concurrency < 1 {
+        concurrency = 1
+    }
+    lStarted := make([]bool, count)
+    lIndexes := make(chan int)
+    var lFailed bool
+    var lMutex sync.Mutex
+    var lWait sync.WaitGroup
+
+    for worker := 0; worker < concurrency && worker < count; worker++ {
+        lWait.Add(1)
+        go func() {
+            defer lWait.Done()
+            for index := range lIndexes {
+                // failure is checked once the worker is free, so job queued behind failed one is not run
+                lMutex.Lock()
+                lStop := stopOnError && lFailed
+                if !lStop {
+                    lStarted[index] = true
+                }
+                lMutex.Unlock()
+                if lStop {
+                    continue
+                }
+                if err := job(index); err != nil {
+                    lMutex.Lock()
+                    lFailed = true
+                    lMutex.Unlock()
+                }
+            }
+        }()
+    }
+
+    for index := 0; index < count; index++ {
+        lMutex.Lock()
+        lStop := stopOnError && lFailed
+        lMutex.Unlock()
+        if lStop {
+            break
+        }
+        lIndexes <- index
+    }
+    close(lIndexes)
+    lWait.Wait()
+
+    return lStarted
+}
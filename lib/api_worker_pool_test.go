@@ -0,0 +1,51 @@
+package a24apiclient
+
+import (
+    "fmt"
+    "sync"
+    "testing"
+)
+
+func TestRunWorkerPool(t *testing.T) {
+    lTests := []struct {
+        name            string
+        count           int
+        concurrency     int
+        stopOnError     bool
+        failing         int
+        started         []bool
+    }{
+        { "all jobs run", 4, 2, false, -1, []bool{ true, true, true, true } },
+        { "failure without stop", 3, 1, false, 0, []bool{ true, true, true } },
+        { "stop after first job", 4, 1, true, 0, []bool{ true, false, false, false } },
+        { "stop after middle job", 4, 1, true, 1, []bool{ true, true, false, false } },
+        { "stop on last job", 3, 1, true, 2, []bool{ true, true, true } },
+        { "zero concurrency is one worker", 3, 0, true, 0, []bool{ true, false, false } },
+        { "no jobs", 0, 4, true, -1, []bool{} },
+    }
+    for _, lTest := range lTests {
+        var lMutex sync.Mutex
+        lRan := make([]bool, lTest.count)
+        lStarted := RunWorkerPool(lTest.count, lTest.concurrency, lTest.stopOnError, func(index int) error {
+            lMutex.Lock()
+            lRan[index] = true
+            lMutex.Unlock()
+            if index == lTest.failing {
+                return fmt.Errorf("job %d failed", index)
+            }
+            return nil
+        })
+        if len(lStarted) != len(lTest.started) {
+            t.Errorf("%s: got %d results, want %d", lTest.name, len(lStarted), len(lTest.started))
+            continue
+        }
+        for index := range lStarted {
+            if lStarted[index] != lTest.started[index] {
+                t.Errorf("%s: job %d started %v, want %v", lTest.name, index, lStarted[index], lTest.started[index])
+            }
+            if lRan[index] != lStarted[index] {
+                t.Errorf("%s: job %d ran %v but reported started %v", lTest.name, index, lRan[index], lStarted[index])
+            }
+        }
+    }
+}
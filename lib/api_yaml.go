@@ -0,0 +1,231 @@
+package a24apiclient
+
+import (
+    "fmt"
+    "strings"
+)
+
+// --------------------------------------------------------------------------------------------------------------------
+// Yaml
+// --------------------------------------------------------------------------------------------------------------------
+//
+// ParseYaml reads the block subset of yaml used by preset files: nested mappings and sequences by indentation
+// (spaces only), plain, single- and double-quoted scalars, empty [] and {} and # comments. Mappings are returned as
+// map[string]interface{}, sequences as []interface{} and scalars as string. Anchors, tags, flow collections and
+// multi-line scalars are not supported.
+
+type t_yamlLine struct {
+    Number          int
+    Indent          int
+    Text            string
+}
+
+func ParseYaml(data []byte) (interface{}, error) {
+    var lLines []t_yamlLine
+    for index, lLine := range strings.Split(strings.Replace(string(data), "\r\n", "\n", -1), "\n") {
+        if strings.HasPrefix(strings.TrimSpace(lLine), "---") && len(lLines) == 0 {
+            continue
+        }
+        lText := strings.TrimRight(yamlStripComment(lLine), " \t")
+        if strings.TrimSpace(lText) == "" {
+            continue
+        }
+        lIndent := len(lText) - len(strings.TrimLeft(lText, " "))
+        if strings.HasPrefix(lText[lIndent:], "\t") {
+            return nil, yamlError(index + 1, "tabs are not allowed in indentation")
+        }
+        lLines = append(lLines, t_yamlLine{ Number: index + 1, Indent: lIndent, Text: lText[lIndent:] })
+    }
+    if len(lLines) == 0 {
+        return map[string]interface{}{}, nil
+    }
+    lValue, lNext, err := yamlParseBlock(lLines, 0, lLines[0].Indent)
+    if err != nil {
+        return nil, err
+    }
+    if lNext < len(lLines) {
+        return nil, yamlError(lLines[lNext].Number, "unexpected indentation")
+    }
+    return lValue, nil
+}
+
+func yamlError(line int, message string) error {
+    return NewA24ApiClientError(fmt.Sprintf("Error: Yaml line %d: %s.", line, message))
+}
+
+// yamlParseBlock parses mapping, sequence or scalar starting at line index with given indentation
+func yamlParseBlock(lines []t_yamlLine, index, indent int) (interface{}, int, error) {
+    if yamlIsItem(lines[index].Text) {
+        return yamlParseSequence(lines, index, indent)
+    }
+    if _, _, isPair := yamlSplitPair(lines[index].Text); isPair {
+        return yamlParseMapping(lines, index, indent)
+    }
+    lValue, err := yamlScalar(lines[index].Text)
+    if err != nil {
+        return nil, 0, yamlError(lines[index].Number, err.Error())
+    }
+    return lValue, index + 1, nil
+}
+
+func yamlParseSequence(lines []t_yamlLine, index, indent int) (interface{}, int, error) {
+    lList := []interface{}{}
+    for index < len(lines) && lines[index].Indent == indent && yamlIsItem(lines[index].Text) {
+        lContent := strings.TrimLeft(strings.TrimPrefix(lines[index].Text, "-"), " ")
+        if lContent == "" {
+            if index + 1 < len(lines) && lines[index + 1].Indent > indent {
+                lValue, lNext, err := yamlParseBlock(lines, index + 1, lines[index + 1].Indent)
+                if err != nil {
+                    return nil, 0, err
+                }
+                lList = append(lList, lValue)
+                index = lNext
+            } else {
+                lList = append(lList, "")
+                index++
+            }
+            continue
+        }
+        // content after "- " is parsed as block indented to its column, following lines of the item align with it
+        lItem := make([]t_yamlLine, len(lines))
+        copy(lItem, lines)
+        lItem[index] = t_yamlLine{ Number: lines[index].Number, Indent: indent + len(lines[index].Text) - len(lContent), Text: lContent }
+        lValue, lNext, err := yamlParseBlock(lItem, index, lItem[index].Indent)
+        if err != nil {
+            return nil, 0, err
+        }
+        lList = append(lList, lValue)
+        index = lNext
+    }
+    return lList, index, nil
+}
+
+func yamlParseMapping(lines []t_yamlLine, index, indent int) (interface{}, int, error) {
+    lMap := make(map[string]interface{})
+    for index < len(lines) && lines[index].Indent == indent {
+        lKey, lRaw, isPair := yamlSplitPair(lines[index].Text)
+        if !isPair {
+            return nil, 0, yamlError(lines[index].Number, "expected key: value")
+        }
+        lKey, err := yamlScalar(lKey)
+        if err != nil {
+            return nil, 0, yamlError(lines[index].Number, err.Error())
+        }
+        if _, isPresent := lMap[lKey]; isPresent {
+            return nil, 0, yamlError(lines[index].Number, fmt.Sprintf("duplicate key %s", lKey))
+        }
+        if lRaw != "" {
+            lValue, err := yamlScalar(lRaw)
+            if err != nil {
+                return nil, 0, yamlError(lines[index].Number, err.Error())
+            }
+            if lRaw == "[]" {
+                lMap[lKey] = []interface{}{}
+            } else if lRaw == "{}" {
+                lMap[lKey] = map[string]interface{}{}
+            } else {
+                lMap[lKey] = lValue
+            }
+            index++
+            continue
+        }
+        index++
+        // nested block is indented deeper, sequence may also start at the same indentation
+        if index < len(lines) && (lines[index].Indent > indent || (lines[index].Indent == indent && yamlIsItem(lines[index].Text))) {
+            lValue, lNext, err := yamlParseBlock(lines, index, lines[index].Indent)
+            if err != nil {
+                return nil, 0, err
+            }
+            lMap[lKey] = lValue
+            index = lNext
+            continue
+        }
+        lMap[lKey] = ""
+    }
+    if index < len(lines) && lines[index].Indent > indent {
+        return nil, 0, yamlError(lines[index].Number, "unexpected indentation")
+    }
+    return lMap, index, nil
+}
+
+func yamlIsItem(text string) bool {
+    return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// yamlSplitPair splits "key: value" outside quotes
+func yamlSplitPair(text string) (string, string, bool) {
+    lQuote := byte(0)
+    for index := 0; index < len(text); index++ {
+        switch {
+            case lQuote != 0:
+                if text[index] == '\\' && lQuote == '"' {
+                    index++
+                } else if text[index] == lQuote {
+                    lQuote = 0
+                }
+            case (text[index] == '"' || text[index] == '\'') && index == 0:
+                lQuote = text[index]
+            case text[index] == ':' && (index + 1 == len(text) || text[index + 1] == ' '):
+                return strings.TrimSpace(text[:index]), strings.TrimSpace(text[index + 1:]), true
+        }
+    }
+    return "", "", false
+}
+
+// yamlScalar returns value of plain or quoted scalar
+func yamlScalar(text string) (string, error) {
+    switch {
+        case strings.HasPrefix(text, "\""):
+            if len(text) < 2 || !strings.HasSuffix(text, "\"") {
+                return "", fmt.Errorf("unterminated string")
+            }
+            var lValue strings.Builder
+            lText := text[1:len(text) - 1]
+            for index := 0; index < len(lText); index++ {
+                if lText[index] != '\\' || index + 1 == len(lText) {
+                    lValue.WriteByte(lText[index])
+                    continue
+                }
+                index++
+                switch lText[index] {
+                    case 'n':
+                        lValue.WriteByte('\n')
+                    case 't':
+                        lValue.WriteByte('\t')
+                    default:
+                        lValue.WriteByte(lText[index])
+                }
+            }
+            return lValue.String(), nil
+        case strings.HasPrefix(text, "'"):
+            if len(text) < 2 || !strings.HasSuffix(text, "'") {
+                return "", fmt.Errorf("unterminated string")
+            }
+            return strings.Replace(text[1:len(text) - 1], "''", "'", -1), nil
+        case text == "~" || text == "null":
+            return "", nil
+    }
+    return text, nil
+}
+
+// yamlStripComment removes # comment which starts line or follows space outside quotes
+func yamlStripComment(line string) string {
+    lQuote := byte(0)
+    for index := 0; index < len(line); index++ {
+        switch {
+            case lQuote != 0:
+                if line[index] == '\\' && lQuote == '"' {
+                    index++
+                } else if line[index] == lQuote {
+                    lQuote = 0
+                }
+            case line[index] == '"' || line[index] == '\'':
+                if index == 0 || strings.ContainsRune(" :-", rune(line[index - 1])) {
+                    lQuote = line[index]
+                }
+            case line[index] == '#' && (index == 0 || line[index - 1] == ' '):
+                return line[:index]
+        }
+    }
+    return line
+}
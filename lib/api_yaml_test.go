@@ -0,0 +1,53 @@
+package a24apiclient
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestParseYaml(t *testing.T) {
+    lTests := []struct {
+        name            string
+        input           string
+        value           interface{}
+        isError         bool
+    }{
+        { "empty", "", map[string]interface{}{}, false },
+        { "comments only", "---\n# preset\n\n", map[string]interface{}{}, false },
+        { "scalar", "value\n", "value", false },
+        { "mapping", "name: mx\ndescription: Mail servers # comment\n",
+            map[string]interface{}{ "name": "mx", "description": "Mail servers" }, false },
+        { "quoted scalars", "a: \"v=spf1 -all # not comment\"\nb: 'it''s'\nc: \"x\\ty\\\"z\"\nd: ~\ne: null\n",
+            map[string]interface{}{ "a": "v=spf1 -all # not comment", "b": "it's", "c": "x\ty\"z", "d": "", "e": "" }, false },
+        { "colon inside value", "url: https://example.com:8443/x\n",
+            map[string]interface{}{ "url": "https://example.com:8443/x" }, false },
+        { "empty collections and value", "a: []\nb: {}\nc:\n",
+            map[string]interface{}{ "a": []interface{}{}, "b": map[string]interface{}{}, "c": "" }, false },
+        { "nested sequence of mappings",
+            "records:\n  - type: MX\n    name: \"@\"\n    values:\n      - 10\n      - mx.example.com\n  - type: TXT\n",
+            map[string]interface{}{ "records": []interface{}{
+                map[string]interface{}{ "type": "MX", "name": "@", "values": []interface{}{ "10", "mx.example.com" } },
+                map[string]interface{}{ "type": "TXT" },
+            } }, false },
+        { "sequence at key indentation", "values:\n- a\n-\n- b\n",
+            map[string]interface{}{ "values": []interface{}{ "a", "", "b" } }, false },
+        { "item with nested block", "-\n  a: 1\n",
+            []interface{}{ map[string]interface{}{ "a": "1" } }, false },
+        { "crlf line ends", "a: 1\r\nb: 2\r\n", map[string]interface{}{ "a": "1", "b": "2" }, false },
+        { "tab indentation", "a:\n\tb: 1\n", nil, true },
+        { "duplicate key", "a: 1\na: 2\n", nil, true },
+        { "unterminated string", "a: \"x\n", nil, true },
+        { "unexpected indentation", "a: 1\n  b: 2\n", nil, true },
+        { "scalar in mapping", "a: 1\nb\n", nil, true },
+    }
+    for _, lTest := range lTests {
+        lValue, err := ParseYaml([]byte(lTest.input))
+        if (err != nil) != lTest.isError {
+            t.Errorf("%s: error %v", lTest.name, err)
+            continue
+        }
+        if err == nil && !reflect.DeepEqual(lValue, lTest.value) {
+            t.Errorf("%s: got %#v, want %#v", lTest.name, lValue, lTest.value)
+        }
+    }
+}
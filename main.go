@@ -5,10 +5,15 @@ import (
     "bytes"
     "encoding/json"
     "fmt"
+    "io"
     "io/ioutil"
     "path/filepath"
+    "regexp"
     "strconv"
+    "strings"
     "text/tabwriter"
+    "text/template"
+    "time"
     "a24api/lib"
 )
 
@@ -20,26 +25,38 @@ var (
     A24ApiClient                        *a24apiclient.T_A24ApiClient
     A24ApiClientConfig                  map[string]string
     A24ApiClientArgs                    map[string]string
-    A24ApiClientFuncArgs                map[int]string
+    A24ApiClientFuncArgs                []string
+    A24ApiClientConfirmInput            io.Reader = os.Stdin
 
-    A24ApiClientConfigArgs =            [...]string { "endpoint", "token", "network", "timeout" }
+    A24ApiClientConfigArgs =            [...]string { "endpoint", "token", "network", "timeout", "ratelimit", "snapshotdir" }
 )
 
 func printHelp() {
-    fmt.Println(`Usage: a24api [options] <service> <function> [parameters]
+    fmt.Print(`Usage: a24api [options] <service> <function> [parameters]
 
 Options:
     -c|--config <path>            Path to config file. Default is a24api-conf.json. Can be also set via env A24API_CONFIG.
     -e|--endpoint <url>           Active24 REST API url. Can be also set via env A24API_ENDPOINT.
     -t|--token <token>            Active24 REST API token. Can be also set via env A24API_TOKEN.
-    -f|--format <json|inline>     Output format (default: inline).
+    -f|--format <json|result|ndjson|yaml|csv|tsv|table|inline>
+                                  Output format (default: inline). Diff also accepts unified (same as inline) and table.
+    -o|--output <format|template=<go-template>|jsonpath=<expr>>
+                                  Output format as -f, or go template or kubectl-style jsonpath evaluated against results.
+    --no-header                   Omit header row of csv, tsv and table output.
+    --columns <col,...>           Table columns, e.g. name,type,ttl,value (default: all but record value fields).
+    --sort-by <column>            Sort table rows by column, e.g. name, type, ttl or value.
+    --width <n>                   Table width, longest columns are truncated (default: COLUMNS or terminal width, 0 is unlimited).
+    --color <auto|always|never>   Colour table headers and statuses (default: auto, on when stdout is a terminal and NO_COLOR is unset).
+    --idn <ascii|unicode>         Show internationalised domain names in ascii (punycode) or unicode form (default: ascii).
+    -r|--rate-limit <n>           Maximum api requests per second, 0 is unlimited (default: 0). Can be also set via env A24API_RATELIMIT.
     -4                            Use ipv4.
     -6                            Use ipv6.
 
 Services, functions and parameters:
     dns
-        list
-        records <domain>
+        list [-fn <name regex filter>]
+        list|records <domain> [-ft <type regex filter>] [-fn <name regex filter>] [-fv <value regex filter>]
+        list|records --all-domains|-d|--domains <glob> [-j|--concurrency <n>] [-ft ...] [-fn ...] [-fv ...]
         delete <domain> <hash_id>
         create <domain>
             <A|AAAA|CNAME|TXT> <name|@> <ttl> <ip|alias|text>
@@ -57,6 +74,64 @@ Services, functions and parameters:
             <TLSA> <name> <ttl> <certificate_usage> <selector> <matching_type> <hash>
             <CAA> <name> <ttl> <flags> <tag> <caavalue>
             <MX> <name> <ttl> <priority> <mailserver>
+        upsert <domain>
+            <type> <name|@> <ttl> <value...>    same arguments as create; creates, updates or leaves record as is
+        rrset get <domain> <type> <name|@>
+        rrset replace <domain> <type> <name|@> <ttl> <value...> [<value...>]
+                                                one value group per record, e.g. A www 3600 192.0.2.1 192.0.2.2
+        rrset delete <domain> <type> <name|@>
+        search <pattern> [--match <regex|cidr|suffix>] [--field <value|name|type>] [-d|--domains <glob>] [-j|--concurrency <n>]
+        replace --from <value|cidr> --to <value> [-d|--domains <glob>] [-y|--yes] [--rollback-file <path>]
+        revert <rollback_file> [-y|--yes]
+        snapshot <domain...>|--all-domains|-d|--domains <glob> [--snapshot-dir <path>]
+        export <domain...>|--all-domains|-d|--domains <glob> [-j|--concurrency <n>]
+        restore <snapshot_file|domain> [--snapshot-dir <path>] [--prune] [-y|--yes]
+        diff <a> <b> [--ignore-ttl]
+        copy <source_domain> <destination_domain> [-ft <type regex filter>] [-fn <name regex filter>] [--rewrite]
+            [--on-conflict <skip|overwrite|fail>] [-y|--yes]
+        batch <file.csv|file.jsonl> [-j|--concurrency <n>] [--stop-on-error]
+        preset list
+        preset apply <domain> <preset|file.yaml> [--var <name>=<value>]... [-y|--yes]
+        preset apply <preset|file.yaml> --all-domains|-d|--domains <glob> [-j|--concurrency <n>] [--var <name>=<value>]...
+            [-y|--yes]
+
+    spf
+        show <domain> [<name|@>] [--resolver <system|address>]
+        lint <domain> [<name|@>] [--resolver <system|address>]
+        flatten <domain> [<name|@>] [--resolver <system|address>] [-y|--yes]
+
+    dkim
+        list <domain>
+        rotate <domain> <selector> [--algorithm <rsa|ed25519>] [--bits <n>] [--key-file <path>]
+            [--retire <old_selector>] [--grace <duration>] [-y|--yes]
+        prune <domain> [-y|--yes]
+
+    mailauth
+        show <domain> [--resolver <system|address>]
+        dmarc <domain> [--policy <none|quarantine|reject>] [--sp <none|quarantine|reject>] [--pct <0-100>]
+            [--rua <uri,...>] [--ruf <uri,...>] [--adkim <r|s>] [--aspf <r|s>] [-y|--yes]
+        mta-sts <domain> [--id <id>] [-y|--yes]
+        tls-rpt <domain> --rua <uri,...> [-y|--yes]
+
+    tlsa
+        generate <domain> <name|@> <port> --cert <file.pem>|--connect <host:port> [--starttls smtp] [--proto <tcp|udp>]
+            [--params <"usage selector matching_type",...>] [--ttl <ttl>] [--replace] [-y|--yes]
+        check <domain> <name|@> <port> --cert <file.pem>|--connect <host:port> [--starttls smtp] [--proto <tcp|udp>]
+
+    sshfp
+        sync <domain> <host|@> [<key_file|->...] [--fp-type <1|2|1,2>] [--ttl <ttl>] [-y|--yes]
+
+    caa
+        show <domain> [<name|@>]
+        set <domain> <name|@> [--issue <ca|none>]... [--issuewild <ca|none>]... [--iodef <mailto:|https://>]...
+            [--account-uri <uri>] [--validation-methods <method,...>] [--reset] [--ttl <ttl>] [-y|--yes]
+
+    srv
+        services
+        list <domain>
+        set <domain> <name|@> --service <service> [--proto <proto>] --target <host[:port][/priority[/weight]]>...
+            [--port <port>] [--priority <priority>] [--ttl <ttl>] [--resolver <system|address>] [-y|--yes]
+        delete <domain> <name|@> --service <service> [--proto <proto>] [-y|--yes]
 
     domains
         list
@@ -66,17 +141,452 @@ Services, functions and parameters:
         transfer <domain> <auth>
 
 Comments:
+    filters are applied only to inline, yaml, csv, tsv and table format
+    yaml, csv and tsv listings have columns domain, hashId, type, name, ttl followed by value fields of the record
+        type in api naming (e.g. priority, mailserver for MX); listings mixing types have value fields of all
+        types in order A, AAAA, CNAME, TXT, NS, SSHFP, SRV, TLSA, CAA, MX; changes, batch results and diffs
+        prepend their own columns (action, code / line, operation, status, ... / status, side)
+    result prints {"schema": "a24api.dns.result/v1", "results": [...]}, ndjson one result per line, streamed
+        as operations complete for batch and --domains listings; result has operation, action (upsert, changes),
+        line (batch), domain, record (api keys), hashId (looked up for created records), status (ok, error,
+        skipped, rolled_back), code, codeText, data (listings and other payloads), startedAt, durationMs and error
+        {message, details (api response body)}; exits with 2 when any result is an error
+    table has the same columns plus value (record values joined as in inline format); record value fields are
+        shown only when selected by --columns; diff keeps its side-by-side table
+    --output template and jsonpath get record listings as list of typed records (fields Domain, HashId, Type,
+        Name, Ttl and value fields, e.g. Ip, Mailserver; jsonpath uses api names domain, hashId, ip, ...),
+        domain list as list of {Domain}, other functions their json result, e.g.
+        -o 'template={{range .}}{{.Name}} {{.Ip}}{{"\n"}}{{end}}' dns records example.com -ft '^A$'
+        -o 'jsonpath={range [?(@.type=="MX")]}{.priority} {.mailserver}{"\n"}{end}' dns records example.com
+        jsonpath key missing in all selected elements is an error, filters such as [?(@.ip)] select elements having it
+    records are validated before any api call: A/AAAA address family, host names of CNAME/NS/MX/SRV targets,
+        ttl 0-2147483647, SSHFP algorithm, fp_type and fingerprint length, TLSA usage 0-3, selector 0-1,
+        matching_type 0-2 and hash length, CAA flags 0|128 and known tags, SRV/MX ranges 0-65535, TXT length
+    TXT values longer than 255 bytes (DKIM keys, long SPF) are sent as quoted strings "..." "..." with " and \
+        escaped and reassembled when listing, so listings, snapshots and diffs show the value unchanged
+    record names may be relative (www), FQDN with or without trailing dot (www.example.com.) or apex (@ or the
+        domain itself); they are converted to relative form with @ for apex keeping the case given, existing
+        records are matched case-insensitively; absolute names outside the domain are rejected
+    internationalised domains, names and host name values may be given in unicode (příklad.cz), they are sent in
+        ascii (punycode) form; this is punycode conversion of lowercased labels with basic checks (letters, digits,
+        hyphen placement, length), not full IDNA 2008: input is not normalised and must be in composed (NFC) form;
+        --idn unicode shows them in unicode, name filters then match the unicode form, --domains globs match
+        either form
+    upsert matches existing record by name, type and identity fields (ip, alias, text, nameserver, sshfp algorithm
+        and fp_type, srv port and target, all tlsa fields, caa tag and value, mx mailserver); other members of
+        an rrset are kept, CNAME is always updated in place
+    rrset replace/delete compute minimal create/update/delete calls and roll back applied calls when one fails;
+        calls are then listed with code and status (rolled back, skipped) before the error, exit code is 1
+    --all-domains and --domains fan out over domains of the account concurrently (-j, default 1) within
+        the rate limit; output keeps domains in alphabetical order
+    export writes records of domains as BIND zone files (one $ORIGIN block per domain) to stdout, readable by
+        diff; domains failing to list are reported on stderr and exit code is 2
+    preset apply with --all-domains or --domains plans the preset for every selected domain and applies all
+        changes at once, a failure rolls back changes of all domains
+    search scans all domains unless --domains is given; without --match an ip address or cidr selects cidr
+        (A/AAAA records in range), anything else regex on value, name or type; suffix matches CNAME, MX,
+        NS and SRV targets ending with given domain
+    replace rewrites A/AAAA addresses in --from range or CNAME/MX/NS/SRV targets equal to --from, shows
+        a preview, asks for confirmation and stores previous values to rollback file (default
+        a24api-rollback-<timestamp>.json) which can be applied by revert
+    snapshot stores records to <snapshot_dir>/<domain>-<timestamp>.json (default dir a24api-snapshots,
+        can be also set via env A24API_SNAPSHOTDIR or config snapshotdir); restore with domain uses its latest
+        snapshot, diffs it against live records and recreates deleted and reverts changed records, --prune
+        also deletes records created after the snapshot
+    diff sides are live domains, snapshot files, desired-state files (json {"domain": ..., "records": [...]}
+        in api record format) or BIND zone files; names are compared relative to each side's domain, ttls
+        in seconds; exits with 3 when there are differences
+    copy copies whole rrsets; rrsets existing in destination with different records are conflicts (default
+        fail, overwrite makes them equal to source); names are copied relative to domain, --rewrite
+        replaces source domain in values (targets, SPF includes, ...) by destination domain
+    batch csv rows follow command-line arguments: create|upsert,<domain>,<type>,<name>,<ttl>,<value...>,
+        update,<domain>,<hash_id>,<type>,<name>,<ttl>,<value...> and delete,<domain>,<hash_id>; jsonl lines
+        are objects with operation, domain and api record keys, e.g.
+        {"operation":"upsert","domain":"example.com","type":"A","name":"www","ttl":3600,"ip":"192.0.2.1"}
+    batch prints one result per input line (line, operation, status, code, record, error) and exits with 2
+        when any operation failed; operations run in file order unless concurrency is raised
+    preset apply upserts records of built-in preset (microsoft365, google-workspace, active24) or of yaml file
+        {name, description, variables: {<name>: <default>}, records: [{type, name, ttl, <api value keys>}]}
+        where ${<name>} is replaced by --var value or default (empty default is required), ${domain} and
+        ${domain_dashed} are always set; existing SPF record is updated in place, other records of the same
+        name and type (e.g. MX of previous provider) are kept and listed; preset list shows variables, check
+        provider host names against its documentation and override them by --var
+    spf show prints terms of SPF record one per line, lint counts DNS lookups of the record and of nested
+        include/redirect records (limit 10) and void lookups (limit 2), reports syntax errors, ptr, +all,
+        terms after all and include loops, and exits with 2 on errors; flatten replaces include, a, mx and
+        redirect by ip4/ip6 networks and updates the TXT record after confirmation, only pass terms of included
+        records are taken, so it refuses included records with fail/softfail/neutral terms before pass terms and
+        with terms referring to their own domain (ptr without domain, %{d} macro); records are read from zone
+        data of account domains, other domains need --resolver (system or name server address)
+    dkim rotate generates rsa (default 2048 bits) or ed25519 key pair, writes private key in PEM form to
+        --key-file (default <domain>-<selector>.key, mode 0600, never overwritten) and publishes TXT record
+        <selector>._domainkey "v=DKIM1; k=...; p=..." after confirmation; --retire requires --grace, with
+        --grace 0 old selector is deleted now, otherwise (e.g. 72h or 7d) it is kept live and marked by note tag
+        n=retire-after:<time>, prune deletes marked selectors once their time has passed (e.g. from cron)
+    mailauth show reports DMARC (_dmarc), MTA-STS (_mta-sts) and TLS-RPT (_smtp._tls) records of domain with
+        syntax errors and warnings and exits with 2 on errors; dmarc, mta-sts and tls-rpt generate and validate
+        the record and create or update it after confirmation; dmarc keeps tags of published record which are
+        not given (empty value removes tag), mta-sts id defaults to current time (YYYYMMDDhhmmss) and must be
+        changed whenever policy at https://mta-sts.<domain>/.well-known/mta-sts.txt changes
+    tlsa generate computes TLSA records at _<port>._<proto>.<name> (default proto tcp) for each parameter triple
+        (default "3 1 1", e.g. --params "3 1 1,2 1 1") from PEM certificate, chain, public or private key, or from
+        chain presented by TLS endpoint (--starttls smtp for mail servers, chain is read, not verified); usage
+        1/3 use server certificate, 0/2 last certificate of the chain; records are added to published ones
+        (rollover keeps old records, listed), --replace makes the rrset exactly the generated records; check
+        compares published records with the certificate and exits with 2 when no record matches
+    sshfp sync reads OpenSSH public host keys (default /etc/ssh/ssh_host_*_key.pub, - is stdin, ssh-keyscan and
+        known_hosts lines are accepted), computes fingerprints of RSA, DSA, ECDSA, Ed25519 and Ed448 keys (default
+        fp type 2 SHA-256) and makes SSHFP rrset of host equal to them: new keys are added, stale ones deleted,
+        several keys of one algorithm (e.g. ECDSA keys of different curves) are kept as separate records;
+        with - the confirmation is read from terminal, without terminal --yes is required
+    caa show reports CAA policy relevant for name (default @, inherited from the closest parent with CAA records),
+        names below using it and names overriding it, with findings (denied issue or issuewild, wildcards without
+        dns-01 validation, unknown critical tags) and exits with 2 on errors; caa set replaces properties of given
+        tags (repeat option for more CAs, none denies issuance), keeps other tags and, unless --reset, tags not
+        given; --account-uri and --validation-methods (RFC 8657) are added to each given CA; the resulting policy
+        is checked before confirmation
+    srv set makes rrset _<service>._<proto>.<name> exactly the given targets; proto defaults to the first one of
+        known service (srv services lists services with default ports, e.g. sip udp 5060, sip tls 5061), other
+        services need --port or <host>:<port>; priority defaults to --priority or 10, targets without weight get 0
+        when alone in their priority and equal weights otherwise; target . means service is not available; targets
+        which are CNAME are rejected and targets without address reported (names outside account domains are
+        checked only with --resolver); srv list shows share of each target within its priority
     parameters precedence is config_file > command_line > environment > defaults
 
 `)
 
 }
 
+// printRecords prints records of domain in inline format, one line per record matching filters
+func printRecords(w *tabwriter.Writer, domain string, records a24apiclient.T_DnsRecordList, a24api_filter_name, a24api_filter_type, a24api_filter_value *regexp.Regexp) {
+    for _, element := range records {
+        if a24api_filter_type.MatchString(element["type"].(string)) {
+            switch element["type"].(string) {
+                case "A", "AAAA":
+                    if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["ip"].(string)) {
+                        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%s\n", domain, element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["ip"].(string))
+                    }
+                case "CNAME":
+                    if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["alias"].(string)) {
+                        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%s\n", domain, element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["alias"].(string))
+                    }
+                case "TXT":
+                    if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["text"].(string)) {
+                        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t\"%s\"\n", domain, element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["text"].(string))
+                    }
+                case "NS":
+                    if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["nameServer"].(string)) {
+                        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%s\n", domain, element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["nameServer"].(string))
+                    }
+                case "SSHFP":
+                    if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["text"].(string)) {
+                        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%g\t%g\t%s\n", domain, element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["algorithm"].(float64), element["fingerprintType"].(float64), element["text"].(string))
+                    }
+                case "SRV":
+                    if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["target"].(string)) {
+                        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%g\t%g\t%g\t%s\n", domain, element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["priority"].(float64), element["weight"].(float64), element["port"].(float64), element["target"].(string))
+                    }
+                case "TLSA":
+                    if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["hash"].(string)) {
+                        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%g\t%g\t%g\t%s\n", domain, element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["certificateUsage"].(float64), element["selector"].(float64), element["matchingType"].(float64), element["hash"].(string))
+                    }
+                case "CAA":
+                    if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["caaValue"].(string)) {
+                        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%g\t%s\t%s\n", domain, element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["flags"].(float64), element["tag"].(string), element["caaValue"].(string))
+                    }
+                case "MX":
+                    if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["mailserver"].(string)) {
+                        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%g\t%s\n", domain, element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["priority"].(float64), element["mailserver"].(string))
+                    }
+            }
+        }
+    }
+}
+
+// printRecordMaps prints records built by a24apiclient.NewDnsRecordFromList in inline format
+func printRecordMaps(w *tabwriter.Writer, records []map[string]string) {
+    for _, element := range records {
+        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", element["Domain"], element["HashId"], element["Type"], element["Name"], element["Ttl"], a24apiclient.DnsRecordValue(element))
+    }
+}
+
+// outputOptions returns settings of yaml, csv, tsv and table output from function arguments, width defaults to terminal
+// width and colour to auto (on when stdout is a terminal and NO_COLOR is not set)
+func outputOptions(args map[string]string) (t_outputOptions, error) {
+    lOptions := t_outputOptions{ Header: args["no-header"] != "true", SortBy: args["sort-by"] }
+    if args["columns"] != "" {
+        lOptions.Columns = strings.Split(args["columns"], ",")
+    }
+    lTerminal := isTerminal(os.Stdout)
+    if args["width"] != "" {
+        lWidth, err := strconv.Atoi(args["width"])
+        if err != nil || lWidth < 0 {
+            return lOptions, fmt.Errorf("Invalid width: %s.", args["width"])
+        }
+        lOptions.Width = lWidth
+    } else if lColumns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && lColumns > 0 {
+        lOptions.Width = lColumns
+    } else if lTerminal {
+        lOptions.Width = terminalWidth(os.Stdout)
+    }
+    switch args["color"] {
+        case "", "auto":
+            lOptions.Colour = lTerminal && os.Getenv("NO_COLOR") == ""
+        case "always":
+            lOptions.Colour = true
+        case "never":
+            lOptions.Colour = false
+        default:
+            return lOptions, fmt.Errorf("Invalid color mode: %s.", args["color"])
+    }
+    return lOptions, nil
+}
+
+// responseResult returns data of response for structured output, code of upsert and functions without data
+func responseResult(data interface{}, action string, code int, codeText string) interface{} {
+    if action != "" {
+        return map[string]interface{}{ "action": action, "code": code, "codeText": codeText }
+    }
+    if data == nil {
+        // create, update and delete
+        return map[string]interface{}{ "code": code, "codeText": codeText }
+    }
+    return data
+}
+
+// batchResult converts batch result to result envelope, hashId of created record is looked up
+func batchResult(client *a24apiclient.T_A24ApiClient, result a24apiclient.T_DnsBatchResult) a24apiclient.T_DnsResult {
+    r := a24apiclient.NewDnsResultFromBatch(result)
+    if r.Status == a24apiclient.C_DnsResult_Ok && (result.Operation == "create" || result.Action == a24apiclient.C_DnsUpsert_Create) {
+        r.HashId, _ = client.DnsResolveHashId(result.Record)
+    }
+    return r
+}
+
+// resultData returns payload of result envelope, record maps are converted to api format
+func resultData(data interface{}) interface{} {
+    if lRecords, isRecords := data.([]map[string]string); isRecords {
+        lData := []map[string]interface{}{}
+        for _, element := range lRecords {
+            lRecord := a24apiclient.DnsRecordApi(element)
+            lRecord["domain"] = element["Domain"]
+            lData = append(lData, lRecord)
+        }
+        return lData
+    }
+    return data
+}
+
+// idnArgs converts domains and names of positional arguments into ascii form, diff and restore sources which
+// are existing files are kept
+func idnArgs(function string, args []string) error {
+    var lIndexes []int
+    switch function {
+        case "list", "records", "create", "upsert", "update", "delete", "rotate", "prune", "dmarc", "mta-sts", "tls-rpt":
+            lIndexes = []int{ 0 }
+        case "rrset":
+            lIndexes = []int{ 1, 3 }
+        case "preset":
+            lIndexes = []int{ 1 }
+        case "copy", "show", "lint", "flatten", "generate", "check", "sync", "set":
+            lIndexes = []int{ 0, 1 }
+        case "snapshot", "restore", "diff":
+            for index := range args {
+                lIndexes = append(lIndexes, index)
+            }
+    }
+    for _, index := range lIndexes {
+        if index >= len(args) {
+            continue
+        }
+        if _, err := os.Stat(args[index]); err == nil && (function == "restore" || function == "diff") {
+            continue
+        }
+        lAscii, err := a24apiclient.DnsIdnToAscii(args[index])
+        if err != nil {
+            return err
+        }
+        args[index] = lAscii
+    }
+    return nil
+}
+
+// idnOutput converts names in response data into unicode form when --idn unicode is set
+func idnOutput(args map[string]string, data interface{}) interface{} {
+    if args["idn"] != "unicode" {
+        return data
+    }
+    return a24apiclient.DnsDataToUnicode(data)
+}
+
+// printResultLine prints result envelope as one ndjson line
+func printResultLine(result a24apiclient.T_DnsResult) {
+    lJson, _ := json.Marshal(result)
+    fmt.Printf("%s\n", string(lJson))
+}
+
+// resultExitCode returns exit code of function results: batch failure, diff differences, partial snapshot
+func resultExitCode(data interface{}, partial bool) int {
+    if lResults, isBatch := data.([]a24apiclient.T_DnsBatchResult); isBatch {
+        return batchExitCode(lResults)
+    }
+    if lEntries, isDiff := data.([]a24apiclient.T_DnsDiffEntry); isDiff && len(lEntries) > 0 {
+        return 3
+    }
+    if lLint, isLint := data.(*a24apiclient.T_SpfLint); isLint && lLint.Errors() > 0 {
+        return 2
+    }
+    if lMailAuth, isMailAuth := data.(*a24apiclient.T_MailAuth); isMailAuth && lMailAuth.Errors() > 0 {
+        return 2
+    }
+    if lPolicy, isCaa := data.(*a24apiclient.T_CaaPolicy); isCaa && lPolicy.Errors() > 0 {
+        return 2
+    }
+    if lChecks, isTlsa := data.([]a24apiclient.T_TlsaCheck); isTlsa && a24apiclient.TlsaMatches(lChecks) == 0 {
+        return 2
+    }
+    if partial {
+        return 2
+    }
+    return 0
+}
+
+// printChangesPreview prints planned changes with previous and new value to stderr
+func printChangesPreview(changes []a24apiclient.T_DnsChange) {
+    w := new(tabwriter.Writer)
+    w.Init(os.Stderr, 0, 8, 1, ' ', 0)
+    fmt.Fprintf(w, "ACTION\tDOMAIN\tHASHID\tTYPE\tNAME\tOLD\tNEW\n")
+    for _, element := range changes {
+        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", element.Action, element.Record["Domain"], element.Record["HashId"], element.Record["Type"], element.Record["Name"], a24apiclient.DnsRecordValue(element.Previous), a24apiclient.DnsRecordValue(element.Record))
+    }
+    w.Flush()
+}
+
+// confirm asks question on stderr and reads answer from stdin (terminal when stdin carries input data)
+func confirm(question string) bool {
+    fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+    var lAnswer string
+    fmt.Fscanln(A24ApiClientConfirmInput, &lAnswer)
+    lAnswer = strings.ToLower(lAnswer)
+    return lAnswer == "y" || lAnswer == "yes"
+}
+
+// changeCodeText returns code text of applied change, reverted and not attempted changes are marked
+func changeCodeText(client *a24apiclient.T_A24ApiClient, change a24apiclient.T_DnsChange) string {
+    switch change.Status() {
+        case a24apiclient.C_DnsResult_Skipped:
+            return "(skipped)"
+        case a24apiclient.C_DnsResult_RolledBack:
+            return client.GetCodeText(change.Code, "dns", change.Action) + " (rolled back)"
+    }
+    return client.GetCodeText(change.Code, "dns", change.Action)
+}
+
+// printChanges prints applied changes inline, changes of dns service are shown with ttl and value
+func printChanges(w io.Writer, client *a24apiclient.T_A24ApiClient, service string, changes []a24apiclient.T_DnsChange) {
+    if service != "dns" {
+        for _, element := range changes {
+            fmt.Fprintf(w, "%s\t%s\t%s\t%d %s\n", element.Action, element.Record["Type"], element.Record["Name"], element.Code, changeCodeText(client, element))
+        }
+        return
+    }
+    lWriter := new(tabwriter.Writer)
+    lWriter.Init(w, 0, 8, 1, ' ', 0)
+    for _, element := range changes {
+        fmt.Fprintf(lWriter, "%s\t%s\t%s\t%s\t%s\t%d %s\n", element.Action, element.Record["Type"], element.Record["Name"], element.Record["Ttl"], a24apiclient.DnsRecordValue(element.Record), element.Code, changeCodeText(client, element))
+    }
+    lWriter.Flush()
+}
+
+// renderChanges prints changes of failed apply in requested format, so that rolled back and skipped changes are
+// visible before error
+func renderChanges(w io.Writer, client *a24apiclient.T_A24ApiClient, args map[string]string, changes []a24apiclient.T_DnsChange) error {
+    switch args["format"] {
+        case "json":
+            lJson, _ := json.MarshalIndent(changes, "", "    ")
+            fmt.Fprintf(w, "%s\n", string(lJson))
+        case "yaml", "csv", "tsv", "table":
+            lOptions, err := outputOptions(args)
+            if err != nil {
+                return err
+            }
+            return renderData(w, args["format"], changes, lOptions)
+        default:
+            printChanges(w, client, args["service"], changes)
+    }
+    return nil
+}
+
+// writeChanges stores changes to file, e.g. as rollback file
+func writeChanges(path string, changes []a24apiclient.T_DnsChange) error {
+    lFile, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    defer lFile.Close()
+    return a24apiclient.WriteDnsChanges(lFile, changes)
+}
+
+// isZoneFile reports whether diff source is an existing file which is not json
+func isZoneFile(source string) bool {
+    lData, err := ioutil.ReadFile(source)
+    if err != nil {
+        return false
+    }
+    return !strings.HasPrefix(strings.TrimSpace(string(lData)), "{")
+}
+
+// printDiffUnified prints diff entries as - (only in a) and + (only in b) lines
+func printDiffUnified(entries []a24apiclient.T_DnsDiffEntry, a, b string) {
+    fmt.Printf("--- %s\n+++ %s\n", a, b)
+    for _, element := range entries {
+        if element.A != nil {
+            fmt.Printf("-%s\t%s\t%s\t%s\n", element.A["Name"], element.A["Ttl"], element.A["Type"], a24apiclient.DnsRecordValue(element.A))
+        }
+        if element.B != nil {
+            fmt.Printf("+%s\t%s\t%s\t%s\n", element.B["Name"], element.B["Ttl"], element.B["Type"], a24apiclient.DnsRecordValue(element.B))
+        }
+    }
+}
+
+// printDiffTable prints diff entries side by side
+func printDiffTable(entries []a24apiclient.T_DnsDiffEntry) {
+    w := new(tabwriter.Writer)
+    w.Init(os.Stdout, 0, 8, 1, ' ', 0)
+    fmt.Fprintf(w, "STATUS\tNAME\tTYPE\tTTL A\tVALUE A\tTTL B\tVALUE B\n")
+    for _, element := range entries {
+        lRecord := element.A
+        if lRecord == nil {
+            lRecord = element.B
+        }
+        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", element.Status, lRecord["Name"], lRecord["Type"], element.A["Ttl"], a24apiclient.DnsRecordValue(element.A), element.B["Ttl"], a24apiclient.DnsRecordValue(element.B))
+    }
+    w.Flush()
+}
+
+// domainsPattern converts --domains value to DnsSelectDomains pattern, * selects all domains
+func domainsPattern(domains string) string {
+    if domains == "*" {
+        return ""
+    }
+    return domains
+}
+
+// batchExitCode returns 0 when every operation succeeded and 2 otherwise
+func batchExitCode(results []a24apiclient.T_DnsBatchResult) int {
+    for _, element := range results {
+        if element.Status != "ok" {
+            return 2
+        }
+    }
+    return 0
+}
+
 func main() {
 
     A24ApiClientConfig := make(map[string]string)
     A24ApiClientArgs := make(map[string]string)
-    A24ApiClientFuncArgs := make(map[int]string)
+    A24ApiClientFuncArgs := []string{}
 
 // ================================================================================================================================================================
 // PARSE ENVIRONMENT
@@ -86,7 +596,9 @@ func main() {
     A24ApiClientConfig["token"] = os.Getenv("A24API_TOKEN")
     A24ApiClientConfig["network"] = os.Getenv("A24API_NETWORK")
     A24ApiClientConfig["timeout"] = os.Getenv("A24API_TIMEOUT")
+    A24ApiClientConfig["ratelimit"] = os.Getenv("A24API_RATELIMIT")
     A24ApiClientConfig["format"] = os.Getenv("A24API_FORMAT")
+    A24ApiClientConfig["snapshotdir"] = os.Getenv("A24API_SNAPSHOTDIR")
     A24ApiClientConfig["config"] = os.Getenv("A24API_CONFIG")
 
 // ================================================================================================================================================================
@@ -96,7 +608,6 @@ func main() {
     var params = os.Args[1:]
     var indexMax = len(params) - 1
     var indexUsedFlag = -1
-    var posFuncArgIndex = 0
 
     for index, element := range params {
 
@@ -109,36 +620,195 @@ func main() {
                 printHelp()
                 os.Exit(0)
             // set config file
-            } else if (element == "-c" || element == "--config") && (index < indexMax) && (A24ApiClientConfig["service"] == "") {
+            } else if (element == "-c" || element == "--config") && (index < indexMax) && (A24ApiClientArgs["service"] == "") {
                 A24ApiClientConfig["config"] = params[index + 1]
                 indexUsedFlag = index + 1
             // set api endpoint
-            } else if (element == "-e" || element == "--endpoint") && (index < indexMax) && (A24ApiClientConfig["service"] == "") {
+            } else if (element == "-e" || element == "--endpoint") && (index < indexMax) && (A24ApiClientArgs["service"] == "") {
                 A24ApiClientConfig["endpoint"] = params[index + 1]
                 indexUsedFlag = index + 1
             // set api token
-            } else if (element == "-t" || element == "--token") && (index < indexMax) && (A24ApiClientConfig["service"] == "") {
+            } else if (element == "-t" || element == "--token") && (index < indexMax) && (A24ApiClientArgs["service"] == "") {
                 A24ApiClientConfig["token"] = params[index + 1]
                 indexUsedFlag = index + 1
             // set output format
-            } else if (element == "-f" || element == "--format") && (index < indexMax) && (A24ApiClientConfig["service"] == "") {
+            } else if (element == "-f" || element == "--format") && (index < indexMax) && (A24ApiClientArgs["service"] == "") {
                 A24ApiClientArgs["format"] = params[index + 1]
                 indexUsedFlag = index + 1
+            // set output format, go template or jsonpath
+            } else if (element == "-o" || element == "--output") && (index < indexMax) {
+                A24ApiClientArgs["output"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // disable header row of csv, tsv and table output
+            } else if (element == "--no-header") {
+                A24ApiClientArgs["no-header"] = "true"
+            // select table columns
+            } else if (element == "--columns") && (index < indexMax) {
+                A24ApiClientArgs["columns"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // sort table rows
+            } else if (element == "--sort-by") && (index < indexMax) {
+                A24ApiClientArgs["sort-by"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set table width
+            } else if (element == "--width") && (index < indexMax) {
+                A24ApiClientArgs["width"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set table colour mode
+            } else if (element == "--color") && (index < indexMax) {
+                A24ApiClientArgs["color"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set form of internationalised domain names in output
+            } else if (element == "--idn") && (index < indexMax) {
+                A24ApiClientArgs["idn"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set api rate limit
+            } else if (element == "-r" || element == "--rate-limit") && (index < indexMax) && (A24ApiClientArgs["service"] == "") {
+                A24ApiClientConfig["ratelimit"] = params[index + 1]
+                indexUsedFlag = index + 1
             // set network ip version to 4
-            } else if (element == "-4") && (A24ApiClientConfig["service"] == "") {
+            } else if (element == "-4") && (A24ApiClientArgs["service"] == "") {
                 A24ApiClientConfig["network"] = "tcp4"
             // set network ip version to 6
-            } else if (element == "-6") && (A24ApiClientConfig["service"] == "") {
+            } else if (element == "-6") && (A24ApiClientArgs["service"] == "") {
                 A24ApiClientConfig["network"] = "tcp6"
             // set api service
-            } else if (element == "dns" || element == "domain") && (A24ApiClientConfig["service"] == "") {
+            } else if (element == "dns" || element == "domain" || element == "spf" || element == "dkim" || element == "mailauth" || element == "tlsa" || element == "sshfp" || element == "caa" || element == "srv") && (A24ApiClientArgs["service"] == "") {
                 A24ApiClientArgs["service"] = element
             // set api function
-            } else if (element == "list" || element == "delete" || element == "create" || element == "update") && (A24ApiClientConfig["service"] != "") {
+            } else if (element == "list" || element == "records" || element == "delete" || element == "create" || element == "update" || element == "upsert" || element == "rrset" || element == "preset" || element == "batch" || element == "search" || element == "replace" || element == "revert" || element == "snapshot" || element == "export" || element == "restore" || element == "diff" || element == "copy" || element == "show" || element == "lint" || element == "flatten" || element == "rotate" || element == "prune" || element == "dmarc" || element == "mta-sts" || element == "tls-rpt" || element == "generate" || element == "check" || element == "sync" || element == "set" || element == "services") && (A24ApiClientArgs["service"] != "") && (A24ApiClientArgs["function"] == "") {
                 A24ApiClientArgs["function"] = element
+            // set name filter
+            } else if (element == "-fn") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["filter-name"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set type filter
+            } else if (element == "-ft") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["filter-type"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set value filter
+            } else if (element == "-fv") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["filter-value"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set batch concurrency
+            } else if (element == "-j" || element == "--concurrency") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["concurrency"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // select all domains
+            } else if (element == "--all-domains") && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["domains"] = "*"
+            // select domains by glob
+            } else if (element == "-d" || element == "--domains") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["domains"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set search mode
+            } else if (element == "--match") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["match"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set search field
+            } else if (element == "--field") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["field"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set replace source
+            } else if (element == "--from") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["from"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set replace value
+            } else if (element == "--to") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["to"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set rollback file
+            } else if (element == "--rollback-file") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["rollback-file"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set snapshot directory
+            } else if (element == "--snapshot-dir") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["snapshot-dir"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // delete records not present in snapshot
+            } else if (element == "--prune") && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["prune"] = "true"
+            // ignore ttl in diff
+            } else if (element == "--ignore-ttl") && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["ignore-ttl"] = "true"
+            // rewrite source domain in copied records
+            } else if (element == "--rewrite") && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["rewrite"] = "true"
+            // set conflict handling of copy
+            } else if (element == "--on-conflict") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["on-conflict"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // do not ask for confirmation
+            } else if (element == "-y" || element == "--yes") && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["yes"] = "true"
+            // set resolver of names outside account domains
+            } else if (element == "--resolver") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["resolver"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set dkim key algorithm
+            } else if (element == "--algorithm") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["algorithm"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set dkim rsa key size
+            } else if (element == "--bits") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["bits"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set dkim private key file
+            } else if (element == "--key-file") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["key-file"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set dkim selector replaced by rotation
+            } else if (element == "--retire") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["retire"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set grace period of retired dkim selector
+            } else if (element == "--grace") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["grace"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set dmarc and tls-rpt tags
+            } else if (element == "--policy" || element == "--sp" || element == "--pct" || element == "--rua" || element == "--ruf" || element == "--adkim" || element == "--aspf") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["tag-" + strings.TrimPrefix(element, "--")] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set preset variable
+            } else if (element == "--var") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["var"] = strings.TrimPrefix(A24ApiClientArgs["var"] + "\n" + params[index + 1], "\n")
+                indexUsedFlag = index + 1
+            // set tlsa certificate source, protocol and parameters
+            } else if (element == "--cert" || element == "--connect" || element == "--starttls" || element == "--proto" || element == "--params" || element == "--ttl" || element == "--fp-type") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs[strings.TrimPrefix(element, "--")] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set caa properties
+            } else if (element == "--issue" || element == "--issuewild" || element == "--iodef") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["caa-" + strings.TrimPrefix(element, "--")] = strings.TrimPrefix(A24ApiClientArgs["caa-" + strings.TrimPrefix(element, "--")] + "\n" + params[index + 1], "\n")
+                indexUsedFlag = index + 1
+            // set caa issuer parameters
+            } else if (element == "--account-uri" || element == "--validation-methods") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs[strings.TrimPrefix(element, "--")] = params[index + 1]
+                indexUsedFlag = index + 1
+            // set srv targets
+            } else if (element == "--target") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["target"] = strings.TrimPrefix(A24ApiClientArgs["target"] + "\n" + params[index + 1], "\n")
+                indexUsedFlag = index + 1
+            // set srv service, port and priority
+            } else if (element == "--service" || element == "--port" || element == "--priority") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["srv-" + strings.TrimPrefix(element, "--")] = params[index + 1]
+                indexUsedFlag = index + 1
+            // remove caa properties which are not given
+            } else if (element == "--reset") && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["reset"] = "true"
+            // replace whole tlsa rrset
+            } else if (element == "--replace") && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["replace-rrset"] = "true"
+            // set mta-sts policy id
+            } else if (element == "--id") && (index < indexMax) && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["id"] = params[index + 1]
+                indexUsedFlag = index + 1
+            // stop batch on first error
+            } else if (element == "--stop-on-error") && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientArgs["stop-on-error"] = "true"
             // set positional arguments
-            } else if (A24ApiClientConfig["service"] != "") && (A24ApiClientConfig["function"] != "") {
-                A24ApiClientFuncArgs[posFuncArgIndex] = element
+            } else if (A24ApiClientArgs["service"] != "") && (A24ApiClientArgs["function"] != "") {
+                A24ApiClientFuncArgs = append(A24ApiClientFuncArgs, element)
             // exit on unexpected argument
             } else {
                 fmt.Println("Unknown argument or argument out of order.")
@@ -159,6 +829,42 @@ func main() {
         }
         A24ApiClientConfig["config"] = confPath + "/" + C_A24ApiClient_Configfile
     }
+    if lOutput := A24ApiClientArgs["output"]; strings.HasPrefix(lOutput, "template=") || strings.HasPrefix(lOutput, "go-template=") {
+        A24ApiClientArgs["format"] = "template"
+        A24ApiClientArgs["template"] = lOutput[strings.Index(lOutput, "=") + 1:]
+    } else if strings.HasPrefix(lOutput, "jsonpath=") {
+        A24ApiClientArgs["format"] = "jsonpath"
+        A24ApiClientArgs["jsonpath"] = strings.TrimPrefix(lOutput, "jsonpath=")
+    } else if lOutput != "" {
+        A24ApiClientArgs["format"] = lOutput
+    }
+    if A24ApiClientArgs["format"] == "" {
+        A24ApiClientArgs["format"] = A24ApiClientConfig["format"]
+    }
+    if A24ApiClientArgs["format"] == "" {
+        A24ApiClientArgs["format"] = "inline"
+    }
+    if A24ApiClientArgs["concurrency"] == "" {
+        A24ApiClientArgs["concurrency"] = "1"
+    }
+    if A24ApiClientArgs["snapshot-dir"] == "" {
+        A24ApiClientArgs["snapshot-dir"] = A24ApiClientConfig["snapshotdir"]
+    }
+    if A24ApiClientArgs["snapshot-dir"] == "" {
+        A24ApiClientArgs["snapshot-dir"] = "a24api-snapshots"
+    }
+    if A24ApiClientArgs["idn"] == "" {
+        A24ApiClientArgs["idn"] = "ascii"
+    }
+    if A24ApiClientArgs["filter-name"] == "" {
+        A24ApiClientArgs["filter-name"] = ".*"
+    }
+    if A24ApiClientArgs["filter-type"] == "" {
+        A24ApiClientArgs["filter-type"] = ".*"
+    }
+    if A24ApiClientArgs["filter-value"] == "" {
+        A24ApiClientArgs["filter-value"] = ".*"
+    }
 
 // ================================================================================================================================================================
 // LOAD CONFIG FILE
@@ -181,11 +887,20 @@ func main() {
 // CHECK INPUT DATA
 // ================================================================================================================================================================
 
-    if A24ApiClientConfig["service"] == "" || A24ApiClientConfig["function"] == "" {
+    if A24ApiClientArgs["service"] == "" || A24ApiClientArgs["function"] == "" {
         fmt.Println("Service or function not provided.")
         printHelp()
         os.Exit(1)
     }
+    if A24ApiClientArgs["idn"] != "ascii" && A24ApiClientArgs["idn"] != "unicode" {
+        fmt.Printf("Invalid idn form: %s.\n", A24ApiClientArgs["idn"])
+        os.Exit(1)
+    }
+    // domains and names given in unicode are sent in ascii form
+    if err := idnArgs(A24ApiClientArgs["function"], A24ApiClientFuncArgs); err != nil {
+        fmt.Println(err)
+        os.Exit(1)
+    }
 
 // ================================================================================================================================================================
 // INITIALIZE CLIENT
@@ -199,117 +914,1347 @@ func main() {
 
     var A24ApiResponseCode    int
     var A24ApiResponseBody    []byte
+    var A24ApiResponseData    interface{}
+    var A24ApiResponseAction  string
     var A24ApiResponseError   error
+    var A24ApiResponseRecord  map[string]string
+
+    // result and ndjson formats report timing and stream batch and multi-domain results
+    lStarted := time.Now()
+    lStream := A24ApiClientArgs["format"] == "ndjson"
+
+    lConcurrency, err := strconv.Atoi(A24ApiClientArgs["concurrency"])
+    if err != nil || lConcurrency < 1 {
+        fmt.Printf("Invalid concurrency: %s.\n", A24ApiClientArgs["concurrency"])
+        os.Exit(1)
+    }
 
     switch A24ApiClientArgs["service"] {
         case "dns":
             switch A24ApiClientArgs["function"] {
-                case "list":
-                    // expected arguments:
-                    A24ApiResponseCode, A24ApiResponseBody, A24ApiResponseError := A24ApiClient.DnsListDomains()
-                case "records":
-                    // expected arguments: 0=domain
-                    A24ApiResponseCode, A24ApiResponseBody, A24ApiResponseError := A24ApiClient.DnsListRecords(A24ApiClientFuncArgs)
-                case "create":
-                    // expected arguments: 0=domain, ...
-                    A24ApiResponseCode, A24ApiResponseBody, A24ApiResponseError := A24ApiClient.DnsCreate(A24ApiClientFuncArgs)
+                case "list", "records":
+                    if A24ApiClientArgs["domains"] != "" {
+                        // expected arguments: (domains selected by --all-domains or --domains)
+                        var lDomains []string
+                        A24ApiResponseCode, lDomains, A24ApiResponseError = A24ApiClient.DnsSelectDomains(domainsPattern(A24ApiClientArgs["domains"]))
+                        if A24ApiResponseError == nil && lStream {
+                            A24ApiResponseData = A24ApiClient.DnsListRecordsMultiStream(lDomains, lConcurrency, func(r a24apiclient.T_DnsDomainRecords) {
+                                lResult := idnOutput(A24ApiClientArgs, r).(a24apiclient.T_DnsDomainRecords)
+                                printResultLine(a24apiclient.NewDnsResultFromDomainRecords(A24ApiClientArgs["function"], lResult, A24ApiClient.GetCodeText(r.Code, "dns", "list")))
+                            })
+                            A24ApiClientArgs["streamed"] = "true"
+                        } else if A24ApiResponseError == nil {
+                            A24ApiResponseData = A24ApiClient.DnsListRecordsMulti(lDomains, lConcurrency)
+                        }
+                    } else if len(A24ApiClientFuncArgs) == 0 {
+                        // expected arguments:
+                        A24ApiResponseCode, A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsListDomains()
+                    } else {
+                        // expected arguments: 0=domain
+                        var lRecords a24apiclient.T_DnsRecordList
+                        A24ApiClientArgs["domain"] = A24ApiClientFuncArgs[0]
+                        A24ApiResponseCode, lRecords, A24ApiResponseError = A24ApiClient.DnsListRecords(map[string]string{ "0": A24ApiClientArgs["domain"] })
+                        A24ApiResponseData = lRecords
+                    }
+                case "create", "upsert":
+                    // expected arguments: 0=domain, 1=type, 2=name, 3=ttl, ...
+                    if len(A24ApiClientFuncArgs) < 1 {
+                        fmt.Println("Domain not provided.")
+                        os.Exit(1)
+                    }
+                    lRecord, err := a24apiclient.NewDnsRecord(A24ApiClientFuncArgs[0], A24ApiClientFuncArgs[1:])
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    A24ApiResponseRecord = lRecord
+                    if A24ApiClientArgs["function"] == "create" {
+                        A24ApiResponseCode, A24ApiResponseBody, A24ApiResponseError = A24ApiClient.DnsCreate(lRecord)
+                    } else {
+                        A24ApiResponseAction, A24ApiResponseCode, A24ApiResponseBody, A24ApiResponseError = A24ApiClient.DnsUpsert(lRecord)
+                    }
                 case "update":
-                    // expected arguments: 0=domain, 1=hash_id, ...
-                    A24ApiResponseCode, A24ApiResponseBody, A24ApiResponseError := A24ApiClient.DnsUpdate(A24ApiClientFuncArgs)
+                    // expected arguments: 0=domain, 1=hash_id, 2=type, 3=name, 4=ttl, ...
+                    if len(A24ApiClientFuncArgs) < 2 {
+                        fmt.Println("Domain or hash_id not provided.")
+                        os.Exit(1)
+                    }
+                    lRecord, err := a24apiclient.NewDnsRecord(A24ApiClientFuncArgs[0], A24ApiClientFuncArgs[2:])
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    lRecord["HashId"] = A24ApiClientFuncArgs[1]
+                    A24ApiResponseRecord = lRecord
+                    A24ApiResponseCode, A24ApiResponseBody, A24ApiResponseError = A24ApiClient.DnsUpdate(lRecord)
                 case "delete":
                     // expected arguments: 0=domain, 1=hash_id
-                    A24ApiResponseCode, A24ApiResponseBody, A24ApiResponseError := A24ApiClient.DnsDelete(A24ApiClientFuncArgs)
+                    if len(A24ApiClientFuncArgs) < 2 {
+                        fmt.Println("Domain or hash_id not provided.")
+                        os.Exit(1)
+                    }
+                    A24ApiResponseRecord = map[string]string{ "Domain": A24ApiClientFuncArgs[0], "HashId": A24ApiClientFuncArgs[1] }
+                    A24ApiResponseCode, A24ApiResponseBody, A24ApiResponseError = A24ApiClient.DnsDelete(A24ApiResponseRecord)
+                case "rrset":
+                    // expected arguments: 0=get|replace|delete, 1=domain, 2=type, 3=name, (4=ttl, ...)
+                    if len(A24ApiClientFuncArgs) < 4 {
+                        fmt.Println("Rrset function, domain, type or name not provided.")
+                        os.Exit(1)
+                    }
+                    lDomain := A24ApiClientFuncArgs[1]
+                    lType := strings.ToUpper(A24ApiClientFuncArgs[2])
+                    lName := A24ApiClientFuncArgs[3]
+                    A24ApiClientArgs["domain"] = lDomain
+                    A24ApiClientArgs["rrset"] = A24ApiClientFuncArgs[0]
+                    switch A24ApiClientArgs["rrset"] {
+                        case "get":
+                            A24ApiResponseCode, A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsGetRRset(lDomain, lType, lName)
+                        case "replace":
+                            lRRset, err := a24apiclient.NewDnsRRset(lDomain, A24ApiClientFuncArgs[2:])
+                            if err != nil {
+                                fmt.Println(err)
+                                os.Exit(1)
+                            }
+                            A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsReplaceRRset(lDomain, lType, lName, lRRset)
+                            A24ApiResponseCode = 200
+                        case "delete":
+                            A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsDeleteRRset(lDomain, lType, lName)
+                            A24ApiResponseCode = 200
+                        default:
+                            fmt.Printf("Unsupported rrset function: %s.\n", A24ApiClientArgs["rrset"])
+                            os.Exit(1)
+                    }
+                case "batch":
+                    // expected arguments: 0=file
+                    if len(A24ApiClientFuncArgs) < 1 {
+                        fmt.Println("Batch file not provided.")
+                        os.Exit(1)
+                    }
+                    lFile, err := os.Open(A24ApiClientFuncArgs[0])
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    var lFormat string
+                    switch strings.ToLower(filepath.Ext(A24ApiClientFuncArgs[0])) {
+                        case ".csv":
+                            lFormat = "csv"
+                        case ".jsonl", ".ndjson":
+                            lFormat = "jsonl"
+                    }
+                    lOperations, err := a24apiclient.NewDnsBatch(lFile, lFormat)
+                    lFile.Close()
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    if lStream {
+                        A24ApiResponseData = A24ApiClient.DnsBatchStream(lOperations, lConcurrency, A24ApiClientArgs["stop-on-error"] == "true", func(r a24apiclient.T_DnsBatchResult) {
+                            printResultLine(batchResult(A24ApiClient, idnOutput(A24ApiClientArgs, r).(a24apiclient.T_DnsBatchResult)))
+                        })
+                        A24ApiClientArgs["streamed"] = "true"
+                    } else {
+                        A24ApiResponseData = A24ApiClient.DnsBatch(lOperations, lConcurrency, A24ApiClientArgs["stop-on-error"] == "true")
+                    }
+                    A24ApiResponseCode = 200
+                case "search":
+                    // expected arguments: 0=pattern
+                    if len(A24ApiClientFuncArgs) < 1 {
+                        fmt.Println("Search pattern not provided.")
+                        os.Exit(1)
+                    }
+                    lQuery, err := a24apiclient.NewDnsSearchQuery(A24ApiClientFuncArgs[0], A24ApiClientArgs["match"], A24ApiClientArgs["field"])
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    var lDomains []string
+                    A24ApiResponseCode, lDomains, A24ApiResponseError = A24ApiClient.DnsSelectDomains(domainsPattern(A24ApiClientArgs["domains"]))
+                    if A24ApiResponseError == nil {
+                        lMatches, lFailed := A24ApiClient.DnsSearch(lDomains, lQuery, lConcurrency)
+                        for _, lDomainRecords := range lFailed {
+                            fmt.Fprintf(os.Stderr, "%s: %s\n", lDomainRecords.Domain, lDomainRecords.Err)
+                        }
+                        A24ApiResponseData = lMatches
+                        if lMatches == nil {
+                            A24ApiResponseData = []map[string]string{}
+                        }
+                    }
+                case "replace":
+                    // expected arguments: (--from, --to)
+                    if A24ApiClientArgs["from"] == "" || A24ApiClientArgs["to"] == "" {
+                        fmt.Println("Replace --from or --to not provided.")
+                        os.Exit(1)
+                    }
+                    lQuery, err := a24apiclient.NewDnsReplaceQuery(A24ApiClientArgs["from"])
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    var lDomains []string
+                    A24ApiResponseCode, lDomains, A24ApiResponseError = A24ApiClient.DnsSelectDomains(domainsPattern(A24ApiClientArgs["domains"]))
+                    if A24ApiResponseError != nil {
+                        break
+                    }
+                    lMatches, lFailed := A24ApiClient.DnsSearch(lDomains, lQuery, lConcurrency)
+                    for _, lDomainRecords := range lFailed {
+                        fmt.Fprintf(os.Stderr, "%s: %s\n", lDomainRecords.Domain, lDomainRecords.Err)
+                    }
+                    lChanges, err := a24apiclient.DnsPlanReplace(lMatches, A24ApiClientArgs["to"])
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    if len(lChanges) == 0 {
+                        fmt.Fprintln(os.Stderr, "No records to replace.")
+                        A24ApiResponseData = lChanges
+                        break
+                    }
+                    printChangesPreview(lChanges)
+                    if A24ApiClientArgs["yes"] != "true" && !confirm(fmt.Sprintf("Apply %d changes?", len(lChanges))) {
+                        fmt.Fprintln(os.Stderr, "Aborted.")
+                        os.Exit(1)
+                    }
+                    if A24ApiClientArgs["rollback-file"] == "" {
+                        A24ApiClientArgs["rollback-file"] = "a24api-rollback-" + time.Now().Format("20060102-150405") + ".json"
+                    }
+                    // file is created before apply so that unwritable path stops the command, it is filled afterwards
+                    if err := writeChanges(A24ApiClientArgs["rollback-file"], []a24apiclient.T_DnsChange{}); err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsApplyChanges(lChanges)
+                    // rollback file lists only changes which were applied and not rolled back
+                    var lApplied []a24apiclient.T_DnsChange
+                    for _, element := range lChanges {
+                        if element.Status() == a24apiclient.C_DnsResult_Ok {
+                            lApplied = append(lApplied, element)
+                        }
+                    }
+                    if len(lApplied) == 0 {
+                        os.Remove(A24ApiClientArgs["rollback-file"])
+                    } else if err := writeChanges(A24ApiClientArgs["rollback-file"], lApplied); err != nil {
+                        fmt.Fprintf(os.Stderr, "Rollback file %s not written: %s\n", A24ApiClientArgs["rollback-file"], err)
+                        if A24ApiResponseError == nil {
+                            A24ApiResponseError = err
+                        }
+                    } else {
+                        fmt.Fprintf(os.Stderr, "Rollback file: %s\n", A24ApiClientArgs["rollback-file"])
+                    }
+                case "revert":
+                    // expected arguments: 0=rollback_file
+                    if len(A24ApiClientFuncArgs) < 1 {
+                        fmt.Println("Rollback file not provided.")
+                        os.Exit(1)
+                    }
+                    lFile, err := os.Open(A24ApiClientFuncArgs[0])
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    lChanges, err := a24apiclient.ReadDnsChanges(lFile)
+                    lFile.Close()
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    // preview shows direction of revert
+                    var lPreview []a24apiclient.T_DnsChange
+                    for _, element := range lChanges {
+                        lPreview = append(lPreview, a24apiclient.T_DnsChange{ Action: "revert " + element.Action, Record: element.Previous, Previous: element.Record })
+                    }
+                    printChangesPreview(lPreview)
+                    if A24ApiClientArgs["yes"] != "true" && !confirm(fmt.Sprintf("Revert %d changes?", len(lChanges))) {
+                        fmt.Fprintln(os.Stderr, "Aborted.")
+                        os.Exit(1)
+                    }
+                    A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsRevertChanges(lChanges)
+                    A24ApiResponseCode = 200
+                case "snapshot":
+                    // expected arguments: 0=domain (or --all-domains, --domains)
+                    var lDomains []string
+                    if A24ApiClientArgs["domains"] != "" {
+                        A24ApiResponseCode, lDomains, A24ApiResponseError = A24ApiClient.DnsSelectDomains(domainsPattern(A24ApiClientArgs["domains"]))
+                        if A24ApiResponseError != nil {
+                            break
+                        }
+                    } else if len(A24ApiClientFuncArgs) > 0 {
+                        lDomains = A24ApiClientFuncArgs
+                    } else {
+                        fmt.Println("Domain not provided.")
+                        os.Exit(1)
+                    }
+                    var lPaths []string
+                    for _, lDomainRecords := range A24ApiClient.DnsListRecordsMulti(lDomains, lConcurrency) {
+                        if lDomainRecords.Err != nil {
+                            A24ApiClientArgs["partial"] = "true"
+                            fmt.Fprintf(os.Stderr, "%s: %s\n", lDomainRecords.Domain, lDomainRecords.Err)
+                            continue
+                        }
+                        lPath, err := a24apiclient.WriteDnsSnapshot(A24ApiClientArgs["snapshot-dir"], a24apiclient.NewDnsSnapshot(lDomainRecords.Domain, lDomainRecords.Records))
+                        if err != nil {
+                            fmt.Println(err)
+                            os.Exit(1)
+                        }
+                        lPaths = append(lPaths, lPath)
+                    }
+                    A24ApiResponseData = lPaths
+                    A24ApiResponseCode = 200
+                case "export":
+                    // expected arguments: 0=domain (or --all-domains, --domains)
+                    var lDomains []string
+                    if A24ApiClientArgs["domains"] != "" {
+                        A24ApiResponseCode, lDomains, A24ApiResponseError = A24ApiClient.DnsSelectDomains(domainsPattern(A24ApiClientArgs["domains"]))
+                        if A24ApiResponseError != nil {
+                            break
+                        }
+                    } else if len(A24ApiClientFuncArgs) > 0 {
+                        lDomains = A24ApiClientFuncArgs
+                    } else {
+                        fmt.Println("Domain not provided.")
+                        os.Exit(1)
+                    }
+                    // zone files are written as domains are listed in order, failed domains are reported and skipped
+                    lExitCode := 0
+                    for _, lDomainRecords := range A24ApiClient.DnsListRecordsMulti(lDomains, lConcurrency) {
+                        if lDomainRecords.Err != nil {
+                            lExitCode = 2
+                            fmt.Fprintf(os.Stderr, "%s: %s\n", lDomainRecords.Domain, lDomainRecords.Err)
+                            continue
+                        }
+                        var lRecords []map[string]string
+                        for _, element := range lDomainRecords.Records {
+                            lRecords = append(lRecords, a24apiclient.NewDnsRecordFromList(lDomainRecords.Domain, element))
+                        }
+                        if err := a24apiclient.WriteDnsZoneFile(os.Stdout, lDomainRecords.Domain, lRecords); err != nil {
+                            fmt.Println(err)
+                            os.Exit(1)
+                        }
+                    }
+                    os.Exit(lExitCode)
+                case "restore":
+                    // expected arguments: 0=snapshot_file|domain
+                    if len(A24ApiClientFuncArgs) < 1 {
+                        fmt.Println("Snapshot not provided.")
+                        os.Exit(1)
+                    }
+                    lPath := A24ApiClientFuncArgs[0]
+                    if _, err := os.Stat(lPath); err != nil {
+                        // argument is a domain, use its latest snapshot
+                        if lPath, err = a24apiclient.LatestDnsSnapshot(A24ApiClientArgs["snapshot-dir"], A24ApiClientFuncArgs[0]); err != nil {
+                            fmt.Println(err)
+                            os.Exit(1)
+                        }
+                    }
+                    lSnapshot, err := a24apiclient.ReadDnsSnapshot(lPath)
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    lChanges, err := A24ApiClient.DnsPlanRestoreLive(lSnapshot, A24ApiClientArgs["prune"] == "true")
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    fmt.Fprintf(os.Stderr, "Snapshot: %s (%s)\n", lPath, lSnapshot.Time.Format(time.RFC3339))
+                    A24ApiResponseCode = 200
+                    if len(lChanges) == 0 {
+                        fmt.Fprintln(os.Stderr, "Live records match snapshot.")
+                        A24ApiResponseData = lChanges
+                        break
+                    }
+                    printChangesPreview(lChanges)
+                    if A24ApiClientArgs["yes"] != "true" && !confirm(fmt.Sprintf("Apply %d changes?", len(lChanges))) {
+                        fmt.Fprintln(os.Stderr, "Aborted.")
+                        os.Exit(1)
+                    }
+                    A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsApplyChanges(lChanges)
+                case "diff":
+                    // expected arguments: 0=a, 1=b
+                    if len(A24ApiClientFuncArgs) < 2 {
+                        fmt.Println("Diff sources not provided.")
+                        os.Exit(1)
+                    }
+                    // live domains and json files first, their domain is origin for zone files without $ORIGIN
+                    lSources := make([]*a24apiclient.T_DnsSource, 2)
+                    for _, lZoneFiles := range []bool{ false, true } {
+                        for index := 0; index < 2; index++ {
+                            if isZoneFile(A24ApiClientFuncArgs[index]) != lZoneFiles {
+                                continue
+                            }
+                            lOrigin := ""
+                            if lOther := lSources[1 - index]; lOther != nil {
+                                lOrigin = lOther.Domain
+                            }
+                            lSource, err := A24ApiClient.LoadDnsSource(A24ApiClientFuncArgs[index], lOrigin)
+                            if err != nil {
+                                fmt.Println(err)
+                                os.Exit(1)
+                            }
+                            for _, lWarning := range lSource.Warnings {
+                                fmt.Fprintf(os.Stderr, "%s: %s\n", lSource.Source, lWarning)
+                            }
+                            lSources[index] = lSource
+                        }
+                    }
+                    A24ApiClientArgs["diff-a"] = lSources[0].Kind + " " + lSources[0].Source
+                    A24ApiClientArgs["diff-b"] = lSources[1].Kind + " " + lSources[1].Source
+                    A24ApiResponseData = a24apiclient.DnsDiff(lSources[0].Records, lSources[1].Records, lSources[0].Domain, lSources[1].Domain, A24ApiClientArgs["ignore-ttl"] == "true")
+                    A24ApiResponseCode = 200
+                case "copy":
+                    // expected arguments: 0=source_domain, 1=destination_domain
+                    if len(A24ApiClientFuncArgs) < 2 {
+                        fmt.Println("Source or destination domain not provided.")
+                        os.Exit(1)
+                    }
+                    lOptions := a24apiclient.T_DnsCopyOptions{ Rewrite: A24ApiClientArgs["rewrite"] == "true", OnConflict: A24ApiClientArgs["on-conflict"] }
+                    if lOptions.TypeFilter, err = regexp.Compile(A24ApiClientArgs["filter-type"]); err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    if lOptions.NameFilter, err = regexp.Compile(A24ApiClientArgs["filter-name"]); err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    lChanges, lSkipped, err := A24ApiClient.DnsPlanCopyLive(A24ApiClientFuncArgs[0], A24ApiClientFuncArgs[1], lOptions)
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    for _, element := range lSkipped {
+                        fmt.Fprintf(os.Stderr, "Skipped conflicting rrset %s.\n", element)
+                    }
+                    A24ApiResponseCode = 200
+                    if len(lChanges) == 0 {
+                        fmt.Fprintln(os.Stderr, "Nothing to copy.")
+                        A24ApiResponseData = lChanges
+                        break
+                    }
+                    printChangesPreview(lChanges)
+                    if A24ApiClientArgs["yes"] != "true" && !confirm(fmt.Sprintf("Apply %d changes?", len(lChanges))) {
+                        fmt.Fprintln(os.Stderr, "Aborted.")
+                        os.Exit(1)
+                    }
+                    A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsApplyChanges(lChanges)
+                case "preset":
+                    // expected arguments: 0=list|apply, (1=domain, 2=preset) or (1=preset with --all-domains, --domains)
+                    if len(A24ApiClientFuncArgs) < 1 {
+                        fmt.Println("Preset function not provided.")
+                        os.Exit(1)
+                    }
+                    A24ApiClientArgs["preset"] = A24ApiClientFuncArgs[0]
+                    A24ApiResponseCode = 200
+                    if A24ApiClientArgs["preset"] == "list" {
+                        A24ApiResponseData = a24apiclient.DnsPresets()
+                        break
+                    }
+                    if A24ApiClientArgs["preset"] != "apply" {
+                        fmt.Printf("Unsupported preset function: %s.\n", A24ApiClientArgs["preset"])
+                        os.Exit(1)
+                    }
+                    lPresetArg := ""
+                    if A24ApiClientArgs["domains"] != "" && len(A24ApiClientFuncArgs) > 1 {
+                        lPresetArg = A24ApiClientFuncArgs[1]
+                    } else if A24ApiClientArgs["domains"] == "" && len(A24ApiClientFuncArgs) > 2 {
+                        A24ApiClientArgs["domain"] = A24ApiClientFuncArgs[1]
+                        lPresetArg = A24ApiClientFuncArgs[2]
+                    } else {
+                        fmt.Println("Domain or preset not provided.")
+                        os.Exit(1)
+                    }
+                    lPreset, err := a24apiclient.LoadDnsPreset(lPresetArg)
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    lValues := make(map[string]string)
+                    for _, element := range strings.Split(A24ApiClientArgs["var"], "\n") {
+                        if element == "" {
+                            continue
+                        }
+                        lPair := strings.SplitN(element, "=", 2)
+                        if len(lPair) != 2 {
+                            fmt.Printf("Invalid variable: %s (expected name=value).\n", element)
+                            os.Exit(1)
+                        }
+                        lValues[lPair[0]] = lPair[1]
+                    }
+                    var lChanges []a24apiclient.T_DnsChange
+                    var lKept []map[string]string
+                    if A24ApiClientArgs["domains"] != "" {
+                        var lDomains []string
+                        A24ApiResponseCode, lDomains, A24ApiResponseError = A24ApiClient.DnsSelectDomains(domainsPattern(A24ApiClientArgs["domains"]))
+                        if A24ApiResponseError != nil {
+                            break
+                        }
+                        lChanges, lKept, err = A24ApiClient.DnsPlanPresetMulti(lDomains, lPreset, lValues, lConcurrency)
+                    } else {
+                        lChanges, lKept, err = A24ApiClient.DnsPlanPreset(A24ApiClientArgs["domain"], lPreset, lValues)
+                    }
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    for _, element := range lKept {
+                        fmt.Fprintf(os.Stderr, "Kept existing %s %s %s in %s (not in preset)\n", element["Type"], element["Name"], a24apiclient.DnsRecordValue(element), element["Domain"])
+                    }
+                    if len(lChanges) == 0 {
+                        fmt.Fprintf(os.Stderr, "Preset %s is already applied.\n", lPreset.Name)
+                        A24ApiResponseData = lChanges
+                        break
+                    }
+                    printChangesPreview(lChanges)
+                    if A24ApiClientArgs["yes"] != "true" && !confirm(fmt.Sprintf("Apply %d changes of preset %s?", len(lChanges), lPreset.Name)) {
+                        fmt.Fprintln(os.Stderr, "Aborted.")
+                        os.Exit(1)
+                    }
+                    A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsApplyChanges(lChanges)
+                default:
+                    fmt.Printf("Unsupported function: %s.\n", A24ApiClientArgs["function"])
+                    os.Exit(1)
+            }
+        case "spf":
+            // expected arguments: 0=domain, (1=name)
+            if len(A24ApiClientFuncArgs) < 1 {
+                fmt.Println("Domain not provided.")
+                os.Exit(1)
+            }
+            A24ApiClientArgs["domain"] = A24ApiClientFuncArgs[0]
+            lName := a24apiclient.C_DnsName_Apex
+            if len(A24ApiClientFuncArgs) > 1 {
+                lName = A24ApiClientFuncArgs[1]
+            }
+            lResolver := A24ApiClient.NewDnsResolver(a24apiclient.NewNetResolver(A24ApiClientArgs["resolver"]))
+            A24ApiResponseCode = 200
+            switch A24ApiClientArgs["function"] {
+                case "show":
+                    var lSpf *a24apiclient.T_SpfRecord
+                    if lSpf, A24ApiResponseError = lResolver.Spf(A24ApiClientArgs["domain"], lName); A24ApiResponseError == nil {
+                        A24ApiResponseData = lSpf
+                    }
+                case "lint":
+                    var lLint *a24apiclient.T_SpfLint
+                    if lLint, A24ApiResponseError = lResolver.LintSpf(A24ApiClientArgs["domain"], lName); A24ApiResponseError == nil {
+                        A24ApiResponseData = lLint
+                    }
+                case "flatten":
+                    lFlatten, err := lResolver.FlattenSpf(A24ApiClientArgs["domain"], lName)
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    if lFlatten.Flattened == lFlatten.Text {
+                        fmt.Fprintln(os.Stderr, "SPF record is already flat.")
+                        A24ApiResponseData = []a24apiclient.T_DnsChange{}
+                        break
+                    }
+                    // flattened value is written by TXT update, long values are split into strings
+                    lRecord := make(map[string]string)
+                    for key, value := range lFlatten.Record {
+                        lRecord[key] = value
+                    }
+                    lRecord["Text"] = lFlatten.Flattened
+                    lChanges := []a24apiclient.T_DnsChange{ { Action: "update", Record: lRecord, Previous: lFlatten.Record } }
+                    fmt.Fprintf(os.Stderr, "Flattened SPF record has %d DNS lookups:\n%s\n", lFlatten.Lookups, lFlatten.Flattened)
+                    printChangesPreview(lChanges)
+                    if A24ApiClientArgs["yes"] != "true" && !confirm("Update SPF record?") {
+                        fmt.Fprintln(os.Stderr, "Aborted.")
+                        os.Exit(1)
+                    }
+                    A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsApplyChanges(lChanges)
+                default:
+                    fmt.Printf("Unsupported function: %s.\n", A24ApiClientArgs["function"])
+                    os.Exit(1)
+            }
+        case "dkim":
+            // expected arguments: 0=domain, (1=selector)
+            if len(A24ApiClientFuncArgs) < 1 {
+                fmt.Println("Domain not provided.")
+                os.Exit(1)
+            }
+            A24ApiClientArgs["domain"] = A24ApiClientFuncArgs[0]
+            A24ApiResponseCode = 200
+            switch A24ApiClientArgs["function"] {
+                case "list":
+                    var lSelectors []a24apiclient.T_DkimSelector
+                    if lSelectors, A24ApiResponseError = A24ApiClient.DkimSelectors(A24ApiClientArgs["domain"]); A24ApiResponseError == nil {
+                        A24ApiResponseData = lSelectors
+                    }
+                case "rotate":
+                    if len(A24ApiClientFuncArgs) < 2 {
+                        fmt.Println("Selector not provided.")
+                        os.Exit(1)
+                    }
+                    // deleting live selector breaks verification of mail in transit, so it must be asked for
+                    if A24ApiClientArgs["retire"] != "" && A24ApiClientArgs["grace"] == "" {
+                        fmt.Println("Grace period not provided, use --grace <duration> to keep old selector live or --grace 0 to delete it now.")
+                        os.Exit(1)
+                    }
+                    lGrace, err := a24apiclient.ParseDkimGrace(A24ApiClientArgs["grace"])
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    lBits := 0
+                    if A24ApiClientArgs["bits"] != "" {
+                        if lBits, err = strconv.Atoi(A24ApiClientArgs["bits"]); err != nil {
+                            fmt.Printf("Invalid key size: %s.\n", A24ApiClientArgs["bits"])
+                            os.Exit(1)
+                        }
+                    }
+                    if A24ApiClientArgs["algorithm"] == "" {
+                        A24ApiClientArgs["algorithm"] = a24apiclient.C_Dkim_Rsa
+                    }
+                    lKey, err := a24apiclient.NewDkimKey(A24ApiClientArgs["algorithm"], lBits)
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    lNow := time.Now()
+                    lChanges, err := A24ApiClient.DkimRotate(A24ApiClientArgs["domain"], A24ApiClientFuncArgs[1], lKey, A24ApiClientArgs["retire"], lGrace, lNow)
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    if A24ApiClientArgs["key-file"] == "" {
+                        A24ApiClientArgs["key-file"] = fmt.Sprintf("%s-%s.key", A24ApiClientArgs["domain"], strings.TrimSuffix(lChanges[0].Record["Name"], a24apiclient.C_Dkim_Suffix))
+                    }
+                    printChangesPreview(lChanges)
+                    if A24ApiClientArgs["yes"] != "true" && !confirm(fmt.Sprintf("Publish DKIM key and write private key to %s?", A24ApiClientArgs["key-file"])) {
+                        fmt.Fprintln(os.Stderr, "Aborted.")
+                        os.Exit(1)
+                    }
+                    // private key is stored before the public key is published, so a published key is never lost
+                    lPem, err := lKey.PrivateKeyPem()
+                    if err == nil {
+                        err = writeKeyFile(A24ApiClientArgs["key-file"], lPem)
+                    }
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    fmt.Fprintf(os.Stderr, "Private key written to %s.\n", A24ApiClientArgs["key-file"])
+                    A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsApplyChanges(lChanges)
+                    if A24ApiResponseError == nil && len(lChanges) > 1 && lChanges[1].Action == "update" {
+                        fmt.Fprintf(os.Stderr, "Selector %s stays live until %s, then run dkim prune %s.\n", a24apiclient.DnsNameRelative(A24ApiClientArgs["retire"], ""), lNow.Add(lGrace).UTC().Format(time.RFC3339), A24ApiClientArgs["domain"])
+                    }
+                case "prune":
+                    lChanges, err := A24ApiClient.DkimPrune(A24ApiClientArgs["domain"], time.Now())
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    if len(lChanges) == 0 {
+                        fmt.Fprintln(os.Stderr, "No selectors to retire.")
+                        A24ApiResponseData = lChanges
+                        break
+                    }
+                    printChangesPreview(lChanges)
+                    if A24ApiClientArgs["yes"] != "true" && !confirm(fmt.Sprintf("Delete %d DKIM selectors?", len(lChanges))) {
+                        fmt.Fprintln(os.Stderr, "Aborted.")
+                        os.Exit(1)
+                    }
+                    A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsApplyChanges(lChanges)
+                default:
+                    fmt.Printf("Unsupported function: %s.\n", A24ApiClientArgs["function"])
+                    os.Exit(1)
+            }
+        case "mailauth":
+            // expected arguments: 0=domain
+            if len(A24ApiClientFuncArgs) < 1 {
+                fmt.Println("Domain not provided.")
+                os.Exit(1)
+            }
+            A24ApiClientArgs["domain"] = A24ApiClientFuncArgs[0]
+            lResolver := A24ApiClient.NewDnsResolver(a24apiclient.NewNetResolver(A24ApiClientArgs["resolver"]))
+            A24ApiResponseCode = 200
+            if A24ApiClientArgs["function"] == "show" {
+                var lMailAuth *a24apiclient.T_MailAuth
+                if lMailAuth, A24ApiResponseError = lResolver.MailAuth(A24ApiClientArgs["domain"]); A24ApiResponseError == nil {
+                    A24ApiResponseData = lMailAuth
+                }
+                break
+            }
+            var lText string
+            switch A24ApiClientArgs["function"] {
+                case "dmarc":
+                    lCurrent, err := lResolver.MailAuthRecord(A24ApiClientArgs["domain"], a24apiclient.C_MailAuth_Dmarc)
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    lOptions := make(map[string]string)
+                    for _, lTag := range []string{ "policy", "sp", "pct", "rua", "ruf", "adkim", "aspf" } {
+                        if lValue, isPresent := A24ApiClientArgs["tag-" + lTag]; isPresent {
+                            lOptions[strings.Replace(lTag, "policy", "p", 1)] = lValue
+                        }
+                    }
+                    lText = a24apiclient.NewDmarc(lCurrent.Text, lOptions)
+                case "mta-sts":
+                    lText = a24apiclient.NewMtaSts(A24ApiClientArgs["id"], time.Now())
+                case "tls-rpt":
+                    lText = a24apiclient.NewTlsRpt(A24ApiClientArgs["tag-rua"])
+                default:
+                    fmt.Printf("Unsupported function: %s.\n", A24ApiClientArgs["function"])
+                    os.Exit(1)
+            }
+            lChanges, err := lResolver.MailAuthChanges(A24ApiClientArgs["domain"], A24ApiClientArgs["function"], lText)
+            if err != nil {
+                fmt.Println(err)
+                os.Exit(1)
+            }
+            if len(lChanges) == 0 {
+                fmt.Fprintf(os.Stderr, "Record is already published: %s\n", lText)
+                A24ApiResponseData = lChanges
+                break
+            }
+            for _, lFinding := range a24apiclient.ValidateMailAuth(A24ApiClientArgs["function"], lText) {
+                fmt.Fprintf(os.Stderr, "%s: %s %s\n", lFinding.Level, lFinding.Term, lFinding.Message)
+            }
+            printChangesPreview(lChanges)
+            if A24ApiClientArgs["yes"] != "true" && !confirm(fmt.Sprintf("Publish %s record?", A24ApiClientArgs["function"])) {
+                fmt.Fprintln(os.Stderr, "Aborted.")
+                os.Exit(1)
+            }
+            A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsApplyChanges(lChanges)
+        case "tlsa":
+            // expected arguments: 0=domain, 1=name, 2=port
+            if len(A24ApiClientFuncArgs) < 3 {
+                fmt.Println("Domain, name or port not provided.")
+                os.Exit(1)
+            }
+            A24ApiClientArgs["domain"] = A24ApiClientFuncArgs[0]
+            if A24ApiClientArgs["proto"] == "" {
+                A24ApiClientArgs["proto"] = a24apiclient.C_Tlsa_Proto
+            }
+            lName, err := a24apiclient.TlsaName(A24ApiClientArgs["domain"], A24ApiClientFuncArgs[1], A24ApiClientFuncArgs[2], A24ApiClientArgs["proto"])
+            if err != nil {
+                fmt.Println(err)
+                os.Exit(1)
+            }
+            var lSource *a24apiclient.T_TlsaSource
+            if A24ApiClientArgs["cert"] != "" {
+                lSource, err = a24apiclient.LoadTlsaSource(A24ApiClientArgs["cert"])
+            } else if A24ApiClientArgs["connect"] != "" {
+                lSource, err = a24apiclient.FetchTlsaSource(A24ApiClientArgs["connect"], A24ApiClientArgs["starttls"])
+            } else {
+                fmt.Println("Certificate not provided (--cert or --connect).")
+                os.Exit(1)
+            }
+            if err != nil {
+                fmt.Println(err)
+                os.Exit(1)
+            }
+            A24ApiResponseCode = 200
+            switch A24ApiClientArgs["function"] {
+                case "generate":
+                    if A24ApiClientArgs["params"] == "" {
+                        A24ApiClientArgs["params"] = a24apiclient.C_Tlsa_Params
+                    }
+                    if A24ApiClientArgs["ttl"] == "" {
+                        A24ApiClientArgs["ttl"] = a24apiclient.C_Tlsa_Ttl
+                    }
+                    lParams, err := a24apiclient.ParseTlsaParams(A24ApiClientArgs["params"])
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    lRecords, err := a24apiclient.NewTlsaRecords(A24ApiClientArgs["domain"], lName, A24ApiClientArgs["ttl"], lSource, lParams)
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    lChanges, lKept, err := A24ApiClient.DnsPlanTlsa(A24ApiClientArgs["domain"], lName, lRecords, A24ApiClientArgs["replace-rrset"] == "true")
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    for _, element := range lKept {
+                        fmt.Fprintf(os.Stderr, "Kept existing %s %s %s (use --replace to remove)\n", element["Type"], element["Name"], a24apiclient.DnsRecordValue(element))
+                    }
+                    if len(lChanges) == 0 {
+                        fmt.Fprintln(os.Stderr, "TLSA records are already published.")
+                        A24ApiResponseData = lChanges
+                        break
+                    }
+                    printChangesPreview(lChanges)
+                    if A24ApiClientArgs["yes"] != "true" && !confirm(fmt.Sprintf("Apply %d changes?", len(lChanges))) {
+                        fmt.Fprintln(os.Stderr, "Aborted.")
+                        os.Exit(1)
+                    }
+                    A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsApplyChanges(lChanges)
+                case "check":
+                    var lRecords []map[string]string
+                    if A24ApiResponseCode, lRecords, A24ApiResponseError = A24ApiClient.DnsGetRRset(A24ApiClientArgs["domain"], "TLSA", lName); A24ApiResponseError == nil {
+                        A24ApiResponseData = a24apiclient.CheckTlsa(lRecords, lSource)
+                    }
+                default:
+                    fmt.Printf("Unsupported function: %s.\n", A24ApiClientArgs["function"])
+                    os.Exit(1)
+            }
+        case "sshfp":
+            // expected arguments: 0=domain, 1=host, (2...=key files)
+            if len(A24ApiClientFuncArgs) < 2 {
+                fmt.Println("Domain or host not provided.")
+                os.Exit(1)
+            }
+            if A24ApiClientArgs["function"] != "sync" {
+                fmt.Printf("Unsupported function: %s.\n", A24ApiClientArgs["function"])
+                os.Exit(1)
+            }
+            A24ApiClientArgs["domain"] = A24ApiClientFuncArgs[0]
+            if A24ApiClientArgs["fp-type"] == "" {
+                A24ApiClientArgs["fp-type"] = a24apiclient.C_Sshfp_FingerprintTypes
+            }
+            if A24ApiClientArgs["ttl"] == "" {
+                A24ApiClientArgs["ttl"] = a24apiclient.C_Sshfp_Ttl
+            }
+            lTypes, err := a24apiclient.ParseSshfpFingerprintTypes(A24ApiClientArgs["fp-type"])
+            if err != nil {
+                fmt.Println(err)
+                os.Exit(1)
+            }
+            lFiles := A24ApiClientFuncArgs[2:]
+            if len(lFiles) == 0 {
+                lFiles, _ = filepath.Glob("/etc/ssh/ssh_host_*_key.pub")
+            }
+            // keys read from stdin leave it at EOF, confirmation is read from terminal
+            for _, element := range lFiles {
+                if element == "-" && A24ApiClientArgs["yes"] != "true" {
+                    lTty, err := os.Open("/dev/tty")
+                    if err != nil {
+                        fmt.Println("Host keys read from stdin (-) require --yes when there is no terminal to confirm changes.")
+                        os.Exit(1)
+                    }
+                    defer lTty.Close()
+                    A24ApiClientConfirmInput = lTty
+                    break
+                }
+            }
+            var lData []byte
+            for _, element := range lFiles {
+                var lFile []byte
+                if element == "-" {
+                    lFile, err = ioutil.ReadAll(os.Stdin)
+                } else {
+                    lFile, err = ioutil.ReadFile(element)
+                }
+                if err != nil {
+                    fmt.Println(err)
+                    os.Exit(1)
+                }
+                lData = append(append(lData, lFile...), '\n')
+            }
+            lKeys, err := a24apiclient.ParseSshHostKeys(lData)
+            if err != nil {
+                fmt.Println(err)
+                os.Exit(1)
+            }
+            lRecords, err := a24apiclient.NewSshfpRecords(A24ApiClientArgs["domain"], A24ApiClientFuncArgs[1], A24ApiClientArgs["ttl"], lKeys, lTypes)
+            if err != nil {
+                fmt.Println(err)
+                os.Exit(1)
+            }
+            lChanges, err := A24ApiClient.DnsPlanSshfp(A24ApiClientArgs["domain"], A24ApiClientFuncArgs[1], lRecords)
+            if err != nil {
+                fmt.Println(err)
+                os.Exit(1)
+            }
+            A24ApiResponseCode = 200
+            if len(lChanges) == 0 {
+                fmt.Fprintln(os.Stderr, "SSHFP records are up to date.")
+                A24ApiResponseData = lChanges
+                break
+            }
+            printChangesPreview(lChanges)
+            if A24ApiClientArgs["yes"] != "true" && !confirm(fmt.Sprintf("Apply %d changes?", len(lChanges))) {
+                fmt.Fprintln(os.Stderr, "Aborted.")
+                os.Exit(1)
+            }
+            A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsApplyChanges(lChanges)
+        case "caa":
+            // expected arguments: 0=domain, (1=name)
+            if len(A24ApiClientFuncArgs) < 1 {
+                fmt.Println("Domain not provided.")
+                os.Exit(1)
+            }
+            A24ApiClientArgs["domain"] = A24ApiClientFuncArgs[0]
+            lName := a24apiclient.C_DnsName_Apex
+            if len(A24ApiClientFuncArgs) > 1 {
+                lName = A24ApiClientFuncArgs[1]
+            }
+            A24ApiResponseCode = 200
+            switch A24ApiClientArgs["function"] {
+                case "show":
+                    lDomain, lZone, err := A24ApiClient.DnsZoneRecords(A24ApiClientArgs["domain"])
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    if lName, err = a24apiclient.NormaliseDnsName(lName, lDomain); err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    A24ApiResponseData = a24apiclient.NewCaaPolicy(lDomain, lName, lZone, nil)
+                case "set":
+                    if len(A24ApiClientFuncArgs) < 2 {
+                        fmt.Println("Name not provided.")
+                        os.Exit(1)
+                    }
+                    lOptions := a24apiclient.T_CaaOptions{ AccountUri: A24ApiClientArgs["account-uri"], ValidationMethods: A24ApiClientArgs["validation-methods"], Ttl: A24ApiClientArgs["ttl"], Reset: A24ApiClientArgs["reset"] == "true" }
+                    if lValue, isPresent := A24ApiClientArgs["caa-issue"]; isPresent {
+                        lOptions.Issue = strings.Split(lValue, "\n")
+                    }
+                    if lValue, isPresent := A24ApiClientArgs["caa-issuewild"]; isPresent {
+                        lOptions.IssueWild = strings.Split(lValue, "\n")
+                    }
+                    if lValue, isPresent := A24ApiClientArgs["caa-iodef"]; isPresent {
+                        lOptions.Iodef = strings.Split(lValue, "\n")
+                    }
+                    lChanges, lPolicy, err := A24ApiClient.DnsPlanCaa(A24ApiClientArgs["domain"], lName, lOptions)
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    if len(lChanges) == 0 {
+                        fmt.Fprintln(os.Stderr, "CAA records are already published.")
+                        A24ApiResponseData = lChanges
+                        break
+                    }
+                    for _, lFinding := range lPolicy.Findings {
+                        fmt.Fprintf(os.Stderr, "%s: %s\n", lFinding.Level, strings.TrimSpace(lFinding.Term + " " + lFinding.Message))
+                    }
+                    printChangesPreview(lChanges)
+                    if A24ApiClientArgs["yes"] != "true" && !confirm(fmt.Sprintf("Apply %d changes?", len(lChanges))) {
+                        fmt.Fprintln(os.Stderr, "Aborted.")
+                        os.Exit(1)
+                    }
+                    A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsApplyChanges(lChanges)
                 default:
                     fmt.Printf("Unsupported function: %s.\n", A24ApiClientArgs["function"])
                     os.Exit(1)
             }
+        case "srv":
+            A24ApiResponseCode = 200
+            if A24ApiClientArgs["function"] == "services" {
+                A24ApiResponseData = a24apiclient.C_Srv_Services
+                break
+            }
+            // expected arguments: 0=domain, (1=name)
+            if len(A24ApiClientFuncArgs) < 1 {
+                fmt.Println("Domain not provided.")
+                os.Exit(1)
+            }
+            A24ApiClientArgs["domain"] = A24ApiClientFuncArgs[0]
+            if A24ApiClientArgs["function"] == "list" {
+                var lEntries []a24apiclient.T_SrvEntry
+                if lEntries, A24ApiResponseError = A24ApiClient.DnsSrvEntries(A24ApiClientArgs["domain"]); A24ApiResponseError == nil {
+                    A24ApiResponseData = lEntries
+                }
+                break
+            }
+            if A24ApiClientArgs["function"] != "set" && A24ApiClientArgs["function"] != "delete" {
+                fmt.Printf("Unsupported function: %s.\n", A24ApiClientArgs["function"])
+                os.Exit(1)
+            }
+            if len(A24ApiClientFuncArgs) < 2 || A24ApiClientArgs["srv-service"] == "" {
+                fmt.Println("Name or service (--service) not provided.")
+                os.Exit(1)
+            }
+            lService := a24apiclient.SrvService(A24ApiClientArgs["srv-service"], A24ApiClientArgs["proto"])
+            lName, err := a24apiclient.SrvName(A24ApiClientArgs["domain"], A24ApiClientFuncArgs[1], lService.Service, lService.Proto)
+            if err != nil {
+                fmt.Println(err)
+                os.Exit(1)
+            }
+            var lRecords []map[string]string
+            if A24ApiClientArgs["function"] == "set" {
+                if A24ApiClientArgs["srv-port"] != "" {
+                    if lService.Port, err = strconv.Atoi(A24ApiClientArgs["srv-port"]); err != nil || lService.Port < 1 || lService.Port > 65535 {
+                        fmt.Printf("Invalid port %s.\n", A24ApiClientArgs["srv-port"])
+                        os.Exit(1)
+                    }
+                }
+                lPriority := a24apiclient.C_Srv_Priority
+                if A24ApiClientArgs["srv-priority"] != "" {
+                    if lPriority, err = strconv.Atoi(A24ApiClientArgs["srv-priority"]); err != nil || lPriority < 0 || lPriority > 65535 {
+                        fmt.Printf("Invalid priority %s.\n", A24ApiClientArgs["srv-priority"])
+                        os.Exit(1)
+                    }
+                }
+                if A24ApiClientArgs["ttl"] == "" {
+                    A24ApiClientArgs["ttl"] = a24apiclient.C_Srv_Ttl
+                }
+                var lTargets []a24apiclient.T_SrvTarget
+                for _, element := range strings.Split(A24ApiClientArgs["target"], "\n") {
+                    if element == "" {
+                        continue
+                    }
+                    lTarget, err := a24apiclient.ParseSrvTarget(element, lService.Port, lPriority)
+                    if err != nil {
+                        fmt.Println(err)
+                        os.Exit(1)
+                    }
+                    lTargets = append(lTargets, lTarget)
+                }
+                if lRecords, err = a24apiclient.NewSrvRecords(A24ApiClientArgs["domain"], lName, A24ApiClientArgs["ttl"], lTargets); err != nil {
+                    fmt.Println(err)
+                    os.Exit(1)
+                }
+            }
+            lResolver := A24ApiClient.NewDnsResolver(a24apiclient.NewNetResolver(A24ApiClientArgs["resolver"]))
+            lChanges, lFindings, err := lResolver.DnsPlanSrv(A24ApiClientArgs["domain"], lName, lRecords)
+            if err != nil {
+                fmt.Println(err)
+                os.Exit(1)
+            }
+            lErrors := 0
+            for _, lFinding := range lFindings {
+                fmt.Fprintf(os.Stderr, "%s: %s %s\n", lFinding.Level, lFinding.Domain, strings.TrimSpace(lFinding.Term + " " + lFinding.Message))
+                if lFinding.Level == a24apiclient.C_SpfLint_Error {
+                    lErrors++
+                }
+            }
+            if lErrors > 0 {
+                os.Exit(1)
+            }
+            if len(lChanges) == 0 {
+                fmt.Fprintln(os.Stderr, "SRV records are already published.")
+                A24ApiResponseData = lChanges
+                break
+            }
+            printChangesPreview(lChanges)
+            if A24ApiClientArgs["yes"] != "true" && !confirm(fmt.Sprintf("Apply %d changes?", len(lChanges))) {
+                fmt.Fprintln(os.Stderr, "Aborted.")
+                os.Exit(1)
+            }
+            A24ApiResponseData, A24ApiResponseError = A24ApiClient.DnsApplyChanges(lChanges)
         default:
-            fmt.Printf("Unsupported service: %s.", A24ApiClientArgs["service"])
+            fmt.Printf("Unsupported service: %s.\n", A24ApiClientArgs["service"])
             os.Exit(1)
     }
 
+    lDuration := time.Since(lStarted)
+
+    // internationalised names are shown in unicode on request
+    A24ApiResponseData = idnOutput(A24ApiClientArgs, A24ApiResponseData)
+    if A24ApiClientArgs["idn"] == "unicode" {
+        A24ApiResponseRecord = a24apiclient.DnsRecordToUnicode(A24ApiResponseRecord)
+        A24ApiClientArgs["domain"] = a24apiclient.DnsIdnToUnicode(A24ApiClientArgs["domain"])
+    }
+
+    // result formats report errors in results
+    if A24ApiResponseError != nil && A24ApiClientArgs["format"] != "result" && A24ApiClientArgs["format"] != "ndjson" {
+        // changes are listed with their status first, error of apply follows
+        if lChanges, isChanges := A24ApiResponseData.([]a24apiclient.T_DnsChange); isChanges && len(lChanges) > 0 {
+            if err := renderChanges(os.Stdout, A24ApiClient, A24ApiClientArgs, lChanges); err != nil {
+                fmt.Println(err)
+            }
+        }
+        fmt.Println(A24ApiResponseError)
+        os.Exit(1)
+    }
+
 // ================================================================================================================================================================
 // PROCESS RESPONSE
 // ================================================================================================================================================================
 
+    // code text of upsert follows the action taken
+    lCodeFunction := A24ApiClientArgs["function"]
+    if lCodeFunction == "upsert" {
+        lCodeFunction = A24ApiResponseAction
+    }
+    lCodeText := A24ApiClient.GetCodeText(A24ApiResponseCode, A24ApiClientArgs["service"], lCodeFunction)
+
+    // prepare regexp
+    a24api_filter_name, err := regexp.Compile(A24ApiClientArgs["filter-name"])
+    if err != nil {
+        fmt.Println(err)
+        os.Exit(1)
+    }
+    a24api_filter_type, err := regexp.Compile(A24ApiClientArgs["filter-type"])
+    if err != nil {
+        fmt.Println(err)
+        os.Exit(1)
+    }
+    a24api_filter_value, err := regexp.Compile(A24ApiClientArgs["filter-value"])
+    if err != nil {
+        fmt.Println(err)
+        os.Exit(1)
+    }
+
     if A24ApiClientArgs["format"] == "json" {
+        if A24ApiClientArgs["function"] == "upsert" {
+            A24ApiResponseData = map[string]interface{}{ "action": A24ApiResponseAction, "code": A24ApiResponseCode, "codeText": lCodeText }
+            A24ApiResponseBody = nil
+        }
         var pretty_json bytes.Buffer
-        json.Indent(&pretty_json, A24ApiResponseBody, "", "    ")
+        if A24ApiResponseBody != nil {
+            json.Indent(&pretty_json, A24ApiResponseBody, "", "    ")
+        } else {
+            lJson, _ := json.MarshalIndent(A24ApiResponseData, "", "    ")
+            pretty_json.Write(lJson)
+        }
         fmt.Printf("%s\n", string(pretty_json.Bytes()))
+        os.Exit(resultExitCode(A24ApiResponseData, A24ApiClientArgs["partial"] == "true"))
+    } else if A24ApiClientArgs["format"] == "yaml" || A24ApiClientArgs["format"] == "csv" || A24ApiClientArgs["format"] == "tsv" || (A24ApiClientArgs["format"] == "table" && A24ApiClientArgs["function"] != "diff") {
+        lOptions, err := outputOptions(A24ApiClientArgs)
+        if err != nil {
+            fmt.Println(err)
+            os.Exit(1)
+        }
+        if (A24ApiResponseCode != 200) && (A24ApiResponseCode != 204) {
+            fmt.Printf("%d %s\n", A24ApiResponseCode, lCodeText)
+            os.Exit(2)
+        }
+        lRecords, isListing := listingRecords(A24ApiResponseData, A24ApiClientArgs["domain"], a24api_filter_name, a24api_filter_type, a24api_filter_value)
+        if lDomains, isDomainList := A24ApiResponseData.(a24apiclient.T_DnsDomainList); isDomainList {
+            // expected structure [ "domainA", "domainB" ]
+            var lRows [][]interface{}
+            for _, element := range lDomains {
+                if a24api_filter_name.MatchString(element) {
+                    lRows = append(lRows, []interface{}{ element })
+                }
+            }
+            err = renderRows(os.Stdout, A24ApiClientArgs["format"], []t_outputColumn{ { Header: "domain" } }, lRows, lOptions)
+        } else if isListing {
+            err = renderRecords(os.Stdout, A24ApiClientArgs["format"], lRecords, lOptions)
+        } else {
+            err = renderData(os.Stdout, A24ApiClientArgs["format"], responseResult(A24ApiResponseData, A24ApiResponseAction, A24ApiResponseCode, lCodeText), lOptions)
+        }
+        if err != nil {
+            fmt.Println(err)
+            os.Exit(1)
+        }
+        os.Exit(resultExitCode(A24ApiResponseData, A24ApiClientArgs["partial"] == "true"))
+    } else if A24ApiClientArgs["format"] == "result" || A24ApiClientArgs["format"] == "ndjson" {
+        var lResults []a24apiclient.T_DnsResult
+        switch structured_data := A24ApiResponseData.(type) {
+            case []a24apiclient.T_DnsBatchResult:
+                for _, element := range structured_data {
+                    lResults = append(lResults, batchResult(A24ApiClient, element))
+                }
+            case []a24apiclient.T_DnsDomainRecords:
+                for _, element := range structured_data {
+                    lResults = append(lResults, a24apiclient.NewDnsResultFromDomainRecords(A24ApiClientArgs["function"], element, A24ApiClient.GetCodeText(element.Code, "dns", "list")))
+                }
+            case []a24apiclient.T_DnsChange:
+                // error belongs to the last attempted change, changes after it were not attempted
+                lFailed := -1
+                if A24ApiResponseError != nil {
+                    for index, element := range structured_data {
+                        if !element.StartedAt.IsZero() {
+                            lFailed = index
+                        }
+                    }
+                }
+                for index, element := range structured_data {
+                    var lErr error
+                    if index == lFailed {
+                        lErr = A24ApiResponseError
+                    }
+                    r := a24apiclient.NewDnsResultFromChange(A24ApiClientArgs["function"], element, A24ApiClient.GetCodeText(element.Code, "dns", element.Action), lErr)
+                    if r.Status == a24apiclient.C_DnsResult_Ok && element.Action == "create" && A24ApiResponseError == nil {
+                        r.HashId, _ = A24ApiClient.DnsResolveHashId(element.Record)
+                    }
+                    lResults = append(lResults, r)
+                }
+                if lFailed < 0 && A24ApiResponseError != nil {
+                    r := a24apiclient.NewDnsResult(A24ApiClientArgs["function"], A24ApiClientArgs["domain"], nil, lStarted)
+                    r.Finish(A24ApiResponseCode, lCodeText, nil, lDuration, A24ApiResponseError)
+                    lResults = append(lResults, r)
+                }
+            default:
+                r := a24apiclient.NewDnsResult(A24ApiClientArgs["function"], A24ApiClientArgs["domain"], A24ApiResponseRecord, lStarted)
+                r.Action = A24ApiResponseAction
+                r.Data = resultData(A24ApiResponseData)
+                r.Finish(A24ApiResponseCode, lCodeText, A24ApiResponseBody, lDuration, A24ApiResponseError)
+                if r.Status == a24apiclient.C_DnsResult_Ok && (A24ApiClientArgs["function"] == "create" || A24ApiResponseAction == a24apiclient.C_DnsUpsert_Create) {
+                    r.HashId, _ = A24ApiClient.DnsResolveHashId(A24ApiResponseRecord)
+                }
+                lResults = append(lResults, r)
+        }
+        if A24ApiClientArgs["format"] == "ndjson" {
+            if A24ApiClientArgs["streamed"] != "true" {
+                for _, element := range lResults {
+                    printResultLine(element)
+                }
+            }
+        } else {
+            if lResults == nil {
+                lResults = []a24apiclient.T_DnsResult{}
+            }
+            lJson, _ := json.MarshalIndent(a24apiclient.T_DnsResultDocument{ Schema: a24apiclient.C_DnsResult_Schema, Results: lResults }, "", "    ")
+            fmt.Printf("%s\n", string(lJson))
+        }
+        lExitCode := resultExitCode(A24ApiResponseData, A24ApiClientArgs["partial"] == "true")
+        for _, element := range lResults {
+            if element.Status == a24apiclient.C_DnsResult_Error && lExitCode == 0 {
+                lExitCode = 2
+            }
+        }
+        os.Exit(lExitCode)
+    } else if A24ApiClientArgs["format"] == "template" || A24ApiClientArgs["format"] == "jsonpath" {
+        if (A24ApiResponseCode != 200) && (A24ApiResponseCode != 204) {
+            fmt.Printf("%d %s\n", A24ApiResponseCode, lCodeText)
+            os.Exit(2)
+        }
+        // records are passed as typed structs (T_DnsRecordA, T_DnsRecordMX, ...), domains as T_DnsDomain
+        var lData interface{}
+        lRecords, isListing := listingRecords(A24ApiResponseData, A24ApiClientArgs["domain"], a24api_filter_name, a24api_filter_type, a24api_filter_value)
+        if lDomains, isDomainList := A24ApiResponseData.(a24apiclient.T_DnsDomainList); isDomainList {
+            lDomainStructs := []a24apiclient.T_DnsDomain{}
+            for _, element := range lDomains {
+                if a24api_filter_name.MatchString(element) {
+                    lDomainStructs = append(lDomainStructs, a24apiclient.T_DnsDomain{ Domain: element })
+                }
+            }
+            lData = lDomainStructs
+        } else if isListing {
+            lData, err = a24apiclient.NewDnsRecordsTyped(lRecords)
+        } else {
+            lData = responseResult(A24ApiResponseData, A24ApiResponseAction, A24ApiResponseCode, lCodeText)
+        }
+        if err == nil {
+            if A24ApiClientArgs["format"] == "template" {
+                var lTemplate *template.Template
+                if lTemplate, err = template.New("output").Parse(A24ApiClientArgs["template"]); err == nil {
+                    err = lTemplate.Execute(os.Stdout, lData)
+                }
+            } else {
+                err = renderJsonPath(os.Stdout, A24ApiClientArgs["jsonpath"], lData)
+            }
+        }
+        if err != nil {
+            fmt.Println(err)
+            os.Exit(1)
+        }
+        os.Exit(resultExitCode(A24ApiResponseData, A24ApiClientArgs["partial"] == "true"))
     } else {
-
         switch A24ApiClientArgs["service"] {
             case "dns":
                 if (A24ApiResponseCode != 200) && (A24ApiResponseCode != 204) {
-                    fmt.Printf("%d %s\n", A24ApiResponseCode, A24ApiClient.GetCodeText(A24ApiResponseCode, A24ApiClientArgs["service"], A24ApiClientArgs["function"]))
+                    fmt.Printf("%d %s\n", A24ApiResponseCode, lCodeText)
                     os.Exit(2)
                 }
-                switch A24ApiClient.Config["function"] {
-                    case "list":
+                switch A24ApiClientArgs["function"] {
+                    case "list", "records":
                         // expected structure [ "domainA", "domainB" ]
-                        if len(A24ApiClientFuncArgs) == 0 {
-                            var structured_data []string
-                            json.Unmarshal([]byte(A24ApiResponseBody), &structured_data)
+                        if len(A24ApiClientFuncArgs) == 0 && A24ApiClientArgs["domains"] == "" {
+                            structured_data, _ := A24ApiResponseData.(a24apiclient.T_DnsDomainList)
                             w := new(tabwriter.Writer)
                             w.Init(os.Stdout, 0, 8, 1, ' ', 0)
                             for _, element := range structured_data {
-                                fmt.Fprintf(w, "%s\n", element)
+                                if a24api_filter_name.MatchString(element) {
+                                    fmt.Fprintf(w, "%s\n", element)
+                                }
                             }
                             w.Flush()
                         } else {
                             // expected structure [ { "variableA": "value", "variableB": "value" }, { "variableA": "value", "variableB": "value" } ]
-                            var structured_data []map[string]interface{}
-                            json.Unmarshal([]byte(a24api_response_body), &structured_data)
-                            //fmt.Printf("%v\n", structured_data)
                             w := new(tabwriter.Writer)
                             w.Init(os.Stdout, 0, 8, 1, ' ', 0)
-                            for _, element := range structured_data {
-                                if a24api_filter_type.MatchString(element["type"].(string)) {
-                                    switch element["type"].(string) {
-                                        case "A", "AAAA":
-                                            if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["ip"].(string)) {
-                                                fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%s\n", A24ApiClient.Config["domain"], element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["ip"].(string))
-                                            }
-                                        case "CNAME":
-                                            if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["alias"].(string)) {
-                                                fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%s\n", A24ApiClient.Config["domain"], element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["alias"].(string))
-                                            }
-                                        case "TXT":
-                                            if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["text"].(string)) {
-                                                fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t\"%s\"\n", A24ApiClient.Config["domain"], element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["text"].(string))
-                                            }
-                                        case "NS":
-                                            if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["nameServer"].(string)) {
-                                                fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%s\n", A24ApiClient.Config["domain"], element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["nameServer"].(string))
-                                            }
-                                        case "SSHFP":
-                                            if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["text"].(string)) {
-                                                fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%g\t%g\t%s\n", A24ApiClient.Config["domain"], element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["algorithm"].(float64), element["fingerprintType"].(float64), element["text"].(string))
-                                            }
-                                        case "SRV":
-                                            if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["target"].(string)) {
-                                                fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%g\t%g\t%g\t%s\n", A24ApiClient.Config["domain"], element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["priority"].(float64), element["weight"].(float64), element["port"].(float64), element["target"].(string))
-                                            }
-                                        case "TLSA":
-                                            if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["hash"].(string)) {
-                                                fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%g\t%g\t%g\t%s\n", A24ApiClient.Config["domain"], element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["certificateUsage"].(float64), element["selector"].(float64), element["matchingType"].(float64), element["hash"].(string))
-                                            }
-                                        case "CAA":
-                                            if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["caaValue"].(string)) {
-                                                fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%g\t%s\t%s\n", A24ApiClient.Config["domain"], element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["flags"].(float64), element["tag"].(string), element["caaValue"].(string))
-                                            }
-                                        case "MX":
-                                            if a24api_filter_name.MatchString(element["name"].(string)) && a24api_filter_value.MatchString(element["mailserver"].(string)) {
-                                                fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%g\t%s\n", A24ApiClient.Config["domain"], element["hashId"].(string), element["type"].(string), element["name"].(string), element["ttl"].(float64), element["priority"].(float64), element["mailserver"].(string))
-                                            }
+                            switch structured_data := A24ApiResponseData.(type) {
+                                case a24apiclient.T_DnsRecordList:
+                                    printRecords(w, A24ApiClientArgs["domain"], structured_data, a24api_filter_name, a24api_filter_type, a24api_filter_value)
+                                case []a24apiclient.T_DnsDomainRecords:
+                                    for _, lDomainRecords := range structured_data {
+                                        if lDomainRecords.Err != nil {
+                                            fmt.Fprintf(os.Stderr, "%s: %s\n", lDomainRecords.Domain, lDomainRecords.Err)
+                                            continue
+                                        }
+                                        printRecords(w, lDomainRecords.Domain, lDomainRecords.Records, a24api_filter_name, a24api_filter_type, a24api_filter_value)
                                     }
-                                }
                             }
                             w.Flush()
                         }
                     case "create", "update", "delete":
-                        fmt.Printf("%d %s\n", a24api_response.StatusCode, getCodeText(a24api_response.StatusCode, A24ApiClient.Config["service"], A24ApiClient.Config["function"]))
+                        fmt.Printf("%d %s\n", A24ApiResponseCode, lCodeText)
+                        os.Exit(0)
+                    case "upsert":
+                        fmt.Printf("%s %d %s\n", A24ApiResponseAction, A24ApiResponseCode, lCodeText)
                         os.Exit(0)
+                    case "batch":
+                        lResults, _ := A24ApiResponseData.([]a24apiclient.T_DnsBatchResult)
+                        w := new(tabwriter.Writer)
+                        w.Init(os.Stdout, 0, 8, 1, ' ', 0)
+                        for _, element := range lResults {
+                            lOperation := element.Operation
+                            if element.Action != "" {
+                                lOperation = lOperation + ":" + element.Action
+                            }
+                            fmt.Fprintf(w, "%d\t%s\t%s\t%d %s\t%s\t%s\t%s\t%s\t%s\t%s\n", element.Line, lOperation, element.Status, element.Code, element.CodeText, element.Record["Domain"], element.Record["HashId"], element.Record["Type"], element.Record["Name"], a24apiclient.DnsRecordValue(element.Record), element.Error)
+                        }
+                        w.Flush()
+                        os.Exit(batchExitCode(lResults))
+                    case "diff":
+                        lEntries, _ := A24ApiResponseData.([]a24apiclient.T_DnsDiffEntry)
+                        if A24ApiClientArgs["format"] == "table" {
+                            printDiffTable(lEntries)
+                        } else {
+                            printDiffUnified(lEntries, A24ApiClientArgs["diff-a"], A24ApiClientArgs["diff-b"])
+                        }
+                        if len(lEntries) > 0 {
+                            os.Exit(3)
+                        }
+                    case "snapshot":
+                        lPaths, _ := A24ApiResponseData.([]string)
+                        for _, element := range lPaths {
+                            fmt.Println(element)
+                        }
+                        if A24ApiClientArgs["partial"] == "true" {
+                            os.Exit(2)
+                        }
+                    case "rrset", "search", "replace", "revert", "restore", "copy", "preset":
+                        w := new(tabwriter.Writer)
+                        w.Init(os.Stdout, 0, 8, 1, ' ', 0)
+                        switch structured_data := A24ApiResponseData.(type) {
+                            case []map[string]string:
+                                printRecordMaps(w, structured_data)
+                            case []a24apiclient.T_DnsPreset:
+                                printDnsPresets(w, structured_data)
+                            case []a24apiclient.T_DnsChange:
+                                printChanges(w, A24ApiClient, "dns", structured_data)
+                        }
+                        w.Flush()
+                }
+            case "spf":
+                switch structured_data := A24ApiResponseData.(type) {
+                    case *a24apiclient.T_SpfRecord:
+                        printSpf(structured_data)
+                    case *a24apiclient.T_SpfLint:
+                        printSpfLint(structured_data)
+                        if structured_data.Errors() > 0 {
+                            os.Exit(2)
+                        }
+                    case []a24apiclient.T_DnsChange:
+                        printChanges(os.Stdout, A24ApiClient, A24ApiClientArgs["service"], structured_data)
+                }
+            case "dkim", "mailauth", "tlsa", "sshfp", "caa", "srv":
+                switch structured_data := A24ApiResponseData.(type) {
+                    case []a24apiclient.T_DkimSelector:
+                        printDkimSelectors(structured_data)
+                    case *a24apiclient.T_MailAuth:
+                        printMailAuth(structured_data)
+                        if structured_data.Errors() > 0 {
+                            os.Exit(2)
+                        }
+                    case []a24apiclient.T_SrvService:
+                        printSrvServices(structured_data)
+                    case []a24apiclient.T_SrvEntry:
+                        printSrvEntries(structured_data)
+                    case *a24apiclient.T_CaaPolicy:
+                        printCaaPolicy(structured_data)
+                        if structured_data.Errors() > 0 {
+                            os.Exit(2)
+                        }
+                    case []a24apiclient.T_TlsaCheck:
+                        printTlsaChecks(structured_data)
+                        if a24apiclient.TlsaMatches(structured_data) == 0 {
+                            os.Exit(2)
+                        }
+                    case []a24apiclient.T_DnsChange:
+                        printChanges(os.Stdout, A24ApiClient, A24ApiClientArgs["service"], structured_data)
                 }
         }
     }
@@ -0,0 +1,446 @@
+package main
+
+import (
+    "bytes"
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "a24api/lib"
+)
+
+// ================================================================================================================================================================
+// RECORD COLUMNS
+// ================================================================================================================================================================
+
+// record types in column order of mixed listings
+var C_Output_RecordTypes = []string { "A", "AAAA", "CNAME", "TXT", "NS", "SSHFP", "SRV", "TLSA", "CAA", "MX" }
+
+var C_Output_YamlPlainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+type t_outputColumn struct {
+    Header          string
+    Key             string
+    Numeric         bool
+    Detail          bool          // value field of record type, table shows value column instead unless selected
+    TableOnly       bool          // joined record value, shown only by table
+}
+
+// t_outputOptions holds output settings of yaml, csv, tsv and table formats
+type t_outputOptions struct {
+    Header          bool
+    Columns         []string      // selected columns of table by header (case-insensitive), empty for default
+    SortBy          string        // sort rows of table by column
+    Width           int           // table width limit, 0 is unlimited
+    Colour          bool
+}
+
+// recordColumns returns columns of records: domain, hashId, type, name, ttl and value fields of their type when
+// all records share one type, union of value fields of all types otherwise, so column set is stable per type
+func recordColumns(records []map[string]string) []t_outputColumn {
+    lColumns := []t_outputColumn {
+        { Header: "domain", Key: "Domain" },
+        { Header: "hashId", Key: "HashId" },
+        { Header: "type", Key: "Type" },
+        { Header: "name", Key: "Name" },
+        { Header: "ttl", Key: "Ttl", Numeric: true },
+    }
+    lTypes := C_Output_RecordTypes
+    if len(records) > 0 {
+        lSingle := true
+        for _, element := range records {
+            if element["Type"] != records[0]["Type"] {
+                lSingle = false
+                break
+            }
+        }
+        if lSingle {
+            lTypes = []string{ records[0]["Type"] }
+        }
+    }
+    lSeen := make(map[string]bool)
+    for _, lType := range lTypes {
+        for _, lField := range a24apiclient.C_A24ApiClient_DnsRecordFields[lType] {
+            if lSeen[lField.ApiKey] {
+                continue
+            }
+            lSeen[lField.ApiKey] = true
+            lColumns = append(lColumns, t_outputColumn{ Header: lField.ApiKey, Key: lField.Key, Numeric: lField.Numeric, Detail: true })
+        }
+    }
+    return append(lColumns, t_outputColumn{ Header: "value", TableOnly: true })
+}
+
+// filterRecords returns records matching type, name and value (a24apiclient.DnsRecordValue) filters
+func filterRecords(records []map[string]string, a24api_filter_name, a24api_filter_type, a24api_filter_value *regexp.Regexp) []map[string]string {
+    var lRecords []map[string]string
+    for _, element := range records {
+        if a24api_filter_type.MatchString(element["Type"]) && a24api_filter_name.MatchString(element["Name"]) && a24api_filter_value.MatchString(a24apiclient.DnsRecordValue(element)) {
+            lRecords = append(lRecords, element)
+        }
+    }
+    return lRecords
+}
+
+// listingRecords returns records of record listing data (single domain, multiple domains, rrset or search) matching
+// filters, second value is false for other data; failed domains are reported to stderr
+func listingRecords(data interface{}, domain string, a24api_filter_name, a24api_filter_type, a24api_filter_value *regexp.Regexp) ([]map[string]string, bool) {
+    switch t := data.(type) {
+        case a24apiclient.T_DnsRecordList:
+            return filterRecords(recordMaps(domain, t), a24api_filter_name, a24api_filter_type, a24api_filter_value), true
+        case []a24apiclient.T_DnsDomainRecords:
+            var lRecords []map[string]string
+            for _, lDomainRecords := range t {
+                if lDomainRecords.Err != nil {
+                    fmt.Fprintf(os.Stderr, "%s: %s\n", lDomainRecords.Domain, lDomainRecords.Err)
+                    continue
+                }
+                lRecords = append(lRecords, recordMaps(lDomainRecords.Domain, lDomainRecords.Records)...)
+            }
+            return filterRecords(lRecords, a24api_filter_name, a24api_filter_type, a24api_filter_value), true
+        case []map[string]string:
+            return t, true
+    }
+    return nil, false
+}
+
+// recordMaps converts api records of domain into record maps
+func recordMaps(domain string, records a24apiclient.T_DnsRecordList) []map[string]string {
+    var lRecords []map[string]string
+    for _, element := range records {
+        lRecords = append(lRecords, a24apiclient.NewDnsRecordFromList(domain, element))
+    }
+    return lRecords
+}
+
+// ================================================================================================================================================================
+// RENDERERS
+// ================================================================================================================================================================
+
+// renderRecords prints records in yaml, csv or tsv format
+func renderRecords(w io.Writer, format string, records []map[string]string, options t_outputOptions) error {
+    return renderRecordRows(w, format, nil, nil, records, options)
+}
+
+// renderRecordRows prints records preceded by prefix columns (e.g. action of change), prefix holds one row
+// of values per record
+func renderRecordRows(w io.Writer, format string, columns []t_outputColumn, prefix [][]interface{}, records []map[string]string, options t_outputOptions) error {
+    lColumns := append(append([]t_outputColumn{}, columns...), recordColumns(records)...)
+    var lRows [][]interface{}
+    for index, element := range records {
+        var lRow []interface{}
+        if prefix != nil {
+            lRow = append(lRow, prefix[index]...)
+        }
+        for _, lColumn := range lColumns[len(columns):] {
+            if lColumn.TableOnly {
+                lRow = append(lRow, a24apiclient.DnsRecordValue(element))
+                continue
+            }
+            lRow = append(lRow, outputValue(element[lColumn.Key], lColumn.Numeric))
+        }
+        lRows = append(lRows, lRow)
+    }
+    return renderRows(w, format, lColumns, lRows, options)
+}
+
+// renderData prints response data of functions other than record listings in yaml, csv or tsv format
+func renderData(w io.Writer, format string, data interface{}, options t_outputOptions) error {
+    if format == "yaml" {
+        return renderYaml(w, data)
+    }
+    switch t := data.(type) {
+        case []a24apiclient.T_DnsChange:
+            lColumns := []t_outputColumn{ { Header: "action" }, { Header: "status" }, { Header: "code" } }
+            var lPrefix [][]interface{}
+            var lRecords []map[string]string
+            for _, element := range t {
+                lPrefix = append(lPrefix, []interface{}{ element.Action, element.Status(), element.Code })
+                lRecords = append(lRecords, element.Record)
+            }
+            return renderRecordRows(w, format, lColumns, lPrefix, lRecords, options)
+        case []a24apiclient.T_DnsBatchResult:
+            lColumns := []t_outputColumn{ { Header: "line" }, { Header: "operation" }, { Header: "action" }, { Header: "status" }, { Header: "code" }, { Header: "codeText" }, { Header: "error" } }
+            var lPrefix [][]interface{}
+            var lRecords []map[string]string
+            for _, element := range t {
+                lPrefix = append(lPrefix, []interface{}{ element.Line, element.Operation, element.Action, element.Status, element.Code, element.CodeText, element.Error })
+                lRecords = append(lRecords, element.Record)
+            }
+            return renderRecordRows(w, format, lColumns, lPrefix, lRecords, options)
+        case []a24apiclient.T_DnsDiffEntry:
+            // changed entry is printed as two rows, side a and side b
+            lColumns := []t_outputColumn{ { Header: "status" }, { Header: "side" } }
+            var lPrefix [][]interface{}
+            var lRecords []map[string]string
+            for _, element := range t {
+                if element.A != nil {
+                    lPrefix = append(lPrefix, []interface{}{ element.Status, "a" })
+                    lRecords = append(lRecords, element.A)
+                }
+                if element.B != nil {
+                    lPrefix = append(lPrefix, []interface{}{ element.Status, "b" })
+                    lRecords = append(lRecords, element.B)
+                }
+            }
+            return renderRecordRows(w, format, lColumns, lPrefix, lRecords, options)
+        case []string:
+            var lRows [][]interface{}
+            for _, element := range t {
+                lRows = append(lRows, []interface{}{ element })
+            }
+            return renderRows(w, format, []t_outputColumn{ { Header: "path" } }, lRows, options)
+        case map[string]interface{}:
+            var lKeys []string
+            for key := range t {
+                lKeys = append(lKeys, key)
+            }
+            sort.Strings(lKeys)
+            var lColumns []t_outputColumn
+            var lRow []interface{}
+            for _, key := range lKeys {
+                lColumns = append(lColumns, t_outputColumn{ Header: key })
+                lRow = append(lRow, t[key])
+            }
+            return renderRows(w, format, lColumns, [][]interface{}{ lRow }, options)
+    }
+    if lColumns, lRows, isService := serviceRows(data); isService {
+        return renderRows(w, format, lColumns, lRows, options)
+    }
+    return fmt.Errorf("Output format %s is not supported by this function.", format)
+}
+
+// renderRows prints rows in yaml (list of mappings, empty values omitted), csv, tsv or table format
+func renderRows(w io.Writer, format string, columns []t_outputColumn, rows [][]interface{}, options t_outputOptions) error {
+    if format == "table" {
+        return renderTable(w, columns, rows, options)
+    }
+    // joined record value is table only
+    var lColumns []t_outputColumn
+    lRows := make([][]interface{}, len(rows))
+    for index, lColumn := range columns {
+        if lColumn.TableOnly {
+            continue
+        }
+        lColumns = append(lColumns, lColumn)
+        for indexRow, lRow := range rows {
+            lRows[indexRow] = append(lRows[indexRow], lRow[index])
+        }
+    }
+    columns, rows = lColumns, lRows
+
+    switch format {
+        case "yaml":
+            if len(rows) == 0 {
+                fmt.Fprintln(w, "[]")
+                return nil
+            }
+            for _, lRow := range rows {
+                lFirst := true
+                for index, lColumn := range columns {
+                    if lRow[index] == nil || lRow[index] == "" {
+                        continue
+                    }
+                    lPrefix := "  "
+                    if lFirst {
+                        lPrefix = "- "
+                        lFirst = false
+                    }
+                    fmt.Fprintf(w, "%s%s: %s\n", lPrefix, lColumn.Header, yamlScalar(lRow[index]))
+                }
+                if lFirst {
+                    fmt.Fprintln(w, "- {}")
+                }
+            }
+            return nil
+        case "csv", "tsv":
+            lRecords := [][]string{}
+            if options.Header {
+                var lHeader []string
+                for _, lColumn := range columns {
+                    lHeader = append(lHeader, lColumn.Header)
+                }
+                lRecords = append(lRecords, lHeader)
+            }
+            for _, lRow := range rows {
+                var lRecord []string
+                for _, lValue := range lRow {
+                    switch t := lValue.(type) {
+                        case nil:
+                            lRecord = append(lRecord, "")
+                        case float64:
+                            // ttl 1000000 stays 1000000, not 1e+06
+                            lRecord = append(lRecord, strconv.FormatFloat(t, 'f', -1, 64))
+                        default:
+                            lRecord = append(lRecord, fmt.Sprintf("%v", t))
+                    }
+                }
+                lRecords = append(lRecords, lRecord)
+            }
+            if format == "csv" {
+                lWriter := csv.NewWriter(w)
+                lWriter.WriteAll(lRecords)
+                return lWriter.Error()
+            }
+            for _, lRecord := range lRecords {
+                for index := range lRecord {
+                    lRecord[index] = tsvEscape(lRecord[index])
+                }
+                fmt.Fprintln(w, strings.Join(lRecord, "\t"))
+            }
+            return nil
+    }
+    return fmt.Errorf("Unsupported output format: %s.", format)
+}
+
+// renderYaml prints any json serializable data as yaml
+func renderYaml(w io.Writer, data interface{}) error {
+    lJson, err := json.Marshal(data)
+    if err != nil {
+        return err
+    }
+    return renderYamlJson(w, lJson)
+}
+
+// renderYamlJson prints json document as yaml
+func renderYamlJson(w io.Writer, data []byte) error {
+    var lData interface{}
+    lDecoder := json.NewDecoder(bytes.NewReader(data))
+    lDecoder.UseNumber()
+    if err := lDecoder.Decode(&lData); err != nil {
+        return err
+    }
+    writeYaml(w, lData, 0, false)
+    return nil
+}
+
+func writeYaml(w io.Writer, data interface{}, indent int, inList bool) {
+    lPad := strings.Repeat("  ", indent)
+    switch t := data.(type) {
+        case map[string]interface{}:
+            if len(t) == 0 {
+                fmt.Fprintf(w, "%s{}\n", yamlListPad(lPad, inList))
+                return
+            }
+            var lKeys []string
+            for key := range t {
+                lKeys = append(lKeys, key)
+            }
+            sort.Strings(lKeys)
+            for index, key := range lKeys {
+                lLinePad := lPad
+                if index == 0 && inList {
+                    lLinePad = yamlListPad(lPad, true)
+                }
+                switch lValue := t[key].(type) {
+                    case map[string]interface{}, []interface{}:
+                        if yamlEmpty(lValue) {
+                            fmt.Fprintf(w, "%s%s: %s\n", lLinePad, yamlKey(key), yamlEmptyValue(lValue))
+                        } else {
+                            fmt.Fprintf(w, "%s%s:\n", lLinePad, yamlKey(key))
+                            writeYaml(w, lValue, indent + 1, false)
+                        }
+                    default:
+                        fmt.Fprintf(w, "%s%s: %s\n", lLinePad, yamlKey(key), yamlScalar(lValue))
+                }
+            }
+        case []interface{}:
+            if len(t) == 0 {
+                fmt.Fprintf(w, "%s[]\n", yamlListPad(lPad, inList))
+                return
+            }
+            for _, lValue := range t {
+                switch lValue.(type) {
+                    case map[string]interface{}, []interface{}:
+                        if yamlEmpty(lValue) {
+                            fmt.Fprintf(w, "%s- %s\n", lPad, yamlEmptyValue(lValue))
+                        } else {
+                            writeYaml(w, lValue, indent + 1, true)
+                        }
+                    default:
+                        fmt.Fprintf(w, "%s- %s\n", lPad, yamlScalar(lValue))
+                }
+            }
+        default:
+            fmt.Fprintf(w, "%s%s\n", yamlListPad(lPad, inList), yamlScalar(t))
+    }
+}
+
+// yamlListPad replaces last indentation level by list item marker
+func yamlListPad(pad string, inList bool) string {
+    if !inList {
+        return pad
+    }
+    if len(pad) >= 2 {
+        return pad[:len(pad) - 2] + "- "
+    }
+    return "- "
+}
+
+func yamlEmpty(value interface{}) bool {
+    switch t := value.(type) {
+        case map[string]interface{}:
+            return len(t) == 0
+        case []interface{}:
+            return len(t) == 0
+    }
+    return false
+}
+
+func yamlEmptyValue(value interface{}) string {
+    if _, isMap := value.(map[string]interface{}); isMap {
+        return "{}"
+    }
+    return "[]"
+}
+
+// yamlScalar formats scalar, strings are always double quoted (json string syntax is valid yaml)
+func yamlScalar(value interface{}) string {
+    switch t := value.(type) {
+        case nil:
+            return "null"
+        case bool:
+            return strconv.FormatBool(t)
+        case json.Number:
+            return t.String()
+        case float64:
+            return strconv.FormatFloat(t, 'g', -1, 64)
+        case int:
+            return strconv.Itoa(t)
+        case string:
+            lQuoted, _ := json.Marshal(t)
+            return string(lQuoted)
+        default:
+            return fmt.Sprintf("%v", t)
+    }
+}
+
+// yamlKey leaves identifier-like keys unquoted
+func yamlKey(key string) string {
+    if C_Output_YamlPlainKey.MatchString(key) {
+        return key
+    }
+    return yamlScalar(key)
+}
+
+// outputValue returns numeric fields as numbers, other fields as strings
+func outputValue(value string, numeric bool) interface{} {
+    if value == "" {
+        return nil
+    }
+    if numeric {
+        if lNumber, err := strconv.ParseFloat(value, 64); err == nil {
+            return lNumber
+        }
+    }
+    return value
+}
+
+func tsvEscape(value string) string {
+    return strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r").Replace(value)
+}
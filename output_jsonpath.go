@@ -0,0 +1,585 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// ================================================================================================================================================================
+// JSONPATH
+// ================================================================================================================================================================
+//
+// Subset of kubectl jsonpath: template text with {expressions}, expression is a path, "quoted text" (\t, \n, \" escapes),
+// range <path> ... end. Path starts with $ (root) or @ (current element, default) followed by .name, ['name'], ..name
+// (recursive), .* or [*], [n] (negative counts from end), [start:end:step] (positive step) and filters [?(@.path)]
+// and [?(@.path <op> value)] with op ==, !=, <, <=, >, >= and value string, number, true, false or null.
+// Multiple results of one path are separated by space, objects and arrays are printed as json. Missing key is an
+// error as in kubectl without allowMissingKeys, except in filters where it only fails the condition.
+
+type t_jsonPathNode struct {
+    text            string
+    literal         bool
+    path            []t_jsonPathStep
+    rangeNodes      []t_jsonPathNode
+    isRange         bool
+}
+
+type t_jsonPathStep struct {
+    kind            string        // root, key, recursive, wildcard, index, slice, filter
+    key             string
+    index           int
+    end             int
+    hasStart        bool
+    hasEnd          bool
+    stride          int           // step of slice
+    filterPath      []t_jsonPathStep
+    filterOp        string
+    filterValue     interface{}
+}
+
+// renderJsonPath evaluates jsonpath template against json serializable data
+func renderJsonPath(w io.Writer, template string, data interface{}) error {
+    lNodes, err := parseJsonPathTemplate(template)
+    if err != nil {
+        return err
+    }
+    lJson, err := json.Marshal(data)
+    if err != nil {
+        return err
+    }
+    var lData interface{}
+    lDecoder := json.NewDecoder(bytes.NewReader(lJson))
+    lDecoder.UseNumber()
+    if err := lDecoder.Decode(&lData); err != nil {
+        return err
+    }
+    var lOut strings.Builder
+    if err := evalJsonPathNodes(&lOut, lNodes, lData, lData); err != nil {
+        return err
+    }
+    _, err = io.WriteString(w, lOut.String())
+    return err
+}
+
+// ----------------------------------------------------------------------------------------------------------------------------------------------------------------
+// Parse
+// ----------------------------------------------------------------------------------------------------------------------------------------------------------------
+
+func parseJsonPathTemplate(template string) ([]t_jsonPathNode, error) {
+    var lStack [][]t_jsonPathNode
+    var lRanges []t_jsonPathNode
+    var lNodes []t_jsonPathNode
+    index := 0
+    for index < len(template) {
+        lOpen := strings.IndexByte(template[index:], '{')
+        if lOpen < 0 {
+            lNodes = append(lNodes, t_jsonPathNode{ text: template[index:], literal: true })
+            break
+        }
+        if lOpen > 0 {
+            lNodes = append(lNodes, t_jsonPathNode{ text: template[index:index + lOpen], literal: true })
+        }
+        index += lOpen + 1
+        lClose := jsonPathExpressionEnd(template, index)
+        if lClose < 0 {
+            return nil, fmt.Errorf("Jsonpath: unclosed { at %d.", index - 1)
+        }
+        lExpr := strings.TrimSpace(template[index:lClose])
+        index = lClose + 1
+
+        switch {
+            case lExpr == "end":
+                if len(lStack) == 0 {
+                    return nil, fmt.Errorf("Jsonpath: end without range.")
+                }
+                lRange := lRanges[len(lRanges) - 1]
+                lRange.rangeNodes = lNodes
+                lRanges = lRanges[:len(lRanges) - 1]
+                lNodes = append(lStack[len(lStack) - 1], lRange)
+                lStack = lStack[:len(lStack) - 1]
+            case strings.HasPrefix(lExpr, "range ") || lExpr == "range":
+                lPath, err := parseJsonPath(strings.TrimSpace(strings.TrimPrefix(lExpr, "range")))
+                if err != nil {
+                    return nil, err
+                }
+                lStack = append(lStack, lNodes)
+                lRanges = append(lRanges, t_jsonPathNode{ isRange: true, path: lPath })
+                lNodes = nil
+            case strings.HasPrefix(lExpr, "\""):
+                lText, err := strconv.Unquote(lExpr)
+                if err != nil {
+                    return nil, fmt.Errorf("Jsonpath: invalid string %s.", lExpr)
+                }
+                lNodes = append(lNodes, t_jsonPathNode{ text: lText, literal: true })
+            default:
+                lPath, err := parseJsonPath(lExpr)
+                if err != nil {
+                    return nil, err
+                }
+                lNodes = append(lNodes, t_jsonPathNode{ path: lPath })
+        }
+    }
+    if len(lStack) > 0 {
+        return nil, fmt.Errorf("Jsonpath: range without end.")
+    }
+    return lNodes, nil
+}
+
+// jsonPathExpressionEnd returns index of } closing expression starting at start, braces in quotes are skipped
+func jsonPathExpressionEnd(template string, start int) int {
+    lQuote := byte(0)
+    for index := start; index < len(template); index++ {
+        ch := template[index]
+        switch {
+            case lQuote != 0 && ch == '\\':
+                index++
+            case lQuote != 0 && ch == lQuote:
+                lQuote = 0
+            case lQuote == 0 && (ch == '"' || ch == '\''):
+                lQuote = ch
+            case lQuote == 0 && ch == '}':
+                return index
+        }
+    }
+    return -1
+}
+
+func parseJsonPath(path string) ([]t_jsonPathStep, error) {
+    var lSteps []t_jsonPathStep
+    index := 0
+    if strings.HasPrefix(path, "$") {
+        lSteps = append(lSteps, t_jsonPathStep{ kind: "root" })
+        index++
+    } else if strings.HasPrefix(path, "@") {
+        index++
+    } else if path != "" && path[0] != '.' && path[0] != '[' {
+        // bare name, e.g. {items[0]}
+        path = "." + path
+    }
+    for index < len(path) {
+        switch {
+            case strings.HasPrefix(path[index:], ".."):
+                lName, lNext := jsonPathName(path, index + 2)
+                if lName == "" {
+                    return nil, fmt.Errorf("Jsonpath: name expected after .. in %s.", path)
+                }
+                lSteps = append(lSteps, t_jsonPathStep{ kind: "recursive", key: lName })
+                index = lNext
+            case path[index] == '.':
+                if index + 1 < len(path) && path[index + 1] == '*' {
+                    lSteps = append(lSteps, t_jsonPathStep{ kind: "wildcard" })
+                    index += 2
+                    continue
+                }
+                lName, lNext := jsonPathName(path, index + 1)
+                if lName == "" {
+                    // trailing or repeated dot selects current element
+                    index++
+                    continue
+                }
+                lSteps = append(lSteps, t_jsonPathStep{ kind: "key", key: lName })
+                index = lNext
+            case path[index] == '[':
+                lClose := jsonPathBracketEnd(path, index)
+                if lClose < 0 {
+                    return nil, fmt.Errorf("Jsonpath: unclosed [ in %s.", path)
+                }
+                lStep, err := parseJsonPathBracket(strings.TrimSpace(path[index + 1:lClose]))
+                if err != nil {
+                    return nil, err
+                }
+                lSteps = append(lSteps, lStep)
+                index = lClose + 1
+            default:
+                return nil, fmt.Errorf("Jsonpath: unexpected %q in %s.", path[index], path)
+        }
+    }
+    return lSteps, nil
+}
+
+func jsonPathName(path string, start int) (string, int) {
+    index := start
+    for index < len(path) && path[index] != '.' && path[index] != '[' && path[index] != ' ' {
+        index++
+    }
+    return path[start:index], index
+}
+
+func jsonPathBracketEnd(path string, start int) int {
+    lDepth := 0
+    lQuote := byte(0)
+    for index := start; index < len(path); index++ {
+        ch := path[index]
+        switch {
+            case lQuote != 0 && ch == '\\':
+                index++
+            case lQuote != 0 && ch == lQuote:
+                lQuote = 0
+            case lQuote == 0 && (ch == '"' || ch == '\''):
+                lQuote = ch
+            case lQuote == 0 && ch == '[':
+                lDepth++
+            case lQuote == 0 && ch == ']':
+                lDepth--
+                if lDepth == 0 {
+                    return index
+                }
+        }
+    }
+    return -1
+}
+
+func parseJsonPathBracket(expr string) (t_jsonPathStep, error) {
+    switch {
+        case expr == "*":
+            return t_jsonPathStep{ kind: "wildcard" }, nil
+        case strings.HasPrefix(expr, "?(") && strings.HasSuffix(expr, ")"):
+            return parseJsonPathFilter(strings.TrimSpace(expr[2:len(expr) - 1]))
+        case strings.HasPrefix(expr, "'") || strings.HasPrefix(expr, "\""):
+            lKey, err := jsonPathUnquote(expr)
+            if err != nil {
+                return t_jsonPathStep{}, err
+            }
+            return t_jsonPathStep{ kind: "key", key: lKey }, nil
+        case strings.Contains(expr, ":"):
+            lParts := strings.SplitN(expr, ":", 3)
+            lStep := t_jsonPathStep{ kind: "slice", stride: 1 }
+            var err error
+            if s := strings.TrimSpace(lParts[0]); s != "" {
+                if lStep.index, err = strconv.Atoi(s); err != nil {
+                    return lStep, fmt.Errorf("Jsonpath: invalid slice [%s].", expr)
+                }
+                lStep.hasStart = true
+            }
+            if s := strings.TrimSpace(lParts[1]); s != "" {
+                if lStep.end, err = strconv.Atoi(s); err != nil {
+                    return lStep, fmt.Errorf("Jsonpath: invalid slice [%s].", expr)
+                }
+                lStep.hasEnd = true
+            }
+            if len(lParts) == 3 {
+                if s := strings.TrimSpace(lParts[2]); s != "" {
+                    if lStep.stride, err = strconv.Atoi(s); err != nil || lStep.stride < 1 {
+                        return lStep, fmt.Errorf("Jsonpath: invalid slice step [%s].", expr)
+                    }
+                }
+            }
+            return lStep, nil
+    }
+    lIndex, err := strconv.Atoi(expr)
+    if err != nil {
+        return t_jsonPathStep{}, fmt.Errorf("Jsonpath: invalid subscript [%s].", expr)
+    }
+    return t_jsonPathStep{ kind: "index", index: lIndex }, nil
+}
+
+func parseJsonPathFilter(expr string) (t_jsonPathStep, error) {
+    lStep := t_jsonPathStep{ kind: "filter" }
+    lPathExpr := expr
+    for _, lOp := range []string{ "==", "!=", "<=", ">=", "<", ">" } {
+        lPos := jsonPathOperator(expr, lOp)
+        if lPos < 0 {
+            continue
+        }
+        lPathExpr = strings.TrimSpace(expr[:lPos])
+        lStep.filterOp = lOp
+        lValue := strings.TrimSpace(expr[lPos + len(lOp):])
+        switch {
+            case strings.HasPrefix(lValue, "'") || strings.HasPrefix(lValue, "\""):
+                lText, err := jsonPathUnquote(lValue)
+                if err != nil {
+                    return lStep, err
+                }
+                lStep.filterValue = lText
+            case lValue == "true" || lValue == "false":
+                lStep.filterValue = lValue == "true"
+            case lValue == "null":
+                lStep.filterValue = nil
+            default:
+                lNumber, err := strconv.ParseFloat(lValue, 64)
+                if err != nil {
+                    return lStep, fmt.Errorf("Jsonpath: invalid filter value %s.", lValue)
+                }
+                lStep.filterValue = lNumber
+        }
+        break
+    }
+    if !strings.HasPrefix(lPathExpr, "@") {
+        return lStep, fmt.Errorf("Jsonpath: filter must start with @: %s.", expr)
+    }
+    lPath, err := parseJsonPath(lPathExpr)
+    if err != nil {
+        return lStep, err
+    }
+    lStep.filterPath = lPath
+    return lStep, nil
+}
+
+// jsonPathOperator returns position of operator outside of quotes
+func jsonPathOperator(expr, op string) int {
+    lQuote := byte(0)
+    for index := 0; index < len(expr); index++ {
+        ch := expr[index]
+        switch {
+            case lQuote != 0 && ch == '\\':
+                index++
+            case lQuote != 0 && ch == lQuote:
+                lQuote = 0
+            case lQuote == 0 && (ch == '"' || ch == '\''):
+                lQuote = ch
+            case lQuote == 0 && strings.HasPrefix(expr[index:], op):
+                // < and > must not be part of <= and >=
+                if len(op) == 1 && index + 1 < len(expr) && expr[index + 1] == '=' {
+                    continue
+                }
+                return index
+        }
+    }
+    return -1
+}
+
+func jsonPathUnquote(text string) (string, error) {
+    if len(text) < 2 || text[0] != text[len(text) - 1] {
+        return "", fmt.Errorf("Jsonpath: invalid string %s.", text)
+    }
+    if text[0] == '\'' {
+        text = "\"" + strings.Replace(strings.Replace(text[1:len(text) - 1], "\\'", "'", -1), "\"", "\\\"", -1) + "\""
+    }
+    lText, err := strconv.Unquote(text)
+    if err != nil {
+        return "", fmt.Errorf("Jsonpath: invalid string %s.", text)
+    }
+    return lText, nil
+}
+
+// ----------------------------------------------------------------------------------------------------------------------------------------------------------------
+// Evaluate
+// ----------------------------------------------------------------------------------------------------------------------------------------------------------------
+
+func evalJsonPathNodes(w *strings.Builder, nodes []t_jsonPathNode, root, current interface{}) error {
+    for _, lNode := range nodes {
+        switch {
+            case lNode.literal:
+                w.WriteString(lNode.text)
+            case lNode.isRange:
+                lValues, err := evalJsonPath(lNode.path, root, current, false)
+                if err != nil {
+                    return err
+                }
+                if len(lValues) == 1 {
+                    if lList, isList := lValues[0].([]interface{}); isList {
+                        lValues = lList
+                    }
+                }
+                for _, lValue := range lValues {
+                    if err := evalJsonPathNodes(w, lNode.rangeNodes, root, lValue); err != nil {
+                        return err
+                    }
+                }
+            default:
+                lValues, err := evalJsonPath(lNode.path, root, current, false)
+                if err != nil {
+                    return err
+                }
+                for index, lValue := range lValues {
+                    if index > 0 {
+                        w.WriteString(" ")
+                    }
+                    w.WriteString(jsonPathString(lValue))
+                }
+        }
+    }
+    return nil
+}
+
+// evalJsonPath returns values selected by path, key found in none of the values is an error unless allowMissing
+func evalJsonPath(steps []t_jsonPathStep, root, current interface{}, allowMissing bool) ([]interface{}, error) {
+    lValues := []interface{}{ current }
+    for _, lStep := range steps {
+        var lNext []interface{}
+        for _, lValue := range lValues {
+            lNext = append(lNext, evalJsonPathStep(lStep, root, lValue)...)
+        }
+        if lStep.kind == "key" && len(lValues) > 0 && len(lNext) == 0 && !allowMissing {
+            return nil, fmt.Errorf("Jsonpath: %s is not found.", lStep.key)
+        }
+        lValues = lNext
+    }
+    return lValues, nil
+}
+
+func evalJsonPathStep(step t_jsonPathStep, root, value interface{}) []interface{} {
+    switch step.kind {
+        case "root":
+            return []interface{}{ root }
+        case "key":
+            if lMap, isMap := value.(map[string]interface{}); isMap {
+                if lValue, isPresent := lMap[step.key]; isPresent {
+                    return []interface{}{ lValue }
+                }
+            }
+        case "wildcard":
+            return jsonPathChildren(value)
+        case "recursive":
+            var lValues []interface{}
+            if lMap, isMap := value.(map[string]interface{}); isMap {
+                if lValue, isPresent := lMap[step.key]; isPresent {
+                    lValues = append(lValues, lValue)
+                }
+            }
+            for _, lChild := range jsonPathChildren(value) {
+                lValues = append(lValues, evalJsonPathStep(step, root, lChild)...)
+            }
+            return lValues
+        case "index":
+            if lList, isList := value.([]interface{}); isList {
+                lIndex := step.index
+                if lIndex < 0 {
+                    lIndex += len(lList)
+                }
+                if lIndex >= 0 && lIndex < len(lList) {
+                    return []interface{}{ lList[lIndex] }
+                }
+            }
+        case "slice":
+            if lList, isList := value.([]interface{}); isList {
+                lStart, lEnd := 0, len(lList)
+                if step.hasStart {
+                    lStart = jsonPathBound(step.index, len(lList))
+                }
+                if step.hasEnd {
+                    lEnd = jsonPathBound(step.end, len(lList))
+                }
+                var lValues []interface{}
+                for index := lStart; index < lEnd; index += step.stride {
+                    lValues = append(lValues, lList[index])
+                }
+                return lValues
+            }
+        case "filter":
+            var lValues []interface{}
+            for _, lChild := range jsonPathChildren(value) {
+                lResults, _ := evalJsonPath(step.filterPath, root, lChild, true)
+                if step.filterOp == "" {
+                    if len(lResults) > 0 {
+                        lValues = append(lValues, lChild)
+                    }
+                    continue
+                }
+                for _, lResult := range lResults {
+                    if jsonPathCompare(lResult, step.filterOp, step.filterValue) {
+                        lValues = append(lValues, lChild)
+                        break
+                    }
+                }
+            }
+            return lValues
+    }
+    return nil
+}
+
+// jsonPathChildren returns array elements or object values in key order
+func jsonPathChildren(value interface{}) []interface{} {
+    switch t := value.(type) {
+        case []interface{}:
+            return t
+        case map[string]interface{}:
+            var lKeys []string
+            for key := range t {
+                lKeys = append(lKeys, key)
+            }
+            sort.Strings(lKeys)
+            var lValues []interface{}
+            for _, key := range lKeys {
+                lValues = append(lValues, t[key])
+            }
+            return lValues
+    }
+    return nil
+}
+
+func jsonPathBound(index, length int) int {
+    if index < 0 {
+        index += length
+    }
+    if index < 0 {
+        return 0
+    }
+    if index > length {
+        return length
+    }
+    return index
+}
+
+func jsonPathCompare(value interface{}, op string, expected interface{}) bool {
+    if lNumber, isNumber := value.(json.Number); isNumber {
+        lValue, err := lNumber.Float64()
+        lExpected, isFloat := expected.(float64)
+        if err != nil || !isFloat {
+            return op == "!="
+        }
+        switch op {
+            case "==":
+                return lValue == lExpected
+            case "!=":
+                return lValue != lExpected
+            case "<":
+                return lValue < lExpected
+            case "<=":
+                return lValue <= lExpected
+            case ">":
+                return lValue > lExpected
+            case ">=":
+                return lValue >= lExpected
+        }
+        return false
+    }
+    if lValue, isString := value.(string); isString {
+        lExpected, isString := expected.(string)
+        if !isString {
+            return op == "!="
+        }
+        switch op {
+            case "==":
+                return lValue == lExpected
+            case "!=":
+                return lValue != lExpected
+            case "<":
+                return lValue < lExpected
+            case "<=":
+                return lValue <= lExpected
+            case ">":
+                return lValue > lExpected
+            case ">=":
+                return lValue >= lExpected
+        }
+        return false
+    }
+    switch op {
+        case "==":
+            return value == expected
+        case "!=":
+            return value != expected
+    }
+    return false
+}
+
+func jsonPathString(value interface{}) string {
+    switch t := value.(type) {
+        case string:
+            return t
+        case json.Number:
+            return t.String()
+        case nil:
+            return ""
+        case map[string]interface{}, []interface{}:
+            lJson, _ := json.Marshal(t)
+            return string(lJson)
+    }
+    return fmt.Sprintf("%v", value)
+}
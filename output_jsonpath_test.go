@@ -0,0 +1,80 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "testing"
+)
+
+const c_JsonPathTestData = `{
+    "domain": "example.com",
+    "records": [
+        { "type": "A", "name": "www", "ttl": 300, "ip": "192.0.2.1" },
+        { "type": "MX", "name": "@", "ttl": 3600, "priority": 10, "mailserver": "mx1.example.com." },
+        { "type": "MX", "name": "@", "ttl": 3600, "priority": 20, "mailserver": "mx2.example.com." },
+        { "type": "TXT", "name": "@", "ttl": 300, "text": "v=spf1 -all", "meta": { "ip": "192.0.2.9" } }
+    ]
+}`
+
+func TestRenderJsonPath(t *testing.T) {
+    var lData interface{}
+    if err := json.Unmarshal([]byte(c_JsonPathTestData), &lData); err != nil {
+        t.Fatal(err)
+    }
+    lTests := []struct {
+        template        string
+        output          string
+        isError         bool
+    }{
+        // paths
+        { `{.domain}`, "example.com", false },
+        { `{$.records[0].ip}`, "192.0.2.1", false },
+        { `{.records[-1].type}`, "TXT", false },
+        { `{.records[0]['name']}`, "www", false },
+        { `{.records[*].type}`, "A MX MX TXT", false },
+        { `{.records[0].*}`, "192.0.2.1 www 300 A", false },
+        // filters
+        { `{.records[?(@.type=="MX")].mailserver}`, "mx1.example.com. mx2.example.com.", false },
+        { `{.records[?(@.priority > 10)].mailserver}`, "mx2.example.com.", false },
+        { `{.records[?(@.ttl <= 300)].type}`, "A TXT", false },
+        { `{.records[?(@.type != 'MX')].name}`, "www @", false },
+        { `{.records[?(@.ip)].name}`, "www", false },
+        { `{.records[?(@.meta.ip)].type}`, "TXT", false },
+        // slices
+        { `{.records[1:3].priority}`, "10 20", false },
+        { `{.records[:2].type}`, "A MX", false },
+        { `{.records[-2:].type}`, "MX TXT", false },
+        { `{.records[::2].type}`, "A MX", false },
+        { `{.records[1::2].type}`, "MX TXT", false },
+        { `{.records[0:10:3].type}`, "A TXT", false },
+        { `{.records[3:1].type}`, "", false },
+        { `{.records[::0].type}`, "", true },
+        { `{.records[::-1].type}`, "", true },
+        // recursion
+        { `{..ip}`, "192.0.2.1 192.0.2.9", false },
+        { `{.records..mailserver}`, "mx1.example.com. mx2.example.com.", false },
+        // range
+        { `{range .records[?(@.type=="MX")]}{.priority} {.mailserver}{"\n"}{end}`, "10 mx1.example.com.\n20 mx2.example.com.\n", false },
+        { `{range .records[*]}[{.name}]{end}`, "[www][@][@][@]", false },
+        { `{range .records[?(@.meta)]}{range .meta.*}<{@}>{end}{end}`, "<192.0.2.9>", false },
+        { `{range .records[*]}{end`, "", true },
+        { `{end}`, "", true },
+        // missing keys
+        { `{.missing}`, "", true },
+        { `{.records[*].mailserver}`, "mx1.example.com. mx2.example.com.", false },
+        { `{.records[0].mailserver}`, "", true },
+        { `{range .records[*]}{.ip}{end}`, "", true },
+        { `{..missing}`, "", false },
+    }
+    for _, lTest := range lTests {
+        var lOutput bytes.Buffer
+        err := renderJsonPath(&lOutput, lTest.template, lData)
+        if (err != nil) != lTest.isError {
+            t.Errorf("renderJsonPath(%q) error %v", lTest.template, err)
+            continue
+        }
+        if err == nil && lOutput.String() != lTest.output {
+            t.Errorf("renderJsonPath(%q) = %q, want %q", lTest.template, lOutput.String(), lTest.output)
+        }
+    }
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "sort"
+    "strings"
+    "text/tabwriter"
+    "a24api/lib"
+)
+
+// ================================================================================================================================================================
+// SPF
+// ================================================================================================================================================================
+
+// printSpf prints SPF record followed by its terms, one per line with qualifier result and lookup mark
+func printSpf(spf *a24apiclient.T_SpfRecord) {
+    fmt.Printf("%s\t%s\t%s\n", spf.Domain, spf.Name, spf.Text)
+    w := new(tabwriter.Writer)
+    w.Init(os.Stdout, 0, 8, 1, ' ', 0)
+    for _, element := range spf.Terms {
+        lKind := element.Mechanism
+        lResult := a24apiclient.C_Spf_Qualifiers[element.Qualifier]
+        if element.Modifier != "" {
+            lKind = element.Modifier
+            lResult = "modifier"
+        }
+        lLookup := ""
+        if element.Lookup {
+            lLookup = "lookup"
+        }
+        fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s\n", element.Text, lResult, lKind, element.Value, lLookup, element.Error)
+    }
+    w.Flush()
+}
+
+// printSpfLint prints findings of SPF lint and lookup counts
+func printSpfLint(lint *a24apiclient.T_SpfLint) {
+    w := new(tabwriter.Writer)
+    w.Init(os.Stdout, 0, 8, 1, ' ', 0)
+    for _, element := range lint.Findings {
+        fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", element.Level, element.Domain, element.Term, element.Message)
+    }
+    w.Flush()
+    fmt.Printf("lookups %d/%d, void lookups %d/%d, %d errors, %d warnings\n", lint.Lookups, a24apiclient.C_Spf_MaxLookups, lint.VoidLookups, a24apiclient.C_Spf_MaxVoidLookups, lint.Errors(), len(lint.Findings) - lint.Errors())
+}
+
+// serviceRows returns table rows of SPF terms, lint findings, DKIM selectors, mail authentication findings, presets,
+// TLSA checks, CAA policy, SRV services or SRV records
+func serviceRows(data interface{}) ([]t_outputColumn, [][]interface{}, bool) {
+    var lRows [][]interface{}
+    switch t := data.(type) {
+        case *a24apiclient.T_SpfRecord:
+            for _, element := range t.Terms {
+                lRows = append(lRows, []interface{}{ t.Domain, t.Name, element.Text, element.Qualifier, element.Mechanism, element.Modifier, element.Value, element.Lookup, element.Error })
+            }
+            return []t_outputColumn{ { Header: "domain" }, { Header: "name" }, { Header: "term" }, { Header: "qualifier" }, { Header: "mechanism" }, { Header: "modifier" }, { Header: "value" }, { Header: "lookup" }, { Header: "error" } }, lRows, true
+        case *a24apiclient.T_SpfLint:
+            for _, element := range t.Findings {
+                lRows = append(lRows, []interface{}{ element.Level, element.Domain, element.Term, element.Message })
+            }
+            return []t_outputColumn{ { Header: "level" }, { Header: "domain" }, { Header: "term" }, { Header: "message" } }, lRows, true
+        case []a24apiclient.T_DkimSelector:
+            for _, element := range t {
+                lRows = append(lRows, []interface{}{ element.Domain, element.Selector, element.Algorithm, element.Bits, element.Revoked, element.RetireAfter })
+            }
+            return []t_outputColumn{ { Header: "domain" }, { Header: "selector" }, { Header: "algorithm" }, { Header: "bits" }, { Header: "revoked" }, { Header: "retireAfter" } }, lRows, true
+        case *a24apiclient.T_MailAuth:
+            for _, lRecord := range t.Records {
+                if len(lRecord.Findings) == 0 {
+                    lRows = append(lRows, []interface{}{ t.Domain, lRecord.Kind, lRecord.Name, lRecord.Published, lRecord.Text, "", "", "" })
+                }
+                for _, element := range lRecord.Findings {
+                    lRows = append(lRows, []interface{}{ t.Domain, lRecord.Kind, lRecord.Name, lRecord.Published, lRecord.Text, element.Level, element.Term, element.Message })
+                }
+            }
+            return []t_outputColumn{ { Header: "domain" }, { Header: "kind" }, { Header: "name" }, { Header: "published" }, { Header: "text" }, { Header: "level" }, { Header: "tag" }, { Header: "message" } }, lRows, true
+        case []a24apiclient.T_DnsPreset:
+            for _, element := range t {
+                lRows = append(lRows, []interface{}{ element.Name, element.Description, presetVariables(element), len(element.Records) })
+            }
+            return []t_outputColumn{ { Header: "name" }, { Header: "description" }, { Header: "variables" }, { Header: "records" } }, lRows, true
+        case []a24apiclient.T_TlsaCheck:
+            for _, element := range t {
+                lRows = append(lRows, []interface{}{ element.Name, element.Usage, element.Selector, element.MatchingType, element.Hash, element.Expected, element.Match, element.Error })
+            }
+            return []t_outputColumn{ { Header: "name" }, { Header: "usage" }, { Header: "selector" }, { Header: "matchingType" }, { Header: "hash" }, { Header: "expected" }, { Header: "match" }, { Header: "error" } }, lRows, true
+        case *a24apiclient.T_CaaPolicy:
+            for _, element := range t.Records {
+                lRows = append(lRows, []interface{}{ t.Domain, t.Name, t.Source, "record", element["Flags"] + " " + element["Tag"] + " " + element["CaaValue"] })
+            }
+            for _, element := range t.Findings {
+                lRows = append(lRows, []interface{}{ t.Domain, t.Name, t.Source, element.Level, strings.TrimSpace(element.Term + " " + element.Message) })
+            }
+            return []t_outputColumn{ { Header: "domain" }, { Header: "name" }, { Header: "source" }, { Header: "kind" }, { Header: "value" } }, lRows, true
+        case []a24apiclient.T_SrvService:
+            for _, element := range t {
+                lRows = append(lRows, []interface{}{ element.Service, element.Proto, element.Port, element.Description })
+            }
+            return []t_outputColumn{ { Header: "service" }, { Header: "proto" }, { Header: "port" }, { Header: "description" } }, lRows, true
+        case []a24apiclient.T_SrvEntry:
+            for _, element := range t {
+                lRows = append(lRows, []interface{}{ element.Domain, element.Name, element.Service, element.Proto, element.Priority, element.Weight, element.Share, element.Port, element.Target, element.Ttl })
+            }
+            return []t_outputColumn{ { Header: "domain" }, { Header: "name" }, { Header: "service" }, { Header: "proto" }, { Header: "priority" }, { Header: "weight" }, { Header: "share" }, { Header: "port" }, { Header: "target" }, { Header: "ttl" } }, lRows, true
+    }
+    return nil, nil, false
+}
+
+// ================================================================================================================================================================
+// DKIM
+// ================================================================================================================================================================
+
+// printDkimSelectors prints published DKIM keys, one selector per line
+func printDkimSelectors(selectors []a24apiclient.T_DkimSelector) {
+    w := new(tabwriter.Writer)
+    w.Init(os.Stdout, 0, 8, 1, ' ', 0)
+    for _, element := range selectors {
+        lKey := element.Algorithm
+        if element.Bits > 0 {
+            lKey = fmt.Sprintf("%s %d", element.Algorithm, element.Bits)
+        }
+        lState := "live"
+        if element.Revoked {
+            lState = "revoked"
+        } else if element.RetireAfter != "" {
+            lState = "retire after " + element.RetireAfter
+        }
+        fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", element.Domain, element.Selector, lKey, lState)
+    }
+    w.Flush()
+}
+
+// writeKeyFile writes private key readable by owner only, existing file is never overwritten
+func writeKeyFile(path string, data []byte) error {
+    lFile, err := os.OpenFile(path, os.O_WRONLY | os.O_CREATE | os.O_EXCL, 0600)
+    if err != nil {
+        return err
+    }
+    if _, err := lFile.Write(data); err != nil {
+        lFile.Close()
+        return err
+    }
+    return lFile.Close()
+}
+
+// ================================================================================================================================================================
+// MAILAUTH
+// ================================================================================================================================================================
+
+// printMailAuth prints published DMARC, MTA-STS and TLS-RPT records followed by their findings
+func printMailAuth(mailauth *a24apiclient.T_MailAuth) {
+    w := new(tabwriter.Writer)
+    w.Init(os.Stdout, 0, 8, 1, ' ', 0)
+    for _, lRecord := range mailauth.Records {
+        lText := lRecord.Text
+        if !lRecord.Published {
+            lText = "-"
+        }
+        fmt.Fprintf(w, "%s\t%s\t%s\n", lRecord.Kind, lRecord.Name, lText)
+        for _, element := range lRecord.Findings {
+            fmt.Fprintf(w, "  %s\t%s\t%s\n", element.Level, element.Term, element.Message)
+        }
+    }
+    w.Flush()
+}
+
+// ================================================================================================================================================================
+// PRESET
+// ================================================================================================================================================================
+
+// printDnsPresets prints presets with variables and their defaults
+func printDnsPresets(w *tabwriter.Writer, presets []a24apiclient.T_DnsPreset) {
+    for _, element := range presets {
+        fmt.Fprintf(w, "%s\t%s\t%s\n", element.Name, element.Description, presetVariables(element))
+    }
+}
+
+// presetVariables returns variables of preset as name=default list ordered by name
+func presetVariables(preset a24apiclient.T_DnsPreset) string {
+    var lVariables []string
+    for key, value := range preset.Variables {
+        lVariables = append(lVariables, key + "=" + value)
+    }
+    sort.Strings(lVariables)
+    return strings.Join(lVariables, " ")
+}
+
+// ================================================================================================================================================================
+// TLSA
+// ================================================================================================================================================================
+
+// printTlsaChecks prints published TLSA records with match result and summary line
+func printTlsaChecks(checks []a24apiclient.T_TlsaCheck) {
+    w := new(tabwriter.Writer)
+    w.Init(os.Stdout, 0, 8, 1, ' ', 0)
+    for _, element := range checks {
+        lResult := "mismatch"
+        if element.Match {
+            lResult = "match"
+        } else if element.Error != "" {
+            lResult = element.Error
+        }
+        fmt.Fprintf(w, "%s\t%s %s %s\t%s\t%s\n", element.Name, element.Usage, element.Selector, element.MatchingType, element.Hash, lResult)
+    }
+    w.Flush()
+    fmt.Printf("%d of %d published records match\n", a24apiclient.TlsaMatches(checks), len(checks))
+}
+
+// ================================================================================================================================================================
+// CAA
+// ================================================================================================================================================================
+
+// printCaaPolicy prints CAA records relevant for name, names using and overriding them and findings
+func printCaaPolicy(policy *a24apiclient.T_CaaPolicy) {
+    w := new(tabwriter.Writer)
+    w.Init(os.Stdout, 0, 8, 1, ' ', 0)
+    lSource := policy.Source
+    if lSource == "" {
+        lSource = "-"
+    } else if lSource != policy.Name {
+        lSource = "inherited from " + lSource
+    }
+    fmt.Fprintf(w, "%s\t%s\t%s\n", policy.Domain, policy.Name, lSource)
+    for _, element := range policy.Records {
+        fmt.Fprintf(w, "  %s\t%s\t%s\n", element["Flags"], element["Tag"], element["CaaValue"])
+    }
+    if len(policy.Inheriting) > 0 {
+        fmt.Fprintf(w, "  applies to\t%s\n", strings.Join(policy.Inheriting, " "))
+    }
+    if len(policy.Overriding) > 0 {
+        fmt.Fprintf(w, "  overridden by\t%s\n", strings.Join(policy.Overriding, " "))
+    }
+    for _, element := range policy.Findings {
+        fmt.Fprintf(w, "  %s\t%s\t%s\n", element.Level, element.Term, element.Message)
+    }
+    w.Flush()
+}
+
+// ================================================================================================================================================================
+// SRV
+// ================================================================================================================================================================
+
+// printSrvServices prints known services with their default ports
+func printSrvServices(services []a24apiclient.T_SrvService) {
+    w := new(tabwriter.Writer)
+    w.Init(os.Stdout, 0, 8, 1, ' ', 0)
+    for _, element := range services {
+        fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", element.Service, element.Proto, element.Port, element.Description)
+    }
+    w.Flush()
+}
+
+// printSrvEntries prints SRV records with share of each target within its priority
+func printSrvEntries(entries []a24apiclient.T_SrvEntry) {
+    w := new(tabwriter.Writer)
+    w.Init(os.Stdout, 0, 8, 1, ' ', 0)
+    for _, element := range entries {
+        fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%.0f%%\t%d\t%s\n", element.Domain, element.Name, element.Ttl, element.Priority, element.Weight, element.Share * 100, element.Port, element.Target)
+    }
+    w.Flush()
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "sort"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+)
+
+// ================================================================================================================================================================
+// TABLE
+// ================================================================================================================================================================
+
+// minimal width of truncated column
+const C_Output_TableMinWidth = 10
+
+// ansi colours of status and action values
+var C_Output_TableColours = map[string]string {
+    "ok": "32",
+    "create": "32",
+    "added": "32",
+    "error": "31",
+    "delete": "31",
+    "removed": "31",
+    "skipped": "33",
+    "rolled_back": "33",
+    "update": "33",
+    "changed": "33",
+}
+
+// renderTable prints rows aligned with upper-case headers. Without selected columns record value fields are
+// replaced by joined value column. Columns wider than width limit are truncated, widest first.
+func renderTable(w io.Writer, columns []t_outputColumn, rows [][]interface{}, options t_outputOptions) error {
+    var lHeaders []string
+    for _, lColumn := range columns {
+        lHeaders = append(lHeaders, lColumn.Header)
+    }
+
+    var lSelected []int
+    if len(options.Columns) > 0 {
+        for _, lName := range options.Columns {
+            lIndex := tableColumnIndex(columns, lName)
+            if lIndex < 0 {
+                return fmt.Errorf("Unknown column %s, available columns: %s.", lName, strings.Join(lHeaders, ", "))
+            }
+            lSelected = append(lSelected, lIndex)
+        }
+    } else {
+        for index, lColumn := range columns {
+            if !lColumn.Detail {
+                lSelected = append(lSelected, index)
+            }
+        }
+    }
+
+    if options.SortBy != "" {
+        lIndex := tableColumnIndex(columns, options.SortBy)
+        if lIndex < 0 {
+            return fmt.Errorf("Unknown sort column %s, available columns: %s.", options.SortBy, strings.Join(lHeaders, ", "))
+        }
+        lRows := append([][]interface{}{}, rows...)
+        sort.SliceStable(lRows, func(i, j int) bool {
+            return tableLess(lRows[i][lIndex], lRows[j][lIndex])
+        })
+        rows = lRows
+    }
+
+    lCells := make([][]string, len(rows))
+    lWidths := make([]int, len(lSelected))
+    for index, lColumn := range lSelected {
+        if options.Header {
+            lWidths[index] = utf8.RuneCountInString(columns[lColumn].Header)
+        }
+    }
+    for indexRow, lRow := range rows {
+        for index, lColumn := range lSelected {
+            lCell := tableCell(lRow[lColumn])
+            lCells[indexRow] = append(lCells[indexRow], lCell)
+            if lWidth := utf8.RuneCountInString(lCell); lWidth > lWidths[index] {
+                lWidths[index] = lWidth
+            }
+        }
+    }
+
+    if options.Width > 0 {
+        for {
+            lTotal := 2 * (len(lWidths) - 1)
+            lWidest := 0
+            for index, lWidth := range lWidths {
+                lTotal += lWidth
+                if lWidth > lWidths[lWidest] {
+                    lWidest = index
+                }
+            }
+            if lTotal <= options.Width || len(lWidths) == 0 || lWidths[lWidest] <= C_Output_TableMinWidth {
+                break
+            }
+            lWidths[lWidest]--
+        }
+    }
+
+    if options.Header {
+        var lLine []string
+        for _, lColumn := range lSelected {
+            lLine = append(lLine, strings.ToUpper(columns[lColumn].Header))
+        }
+        writeTableLine(w, lLine, lWidths, options.Colour, true)
+    }
+    for _, lLine := range lCells {
+        writeTableLine(w, lLine, lWidths, options.Colour, false)
+    }
+    return nil
+}
+
+func tableColumnIndex(columns []t_outputColumn, name string) int {
+    for index, lColumn := range columns {
+        if strings.EqualFold(lColumn.Header, strings.TrimSpace(name)) {
+            return index
+        }
+    }
+    return -1
+}
+
+// tableLess compares numbers numerically, anything else as strings, empty values last
+func tableLess(a, b interface{}) bool {
+    lA, lB := tableCell(a), tableCell(b)
+    if lA == "" || lB == "" {
+        return lA != "" && lB == ""
+    }
+    lNumberA, errA := strconv.ParseFloat(lA, 64)
+    lNumberB, errB := strconv.ParseFloat(lB, 64)
+    if errA == nil && errB == nil {
+        return lNumberA < lNumberB
+    }
+    return lA < lB
+}
+
+func tableCell(value interface{}) string {
+    switch t := value.(type) {
+        case nil:
+            return ""
+        case float64:
+            return strconv.FormatFloat(t, 'f', -1, 64)
+        case string:
+            // keep one line per row
+            return strings.NewReplacer("\n", "\\n", "\r", "\\r", "\t", " ").Replace(t)
+    }
+    return fmt.Sprintf("%v", value)
+}
+
+func writeTableLine(w io.Writer, cells []string, widths []int, colour, header bool) {
+    var lLine strings.Builder
+    for index, lCell := range cells {
+        if index > 0 {
+            lLine.WriteString("  ")
+        }
+        lCell = tableTruncate(lCell, widths[index])
+        lPadding := ""
+        if index < len(cells) - 1 {
+            lPadding = strings.Repeat(" ", widths[index] - utf8.RuneCountInString(lCell))
+        }
+        lCode := ""
+        if colour && header {
+            lCode = "1"
+        } else if colour {
+            lCode = C_Output_TableColours[lCell]
+        }
+        if lCode != "" {
+            lCell = "\x1b[" + lCode + "m" + lCell + "\x1b[0m"
+        }
+        lLine.WriteString(lCell + lPadding)
+    }
+    fmt.Fprintln(w, lLine.String())
+}
+
+func tableTruncate(cell string, width int) string {
+    if utf8.RuneCountInString(cell) <= width {
+        return cell
+    }
+    lRunes := []rune(cell)
+    return string(lRunes[:width - 1]) + "…"
+}
+
+// isTerminal reports whether file is a character device, e.g. interactive stdout
+func isTerminal(f *os.File) bool {
+    lInfo, err := f.Stat()
+    if err != nil {
+        return false
+    }
+    return lInfo.Mode() & os.ModeCharDevice != 0
+}
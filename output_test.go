@@ -0,0 +1,125 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync"
+    "testing"
+
+    a24apiclient "a24api/lib"
+)
+
+// newChangesTestServer returns fake dns api of example.com, record with ip 192.0.2.99 is refused with 400
+func newChangesTestServer() *httptest.Server {
+    var lMutex sync.Mutex
+    var lRecords []map[string]interface{}
+    return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        lMutex.Lock()
+        defer lMutex.Unlock()
+        var lBody map[string]interface{}
+        json.NewDecoder(r.Body).Decode(&lBody)
+        switch {
+            case r.Method == "GET" && r.URL.Path == "/dns/example.com/records/v1":
+                json.NewEncoder(w).Encode(lRecords)
+                return
+            case r.Method == "POST" && r.URL.Path == "/dns/example.com/a/v1" && lBody["ip"] != "192.0.2.99":
+                lBody["hashId"] = "h" + lBody["ip"].(string)
+                lBody["type"] = "A"
+                lRecords = append(lRecords, lBody)
+                w.WriteHeader(204)
+                return
+            case r.Method == "DELETE":
+                for index, lRecord := range lRecords {
+                    if r.URL.Path == "/dns/example.com/" + lRecord["hashId"].(string) + "/v1" {
+                        lRecords = append(lRecords[:index:index], lRecords[index + 1:]...)
+                        w.WriteHeader(204)
+                        return
+                    }
+                }
+        }
+        w.WriteHeader(400)
+    }))
+}
+
+func TestRenderChangesFailedApply(t *testing.T) {
+    s := newChangesTestServer()
+    defer s.Close()
+    lClient := a24apiclient.NewA24ApiClient(map[string]string{ "endpoint": s.URL })
+    var lChanges []a24apiclient.T_DnsChange
+    for _, lIp := range []string{ "192.0.2.1", "192.0.2.99", "192.0.2.2" } {
+        lChanges = append(lChanges, a24apiclient.T_DnsChange{ Action: "create", Record: map[string]string{ "Domain": "example.com", "Type": "A", "Name": "www", "Ttl": "300", "Ip": lIp } })
+    }
+    lChanges, err := lClient.DnsApplyChanges(lChanges)
+    if err == nil {
+        t.Fatalf("DnsApplyChanges did not fail")
+    }
+
+    lTests := []struct {
+        format          string
+        want            []string
+    }{
+        { "inline", []string{ "192.0.2.1  204 OK (rolled back)", "192.0.2.99 400 ", "192.0.2.2  0 (skipped)" } },
+        { "table", []string{ "\x1b[33mrolled_back\x1b[0m", "\x1b[31merror\x1b[0m", "\x1b[33mskipped\x1b[0m" } },
+        { "csv", []string{ "create,rolled_back,204,", "create,error,400,", "create,skipped,0," } },
+    }
+    for _, lTest := range lTests {
+        var lOutput bytes.Buffer
+        lArgs := map[string]string{ "format": lTest.format, "service": "dns", "color": "always", "width": "0" }
+        if err := renderChanges(&lOutput, lClient, lArgs, lChanges); err != nil {
+            t.Errorf("%s: %v", lTest.format, err)
+            continue
+        }
+        for _, lWant := range lTest.want {
+            if !strings.Contains(lOutput.String(), lWant) {
+                t.Errorf("%s: output %q does not contain %q", lTest.format, lOutput.String(), lWant)
+            }
+        }
+    }
+}
+
+func TestRenderChangesFailedRRset(t *testing.T) {
+    s := newChangesTestServer()
+    defer s.Close()
+    lClient := a24apiclient.NewA24ApiClient(map[string]string{ "endpoint": s.URL })
+    lRRset, err := a24apiclient.NewDnsRRset("example.com", []string{ "A", "www", "300", "192.0.2.1", "192.0.2.99" })
+    if err != nil {
+        t.Fatalf("NewDnsRRset failed: %v", err)
+    }
+    lChanges, err := lClient.DnsReplaceRRset("example.com", "A", "www", lRRset)
+    if err == nil {
+        t.Fatalf("DnsReplaceRRset did not fail")
+    }
+    var lOutput bytes.Buffer
+    if err := renderChanges(&lOutput, lClient, map[string]string{ "format": "inline", "service": "dns" }, lChanges); err != nil {
+        t.Fatalf("renderChanges failed: %v", err)
+    }
+    lLines := strings.Split(strings.TrimSpace(lOutput.String()), "\n")
+    if len(lLines) != 2 || !strings.HasSuffix(lLines[0], "192.0.2.1  204 OK (rolled back)") || !strings.Contains(lLines[1], "192.0.2.99 400 ") {
+        t.Errorf("output %q", lOutput.String())
+    }
+}
+
+func TestRenderRowsNumbers(t *testing.T) {
+    lColumns := []t_outputColumn{ { Header: "name" }, { Header: "ttl" }, { Header: "weight" } }
+    lRows := [][]interface{}{ { "www", outputValue("1000000", true), 0.5 }, { "@", outputValue("2147483647", true), nil } }
+    lTests := []struct {
+        format          string
+        output          string
+    }{
+        { "csv", "name,ttl,weight\nwww,1000000,0.5\n@,2147483647,\n" },
+        { "tsv", "name\tttl\tweight\nwww\t1000000\t0.5\n@\t2147483647\t\n" },
+    }
+    for _, lTest := range lTests {
+        var lOutput bytes.Buffer
+        if err := renderRows(&lOutput, lTest.format, lColumns, lRows, t_outputOptions{ Header: true }); err != nil {
+            t.Errorf("%s: %v", lTest.format, err)
+            continue
+        }
+        if lOutput.String() != lTest.output {
+            t.Errorf("%s: output %q, want %q", lTest.format, lOutput.String(), lTest.output)
+        }
+    }
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package main
+
+import (
+    "os"
+)
+
+// terminalWidth returns number of columns of terminal attached to file, 0 when unknown
+func terminalWidth(f *os.File) int {
+    return 0
+}
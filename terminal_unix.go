@@ -0,0 +1,25 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package main
+
+import (
+    "os"
+    "syscall"
+    "unsafe"
+)
+
+// terminalWidth returns number of columns of terminal attached to file, 0 when unknown
+func terminalWidth(f *os.File) int {
+    var lSize struct {
+        Rows        uint16
+        Cols        uint16
+        X           uint16
+        Y           uint16
+    }
+    _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&lSize)))
+    if errno != 0 {
+        return 0
+    }
+    return int(lSize.Cols)
+}